@@ -75,6 +75,162 @@ func (TodoStatus) EnumDescriptor() ([]byte, []int) {
 	return file_todo_proto_rawDescGZIP(), []int{0}
 }
 
+// ExportFormat is the encoding of a user data export bundle
+type ExportFormat int32
+
+const (
+	ExportFormat_EXPORT_FORMAT_UNSPECIFIED ExportFormat = 0 // Defaults to JSON
+	ExportFormat_EXPORT_FORMAT_JSON        ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_ZIP         ExportFormat = 2
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_UNSPECIFIED",
+		1: "EXPORT_FORMAT_JSON",
+		2: "EXPORT_FORMAT_ZIP",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_UNSPECIFIED": 0,
+		"EXPORT_FORMAT_JSON":        1,
+		"EXPORT_FORMAT_ZIP":         2,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_proto_enumTypes[1].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_todo_proto_enumTypes[1]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{1}
+}
+
+// EraseMode controls how a user's data is erased
+type EraseMode int32
+
+const (
+	EraseMode_ERASE_MODE_UNSPECIFIED EraseMode = 0 // Defaults to hard delete
+	EraseMode_ERASE_MODE_HARD_DELETE EraseMode = 1
+	EraseMode_ERASE_MODE_ANONYMIZE   EraseMode = 2
+)
+
+// Enum value maps for EraseMode.
+var (
+	EraseMode_name = map[int32]string{
+		0: "ERASE_MODE_UNSPECIFIED",
+		1: "ERASE_MODE_HARD_DELETE",
+		2: "ERASE_MODE_ANONYMIZE",
+	}
+	EraseMode_value = map[string]int32{
+		"ERASE_MODE_UNSPECIFIED": 0,
+		"ERASE_MODE_HARD_DELETE": 1,
+		"ERASE_MODE_ANONYMIZE":   2,
+	}
+)
+
+func (x EraseMode) Enum() *EraseMode {
+	p := new(EraseMode)
+	*p = x
+	return p
+}
+
+func (x EraseMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EraseMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_proto_enumTypes[2].Descriptor()
+}
+
+func (EraseMode) Type() protoreflect.EnumType {
+	return &file_todo_proto_enumTypes[2]
+}
+
+func (x EraseMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EraseMode.Descriptor instead.
+func (EraseMode) EnumDescriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{2}
+}
+
+// TodoFormat is a file format shared with other todo apps
+type TodoFormat int32
+
+const (
+	TodoFormat_TODO_FORMAT_UNSPECIFIED TodoFormat = 0
+	TodoFormat_TODO_FORMAT_CSV         TodoFormat = 1
+	TodoFormat_TODO_FORMAT_JSON        TodoFormat = 2
+	TodoFormat_TODO_FORMAT_TODO_TXT    TodoFormat = 3
+	TodoFormat_TODO_FORMAT_ICALENDAR   TodoFormat = 4 // VTODO components
+)
+
+// Enum value maps for TodoFormat.
+var (
+	TodoFormat_name = map[int32]string{
+		0: "TODO_FORMAT_UNSPECIFIED",
+		1: "TODO_FORMAT_CSV",
+		2: "TODO_FORMAT_JSON",
+		3: "TODO_FORMAT_TODO_TXT",
+		4: "TODO_FORMAT_ICALENDAR",
+	}
+	TodoFormat_value = map[string]int32{
+		"TODO_FORMAT_UNSPECIFIED": 0,
+		"TODO_FORMAT_CSV":         1,
+		"TODO_FORMAT_JSON":        2,
+		"TODO_FORMAT_TODO_TXT":    3,
+		"TODO_FORMAT_ICALENDAR":   4,
+	}
+)
+
+func (x TodoFormat) Enum() *TodoFormat {
+	p := new(TodoFormat)
+	*p = x
+	return p
+}
+
+func (x TodoFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TodoFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_proto_enumTypes[3].Descriptor()
+}
+
+func (TodoFormat) Type() protoreflect.EnumType {
+	return &file_todo_proto_enumTypes[3]
+}
+
+func (x TodoFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TodoFormat.Descriptor instead.
+func (TodoFormat) EnumDescriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{3}
+}
+
 // Todo represents a todo item
 type Todo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -85,6 +241,11 @@ type Todo struct {
 	Status        TodoStatus             `protobuf:"varint,5,opt,name=status,proto3,enum=todo.v1.TodoStatus" json:"status,omitempty"`
 	CreatedAt     *timestamp.Timestamp   `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	CompletedAt   *timestamp.Timestamp   `protobuf:"bytes,7,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	DeletedAt     *timestamp.Timestamp   `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	DueAt         *timestamp.Timestamp   `protobuf:"bytes,9,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	Priority      int32                  `protobuf:"varint,10,opt,name=priority,proto3" json:"priority,omitempty"`                 // 1 (highest) to 9 (lowest), 0 means none - as in iCalendar
+	ParentId      int64                  `protobuf:"varint,11,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // The todo this is a step of, 0 if none
+	Category      string                 `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`                  // What kind of todo it is, like "errands" - empty if unknown
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -168,6 +329,41 @@ func (x *Todo) GetCompletedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (x *Todo) GetDeletedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetDueAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Todo) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Todo) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Todo) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
 // CreateTodoRequest creates a new todo
 type CreateTodoRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -175,6 +371,8 @@ type CreateTodoRequest struct {
 	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
 	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
 	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	ParentId       int64                  `protobuf:"varint,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Makes the new todo a step of this one, 0 for none
+	Category       string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`                  // Optional, like "errands"
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -237,6 +435,20 @@ func (x *CreateTodoRequest) GetIdempotencyKey() string {
 	return ""
 }
 
+func (x *CreateTodoRequest) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateTodoRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
 type CreateTodoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Todo          *Todo                  `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
@@ -401,6 +613,7 @@ type ListTodosRequest struct {
 	Status          TodoStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=todo.v1.TodoStatus" json:"status,omitempty"`                 // Optional filter by status
 	CompletedAfter  *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=completed_after,json=completedAfter,proto3" json:"completed_after,omitempty"`    // Optional: filter completed todos after this time
 	CompletedBefore *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=completed_before,json=completedBefore,proto3" json:"completed_before,omitempty"` // Optional: filter completed todos before this time
+	Category        string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`                                      // Optional filter by category
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -463,6 +676,13 @@ func (x *ListTodosRequest) GetCompletedBefore() *timestamp.Timestamp {
 	return nil
 }
 
+func (x *ListTodosRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
 type ListTodosResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Todos         []*Todo                `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
@@ -733,71 +953,1623 @@ func (x *EditTodoResponse) GetTodo() *Todo {
 	return nil
 }
 
-var File_todo_proto protoreflect.FileDescriptor
+// GetStatsRequest retrieves completion statistics for a user
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Timezone      string                 `protobuf:"bytes,2,opt,name=timezone,proto3" json:"timezone,omitempty"`                           // Optional: IANA timezone used for day boundaries (default UTC)
+	Days          int32                  `protobuf:"varint,3,opt,name=days,proto3" json:"days,omitempty"`                                  // Optional: number of daily buckets to return (default 7, max 366)
+	Weeks         int32                  `protobuf:"varint,4,opt,name=weeks,proto3" json:"weeks,omitempty"`                                // Optional: number of weekly buckets to return (default 4, max 104)
+	OldestLimit   int32                  `protobuf:"varint,5,opt,name=oldest_limit,json=oldestLimit,proto3" json:"oldest_limit,omitempty"` // Optional: number of oldest open todos to return (default 3, max 50)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_todo_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"todo.proto\x12\atodo.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x02\n" +
-	"\x04Todo\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
-	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12+\n" +
-	"\x06status\x18\x05 \x01(\x0e2\x13.todo.v1.TodoStatusR\x06status\x129\n" +
-	"\n" +
-	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
-	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"\x8d\x01\n" +
-	"\x11CreateTodoRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12'\n" +
-	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"7\n" +
-	"\x12CreateTodoResponse\x12!\n" +
-	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\xaf\x01\n" +
-	"\x13CompleteTodoRequest\x12\x17\n" +
-	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12'\n" +
-	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12=\n" +
-	"\fcompleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"9\n" +
-	"\x14CompleteTodoResponse\x12!\n" +
-	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\xe4\x01\n" +
-	"\x10ListTodosRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
-	"\x06status\x18\x02 \x01(\x0e2\x13.todo.v1.TodoStatusR\x06status\x12C\n" +
-	"\x0fcompleted_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0ecompletedAfter\x12E\n" +
-	"\x10completed_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0fcompletedBefore\"8\n" +
-	"\x11ListTodosResponse\x12#\n" +
-	"\x05todos\x18\x01 \x03(\v2\r.todo.v1.TodoR\x05todos\"n\n" +
-	"\x11DeleteTodoRequest\x12\x17\n" +
-	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12'\n" +
-	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"7\n" +
-	"\x12DeleteTodoResponse\x12!\n" +
-	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\xa4\x01\n" +
-	"\x0fEditTodoRequest\x12\x17\n" +
-	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
-	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
-	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\x12'\n" +
-	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"5\n" +
-	"\x10EditTodoResponse\x12!\n" +
-	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo*u\n" +
-	"\n" +
-	"TodoStatus\x12\x1b\n" +
-	"\x17TODO_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
-	"\x12TODO_STATUS_ACTIVE\x10\x01\x12\x19\n" +
-	"\x15TODO_STATUS_COMPLETED\x10\x02\x12\x17\n" +
-	"\x13TODO_STATUS_DELETED\x10\x032\xec\x02\n" +
-	"\n" +
-	"TodoDomain\x12E\n" +
-	"\n" +
-	"CreateTodo\x12\x1a.todo.v1.CreateTodoRequest\x1a\x1b.todo.v1.CreateTodoResponse\x12K\n" +
-	"\fCompleteTodo\x12\x1c.todo.v1.CompleteTodoRequest\x1a\x1d.todo.v1.CompleteTodoResponse\x12B\n" +
-	"\tListTodos\x12\x19.todo.v1.ListTodosRequest\x1a\x1a.todo.v1.ListTodosResponse\x12E\n" +
-	"\n" +
-	"DeleteTodo\x12\x1a.todo.v1.DeleteTodoRequest\x1a\x1b.todo.v1.DeleteTodoResponse\x12?\n" +
-	"\bEditTodo\x12\x18.todo.v1.EditTodoRequest\x1a\x19.todo.v1.EditTodoResponseB\x1fZ\x1dhound-todo/api/todo/v1;todov1b\x06proto3"
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_todo_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetStatsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *GetStatsRequest) GetWeeks() int32 {
+	if x != nil {
+		return x.Weeks
+	}
+	return 0
+}
+
+func (x *GetStatsRequest) GetOldestLimit() int32 {
+	if x != nil {
+		return x.OldestLimit
+	}
+	return 0
+}
+
+// DayCount is the number of todos completed on a calendar day
+type DayCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"` // YYYY-MM-DD in the requested timezone
+	Completed     int32                  `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DayCount) Reset() {
+	*x = DayCount{}
+	mi := &file_todo_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DayCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DayCount) ProtoMessage() {}
+
+func (x *DayCount) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DayCount.ProtoReflect.Descriptor instead.
+func (*DayCount) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DayCount) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DayCount) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+// WeekCount is the number of todos completed in a week starting Monday
+type WeekCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WeekStart     string                 `protobuf:"bytes,1,opt,name=week_start,json=weekStart,proto3" json:"week_start,omitempty"` // YYYY-MM-DD of the Monday starting the week
+	Completed     int32                  `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeekCount) Reset() {
+	*x = WeekCount{}
+	mi := &file_todo_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeekCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeekCount) ProtoMessage() {}
+
+func (x *WeekCount) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeekCount.ProtoReflect.Descriptor instead.
+func (*WeekCount) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WeekCount) GetWeekStart() string {
+	if x != nil {
+		return x.WeekStart
+	}
+	return ""
+}
+
+func (x *WeekCount) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+type GetStatsResponse struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Daily                    []*DayCount            `protobuf:"bytes,1,rep,name=daily,proto3" json:"daily,omitempty"`                                       // Oldest first, ending today
+	Weekly                   []*WeekCount           `protobuf:"bytes,2,rep,name=weekly,proto3" json:"weekly,omitempty"`                                     // Oldest first, ending this week
+	CurrentStreak            int32                  `protobuf:"varint,3,opt,name=current_streak,json=currentStreak,proto3" json:"current_streak,omitempty"` // Consecutive days with a completion, ending today or yesterday
+	LongestStreak            int32                  `protobuf:"varint,4,opt,name=longest_streak,json=longestStreak,proto3" json:"longest_streak,omitempty"`
+	AverageCompletionSeconds int64                  `protobuf:"varint,5,opt,name=average_completion_seconds,json=averageCompletionSeconds,proto3" json:"average_completion_seconds,omitempty"` // Mean time from creation to completion
+	OldestOpen               []*Todo                `protobuf:"bytes,6,rep,name=oldest_open,json=oldestOpen,proto3" json:"oldest_open,omitempty"`                                              // Oldest active todos first
+	TotalCompleted           int32                  `protobuf:"varint,7,opt,name=total_completed,json=totalCompleted,proto3" json:"total_completed,omitempty"`
+	TotalOpen                int32                  `protobuf:"varint,8,opt,name=total_open,json=totalOpen,proto3" json:"total_open,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_todo_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetStatsResponse) GetDaily() []*DayCount {
+	if x != nil {
+		return x.Daily
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetWeekly() []*WeekCount {
+	if x != nil {
+		return x.Weekly
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetCurrentStreak() int32 {
+	if x != nil {
+		return x.CurrentStreak
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetLongestStreak() int32 {
+	if x != nil {
+		return x.LongestStreak
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetAverageCompletionSeconds() int64 {
+	if x != nil {
+		return x.AverageCompletionSeconds
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetOldestOpen() []*Todo {
+	if x != nil {
+		return x.OldestOpen
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetTotalCompleted() int32 {
+	if x != nil {
+		return x.TotalCompleted
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetTotalOpen() int32 {
+	if x != nil {
+		return x.TotalOpen
+	}
+	return 0
+}
+
+// ExportUserDataRequest exports todos, history and transcripts for a user
+type ExportUserDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Format        ExportFormat           `protobuf:"varint,2,opt,name=format,proto3,enum=todo.v1.ExportFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataRequest) Reset() {
+	*x = ExportUserDataRequest{}
+	mi := &file_todo_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataRequest) ProtoMessage() {}
+
+func (x *ExportUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExportUserDataRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ExportUserDataRequest) GetFormat() ExportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ExportFormat_EXPORT_FORMAT_UNSPECIFIED
+}
+
+// ExportUserDataChunk is one piece of the export bundle
+type ExportUserDataChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"` // Set on the first chunk only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUserDataChunk) Reset() {
+	*x = ExportUserDataChunk{}
+	mi := &file_todo_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUserDataChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUserDataChunk) ProtoMessage() {}
+
+func (x *ExportUserDataChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUserDataChunk.ProtoReflect.Descriptor instead.
+func (*ExportUserDataChunk) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportUserDataChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportUserDataChunk) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+// EraseUserDataRequest erases a user's data across all databases
+type EraseUserDataRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Mode           EraseMode              `protobuf:"varint,2,opt,name=mode,proto3,enum=todo.v1.EraseMode" json:"mode,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EraseUserDataRequest) Reset() {
+	*x = EraseUserDataRequest{}
+	mi := &file_todo_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserDataRequest) ProtoMessage() {}
+
+func (x *EraseUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserDataRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EraseUserDataRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EraseUserDataRequest) GetMode() EraseMode {
+	if x != nil {
+		return x.Mode
+	}
+	return EraseMode_ERASE_MODE_UNSPECIFIED
+}
+
+func (x *EraseUserDataRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type EraseUserDataResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TodosErased           int64                  `protobuf:"varint,1,opt,name=todos_erased,json=todosErased,proto3" json:"todos_erased,omitempty"`
+	IdempotencyKeysErased int64                  `protobuf:"varint,2,opt,name=idempotency_keys_erased,json=idempotencyKeysErased,proto3" json:"idempotency_keys_erased,omitempty"`
+	AuditEventsErased     int64                  `protobuf:"varint,3,opt,name=audit_events_erased,json=auditEventsErased,proto3" json:"audit_events_erased,omitempty"`
+	TranscriptsErased     int64                  `protobuf:"varint,4,opt,name=transcripts_erased,json=transcriptsErased,proto3" json:"transcripts_erased,omitempty"`
+	TombstoneId           string                 `protobuf:"bytes,5,opt,name=tombstone_id,json=tombstoneId,proto3" json:"tombstone_id,omitempty"` // Anonymous id recorded in the audit log
+	LlmUsageErased        int64                  `protobuf:"varint,6,opt,name=llm_usage_erased,json=llmUsageErased,proto3" json:"llm_usage_erased,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *EraseUserDataResponse) Reset() {
+	*x = EraseUserDataResponse{}
+	mi := &file_todo_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserDataResponse) ProtoMessage() {}
+
+func (x *EraseUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserDataResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *EraseUserDataResponse) GetTodosErased() int64 {
+	if x != nil {
+		return x.TodosErased
+	}
+	return 0
+}
+
+func (x *EraseUserDataResponse) GetIdempotencyKeysErased() int64 {
+	if x != nil {
+		return x.IdempotencyKeysErased
+	}
+	return 0
+}
+
+func (x *EraseUserDataResponse) GetAuditEventsErased() int64 {
+	if x != nil {
+		return x.AuditEventsErased
+	}
+	return 0
+}
+
+func (x *EraseUserDataResponse) GetTranscriptsErased() int64 {
+	if x != nil {
+		return x.TranscriptsErased
+	}
+	return 0
+}
+
+func (x *EraseUserDataResponse) GetTombstoneId() string {
+	if x != nil {
+		return x.TombstoneId
+	}
+	return ""
+}
+
+func (x *EraseUserDataResponse) GetLlmUsageErased() int64 {
+	if x != nil {
+		return x.LlmUsageErased
+	}
+	return 0
+}
+
+// RestoreTodoRequest restores a todo from the trash
+type RestoreTodoRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TodoId         int64                  `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RestoreTodoRequest) Reset() {
+	*x = RestoreTodoRequest{}
+	mi := &file_todo_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreTodoRequest) ProtoMessage() {}
+
+func (x *RestoreTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreTodoRequest.ProtoReflect.Descriptor instead.
+func (*RestoreTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RestoreTodoRequest) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *RestoreTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RestoreTodoRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type RestoreTodoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Todo          *Todo                  `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreTodoResponse) Reset() {
+	*x = RestoreTodoResponse{}
+	mi := &file_todo_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreTodoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreTodoResponse) ProtoMessage() {}
+
+func (x *RestoreTodoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreTodoResponse.ProtoReflect.Descriptor instead.
+func (*RestoreTodoResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RestoreTodoResponse) GetTodo() *Todo {
+	if x != nil {
+		return x.Todo
+	}
+	return nil
+}
+
+// EmptyTrashRequest permanently deletes everything in a user's trash
+type EmptyTrashRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EmptyTrashRequest) Reset() {
+	*x = EmptyTrashRequest{}
+	mi := &file_todo_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyTrashRequest) ProtoMessage() {}
+
+func (x *EmptyTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyTrashRequest.ProtoReflect.Descriptor instead.
+func (*EmptyTrashRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *EmptyTrashRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EmptyTrashRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type EmptyTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TodosDeleted  int64                  `protobuf:"varint,1,opt,name=todos_deleted,json=todosDeleted,proto3" json:"todos_deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmptyTrashResponse) Reset() {
+	*x = EmptyTrashResponse{}
+	mi := &file_todo_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyTrashResponse) ProtoMessage() {}
+
+func (x *EmptyTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyTrashResponse.ProtoReflect.Descriptor instead.
+func (*EmptyTrashResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *EmptyTrashResponse) GetTodosDeleted() int64 {
+	if x != nil {
+		return x.TodosDeleted
+	}
+	return 0
+}
+
+// ImportTodosRequest creates todos from an uploaded file
+type ImportTodosRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Format         TodoFormat             `protobuf:"varint,2,opt,name=format,proto3,enum=todo.v1.TodoFormat" json:"format,omitempty"`
+	Data           []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	DryRun         bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Validate and report errors without creating anything
+	IdempotencyKey string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ImportTodosRequest) Reset() {
+	*x = ImportTodosRequest{}
+	mi := &file_todo_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTodosRequest) ProtoMessage() {}
+
+func (x *ImportTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTodosRequest.ProtoReflect.Descriptor instead.
+func (*ImportTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportTodosRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ImportTodosRequest) GetFormat() TodoFormat {
+	if x != nil {
+		return x.Format
+	}
+	return TodoFormat_TODO_FORMAT_UNSPECIFIED
+}
+
+func (x *ImportTodosRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ImportTodosRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportTodosRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// ImportRowError describes why a single row of an import was rejected
+type ImportRowError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"` // Line number for CSV and todo.txt, position for JSON and iCalendar
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRowError) Reset() {
+	*x = ImportRowError{}
+	mi := &file_todo_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRowError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRowError) ProtoMessage() {}
+
+func (x *ImportRowError) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRowError.ProtoReflect.Descriptor instead.
+func (*ImportRowError) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ImportRowError) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ImportRowError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ImportRowError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ImportTodosResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imported      int32                  `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"` // Todos created, or that would be created on a dry run
+	Rejected      int32                  `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Errors        []*ImportRowError      `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	Todos         []*Todo                `protobuf:"bytes,4,rep,name=todos,proto3" json:"todos,omitempty"` // Ids are unset on a dry run
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportTodosResponse) Reset() {
+	*x = ImportTodosResponse{}
+	mi := &file_todo_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTodosResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTodosResponse) ProtoMessage() {}
+
+func (x *ImportTodosResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTodosResponse.ProtoReflect.Descriptor instead.
+func (*ImportTodosResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ImportTodosResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportTodosResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *ImportTodosResponse) GetErrors() []*ImportRowError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ImportTodosResponse) GetTodos() []*Todo {
+	if x != nil {
+		return x.Todos
+	}
+	return nil
+}
+
+// ExportTodosRequest exports a user's todos
+type ExportTodosRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Format        TodoFormat             `protobuf:"varint,2,opt,name=format,proto3,enum=todo.v1.TodoFormat" json:"format,omitempty"`
+	Status        TodoStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=todo.v1.TodoStatus" json:"status,omitempty"` // UNSPECIFIED exports active and completed todos
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTodosRequest) Reset() {
+	*x = ExportTodosRequest{}
+	mi := &file_todo_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTodosRequest) ProtoMessage() {}
+
+func (x *ExportTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTodosRequest.ProtoReflect.Descriptor instead.
+func (*ExportTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ExportTodosRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ExportTodosRequest) GetFormat() TodoFormat {
+	if x != nil {
+		return x.Format
+	}
+	return TodoFormat_TODO_FORMAT_UNSPECIFIED
+}
+
+func (x *ExportTodosRequest) GetStatus() TodoStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TodoStatus_TODO_STATUS_UNSPECIFIED
+}
+
+type ExportTodosResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Count         int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTodosResponse) Reset() {
+	*x = ExportTodosResponse{}
+	mi := &file_todo_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTodosResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTodosResponse) ProtoMessage() {}
+
+func (x *ExportTodosResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTodosResponse.ProtoReflect.Descriptor instead.
+func (*ExportTodosResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ExportTodosResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportTodosResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ExportTodosResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ExportTodosResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// RecordLLMUsageRequest records the LLM calls made while handling one message
+type RecordLLMUsageRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserId           string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Model            string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Calls            int32                  `protobuf:"varint,3,opt,name=calls,proto3" json:"calls,omitempty"`
+	PromptTokens     int64                  `protobuf:"varint,4,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens int64                  `protobuf:"varint,5,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	CostMicros       int64                  `protobuf:"varint,6,opt,name=cost_micros,json=costMicros,proto3" json:"cost_micros,omitempty"`            // Millionths of a US dollar
+	IdempotencyKey   string                 `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // A redelivered message is only counted once
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RecordLLMUsageRequest) Reset() {
+	*x = RecordLLMUsageRequest{}
+	mi := &file_todo_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordLLMUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordLLMUsageRequest) ProtoMessage() {}
+
+func (x *RecordLLMUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordLLMUsageRequest.ProtoReflect.Descriptor instead.
+func (*RecordLLMUsageRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RecordLLMUsageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RecordLLMUsageRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *RecordLLMUsageRequest) GetCalls() int32 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *RecordLLMUsageRequest) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *RecordLLMUsageRequest) GetCompletionTokens() int64 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *RecordLLMUsageRequest) GetCostMicros() int64 {
+	if x != nil {
+		return x.CostMicros
+	}
+	return 0
+}
+
+func (x *RecordLLMUsageRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type RecordLLMUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Recorded      bool                   `protobuf:"varint,1,opt,name=recorded,proto3" json:"recorded,omitempty"` // False if the idempotency key was already used
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordLLMUsageResponse) Reset() {
+	*x = RecordLLMUsageResponse{}
+	mi := &file_todo_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordLLMUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordLLMUsageResponse) ProtoMessage() {}
+
+func (x *RecordLLMUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordLLMUsageResponse.ProtoReflect.Descriptor instead.
+func (*RecordLLMUsageResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RecordLLMUsageResponse) GetRecorded() bool {
+	if x != nil {
+		return x.Recorded
+	}
+	return false
+}
+
+// GetLLMUsageRequest selects the usage to total
+type GetLLMUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Optional: all users if empty
+	Since         *timestamp.Timestamp   `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`                 // Optional: from the beginning if unset
+	Until         *timestamp.Timestamp   `protobuf:"bytes,3,opt,name=until,proto3" json:"until,omitempty"`                 // Optional: up to now if unset
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`           // Optional: IANA timezone used for day boundaries (default UTC)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLLMUsageRequest) Reset() {
+	*x = GetLLMUsageRequest{}
+	mi := &file_todo_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLLMUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLLMUsageRequest) ProtoMessage() {}
+
+func (x *GetLLMUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLLMUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetLLMUsageRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetLLMUsageRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetLLMUsageRequest) GetSince() *timestamp.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetLLMUsageRequest) GetUntil() *timestamp.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *GetLLMUsageRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+// LLMUsage is the usage of one model by one user on one day
+type LLMUsage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserId           string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Date             string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"` // YYYY-MM-DD in the requested timezone
+	Model            string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Calls            int32                  `protobuf:"varint,4,opt,name=calls,proto3" json:"calls,omitempty"`
+	PromptTokens     int64                  `protobuf:"varint,5,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens int64                  `protobuf:"varint,6,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	CostMicros       int64                  `protobuf:"varint,7,opt,name=cost_micros,json=costMicros,proto3" json:"cost_micros,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LLMUsage) Reset() {
+	*x = LLMUsage{}
+	mi := &file_todo_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLMUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLMUsage) ProtoMessage() {}
+
+func (x *LLMUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLMUsage.ProtoReflect.Descriptor instead.
+func (*LLMUsage) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LLMUsage) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *LLMUsage) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *LLMUsage) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *LLMUsage) GetCalls() int32 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *LLMUsage) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *LLMUsage) GetCompletionTokens() int64 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *LLMUsage) GetCostMicros() int64 {
+	if x != nil {
+		return x.CostMicros
+	}
+	return 0
+}
+
+type GetLLMUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Usage         []*LLMUsage            `protobuf:"bytes,1,rep,name=usage,proto3" json:"usage,omitempty"` // Ordered by user, date and model
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLLMUsageResponse) Reset() {
+	*x = GetLLMUsageResponse{}
+	mi := &file_todo_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLLMUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLLMUsageResponse) ProtoMessage() {}
+
+func (x *GetLLMUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLLMUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetLLMUsageResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetLLMUsageResponse) GetUsage() []*LLMUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+var File_todo_proto protoreflect.FileDescriptor
+
+const file_todo_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"todo.proto\x12\atodo.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd1\x03\n" +
+	"\x04Todo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12+\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x13.todo.v1.TodoStatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcompleted_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x121\n" +
+	"\x06due_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x12\x1a\n" +
+	"\bpriority\x18\n" +
+	" \x01(\x05R\bpriority\x12\x1b\n" +
+	"\tparent_id\x18\v \x01(\x03R\bparentId\x12\x1a\n" +
+	"\bcategory\x18\f \x01(\tR\bcategory\"\xc6\x01\n" +
+	"\x11CreateTodoRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12\x1b\n" +
+	"\tparent_id\x18\x05 \x01(\x03R\bparentId\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\"7\n" +
+	"\x12CreateTodoResponse\x12!\n" +
+	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\xaf\x01\n" +
+	"\x13CompleteTodoRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12=\n" +
+	"\fcompleted_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"9\n" +
+	"\x14CompleteTodoResponse\x12!\n" +
+	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\x80\x02\n" +
+	"\x10ListTodosRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.todo.v1.TodoStatusR\x06status\x12C\n" +
+	"\x0fcompleted_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0ecompletedAfter\x12E\n" +
+	"\x10completed_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0fcompletedBefore\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\"8\n" +
+	"\x11ListTodosResponse\x12#\n" +
+	"\x05todos\x18\x01 \x03(\v2\r.todo.v1.TodoR\x05todos\"n\n" +
+	"\x11DeleteTodoRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"7\n" +
+	"\x12DeleteTodoResponse\x12!\n" +
+	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\xa4\x01\n" +
+	"\x0fEditTodoRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"5\n" +
+	"\x10EditTodoResponse\x12!\n" +
+	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"\x93\x01\n" +
+	"\x0fGetStatsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\btimezone\x18\x02 \x01(\tR\btimezone\x12\x12\n" +
+	"\x04days\x18\x03 \x01(\x05R\x04days\x12\x14\n" +
+	"\x05weeks\x18\x04 \x01(\x05R\x05weeks\x12!\n" +
+	"\foldest_limit\x18\x05 \x01(\x05R\voldestLimit\"<\n" +
+	"\bDayCount\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x1c\n" +
+	"\tcompleted\x18\x02 \x01(\x05R\tcompleted\"H\n" +
+	"\tWeekCount\x12\x1d\n" +
+	"\n" +
+	"week_start\x18\x01 \x01(\tR\tweekStart\x12\x1c\n" +
+	"\tcompleted\x18\x02 \x01(\x05R\tcompleted\"\xeb\x02\n" +
+	"\x10GetStatsResponse\x12'\n" +
+	"\x05daily\x18\x01 \x03(\v2\x11.todo.v1.DayCountR\x05daily\x12*\n" +
+	"\x06weekly\x18\x02 \x03(\v2\x12.todo.v1.WeekCountR\x06weekly\x12%\n" +
+	"\x0ecurrent_streak\x18\x03 \x01(\x05R\rcurrentStreak\x12%\n" +
+	"\x0elongest_streak\x18\x04 \x01(\x05R\rlongestStreak\x12<\n" +
+	"\x1aaverage_completion_seconds\x18\x05 \x01(\x03R\x18averageCompletionSeconds\x12.\n" +
+	"\voldest_open\x18\x06 \x03(\v2\r.todo.v1.TodoR\n" +
+	"oldestOpen\x12'\n" +
+	"\x0ftotal_completed\x18\a \x01(\x05R\x0etotalCompleted\x12\x1d\n" +
+	"\n" +
+	"total_open\x18\b \x01(\x05R\ttotalOpen\"_\n" +
+	"\x15ExportUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12-\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x15.todo.v1.ExportFormatR\x06format\"E\n" +
+	"\x13ExportUserDataChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\"\x80\x01\n" +
+	"\x14EraseUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x12.todo.v1.EraseModeR\x04mode\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"\x9e\x02\n" +
+	"\x15EraseUserDataResponse\x12!\n" +
+	"\ftodos_erased\x18\x01 \x01(\x03R\vtodosErased\x126\n" +
+	"\x17idempotency_keys_erased\x18\x02 \x01(\x03R\x15idempotencyKeysErased\x12.\n" +
+	"\x13audit_events_erased\x18\x03 \x01(\x03R\x11auditEventsErased\x12-\n" +
+	"\x12transcripts_erased\x18\x04 \x01(\x03R\x11transcriptsErased\x12!\n" +
+	"\ftombstone_id\x18\x05 \x01(\tR\vtombstoneId\x12(\n" +
+	"\x10llm_usage_erased\x18\x06 \x01(\x03R\x0ellmUsageErased\"o\n" +
+	"\x12RestoreTodoRequest\x12\x17\n" +
+	"\atodo_id\x18\x01 \x01(\x03R\x06todoId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"8\n" +
+	"\x13RestoreTodoResponse\x12!\n" +
+	"\x04todo\x18\x01 \x01(\v2\r.todo.v1.TodoR\x04todo\"U\n" +
+	"\x11EmptyTrashRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\"9\n" +
+	"\x12EmptyTrashResponse\x12#\n" +
+	"\rtodos_deleted\x18\x01 \x01(\x03R\ftodosDeleted\"\xb0\x01\n" +
+	"\x12ImportTodosRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x13.todo.v1.TodoFormatR\x06format\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"R\n" +
+	"\x0eImportRowError\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xa3\x01\n" +
+	"\x13ImportTodosResponse\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x05R\bimported\x12\x1a\n" +
+	"\brejected\x18\x02 \x01(\x05R\brejected\x12/\n" +
+	"\x06errors\x18\x03 \x03(\v2\x17.todo.v1.ImportRowErrorR\x06errors\x12#\n" +
+	"\x05todos\x18\x04 \x03(\v2\r.todo.v1.TodoR\x05todos\"\x87\x01\n" +
+	"\x12ExportTodosRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12+\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x13.todo.v1.TodoFormatR\x06format\x12+\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x13.todo.v1.TodoStatusR\x06status\"~\n" +
+	"\x13ExportTodosResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05count\"\xf8\x01\n" +
+	"\x15RecordLLMUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x14\n" +
+	"\x05calls\x18\x03 \x01(\x05R\x05calls\x12#\n" +
+	"\rprompt_tokens\x18\x04 \x01(\x03R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\x05 \x01(\x03R\x10completionTokens\x12\x1f\n" +
+	"\vcost_micros\x18\x06 \x01(\x03R\n" +
+	"costMicros\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\"4\n" +
+	"\x16RecordLLMUsageResponse\x12\x1a\n" +
+	"\brecorded\x18\x01 \x01(\bR\brecorded\"\xad\x01\n" +
+	"\x12GetLLMUsageRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\"\xd6\x01\n" +
+	"\bLLMUsage\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x14\n" +
+	"\x05calls\x18\x04 \x01(\x05R\x05calls\x12#\n" +
+	"\rprompt_tokens\x18\x05 \x01(\x03R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\x06 \x01(\x03R\x10completionTokens\x12\x1f\n" +
+	"\vcost_micros\x18\a \x01(\x03R\n" +
+	"costMicros\">\n" +
+	"\x13GetLLMUsageResponse\x12'\n" +
+	"\x05usage\x18\x01 \x03(\v2\x11.todo.v1.LLMUsageR\x05usage*u\n" +
+	"\n" +
+	"TodoStatus\x12\x1b\n" +
+	"\x17TODO_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12TODO_STATUS_ACTIVE\x10\x01\x12\x19\n" +
+	"\x15TODO_STATUS_COMPLETED\x10\x02\x12\x17\n" +
+	"\x13TODO_STATUS_DELETED\x10\x03*\\\n" +
+	"\fExportFormat\x12\x1d\n" +
+	"\x19EXPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12EXPORT_FORMAT_JSON\x10\x01\x12\x15\n" +
+	"\x11EXPORT_FORMAT_ZIP\x10\x02*]\n" +
+	"\tEraseMode\x12\x1a\n" +
+	"\x16ERASE_MODE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16ERASE_MODE_HARD_DELETE\x10\x01\x12\x18\n" +
+	"\x14ERASE_MODE_ANONYMIZE\x10\x02*\x89\x01\n" +
+	"\n" +
+	"TodoFormat\x12\x1b\n" +
+	"\x17TODO_FORMAT_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fTODO_FORMAT_CSV\x10\x01\x12\x14\n" +
+	"\x10TODO_FORMAT_JSON\x10\x02\x12\x18\n" +
+	"\x14TODO_FORMAT_TODO_TXT\x10\x03\x12\x19\n" +
+	"\x15TODO_FORMAT_ICALENDAR\x10\x042\x91\b\n" +
+	"\n" +
+	"TodoDomain\x12E\n" +
+	"\n" +
+	"CreateTodo\x12\x1a.todo.v1.CreateTodoRequest\x1a\x1b.todo.v1.CreateTodoResponse\x12K\n" +
+	"\fCompleteTodo\x12\x1c.todo.v1.CompleteTodoRequest\x1a\x1d.todo.v1.CompleteTodoResponse\x12B\n" +
+	"\tListTodos\x12\x19.todo.v1.ListTodosRequest\x1a\x1a.todo.v1.ListTodosResponse\x12E\n" +
+	"\n" +
+	"DeleteTodo\x12\x1a.todo.v1.DeleteTodoRequest\x1a\x1b.todo.v1.DeleteTodoResponse\x12?\n" +
+	"\bEditTodo\x12\x18.todo.v1.EditTodoRequest\x1a\x19.todo.v1.EditTodoResponse\x12?\n" +
+	"\bGetStats\x12\x18.todo.v1.GetStatsRequest\x1a\x19.todo.v1.GetStatsResponse\x12P\n" +
+	"\x0eExportUserData\x12\x1e.todo.v1.ExportUserDataRequest\x1a\x1c.todo.v1.ExportUserDataChunk0\x01\x12N\n" +
+	"\rEraseUserData\x12\x1d.todo.v1.EraseUserDataRequest\x1a\x1e.todo.v1.EraseUserDataResponse\x12H\n" +
+	"\vRestoreTodo\x12\x1b.todo.v1.RestoreTodoRequest\x1a\x1c.todo.v1.RestoreTodoResponse\x12E\n" +
+	"\n" +
+	"EmptyTrash\x12\x1a.todo.v1.EmptyTrashRequest\x1a\x1b.todo.v1.EmptyTrashResponse\x12H\n" +
+	"\vImportTodos\x12\x1b.todo.v1.ImportTodosRequest\x1a\x1c.todo.v1.ImportTodosResponse\x12H\n" +
+	"\vExportTodos\x12\x1b.todo.v1.ExportTodosRequest\x1a\x1c.todo.v1.ExportTodosResponse\x12Q\n" +
+	"\x0eRecordLLMUsage\x12\x1e.todo.v1.RecordLLMUsageRequest\x1a\x1f.todo.v1.RecordLLMUsageResponse\x12H\n" +
+	"\vGetLLMUsage\x12\x1b.todo.v1.GetLLMUsageRequest\x1a\x1c.todo.v1.GetLLMUsageResponseB\x1fZ\x1dhound-todo/api/todo/v1;todov1b\x06proto3"
 
 var (
 	file_todo_proto_rawDescOnce sync.Once
@@ -811,51 +2583,110 @@ func file_todo_proto_rawDescGZIP() []byte {
 	return file_todo_proto_rawDescData
 }
 
-var file_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_todo_proto_goTypes = []any{
-	(TodoStatus)(0),              // 0: todo.v1.TodoStatus
-	(*Todo)(nil),                 // 1: todo.v1.Todo
-	(*CreateTodoRequest)(nil),    // 2: todo.v1.CreateTodoRequest
-	(*CreateTodoResponse)(nil),   // 3: todo.v1.CreateTodoResponse
-	(*CompleteTodoRequest)(nil),  // 4: todo.v1.CompleteTodoRequest
-	(*CompleteTodoResponse)(nil), // 5: todo.v1.CompleteTodoResponse
-	(*ListTodosRequest)(nil),     // 6: todo.v1.ListTodosRequest
-	(*ListTodosResponse)(nil),    // 7: todo.v1.ListTodosResponse
-	(*DeleteTodoRequest)(nil),    // 8: todo.v1.DeleteTodoRequest
-	(*DeleteTodoResponse)(nil),   // 9: todo.v1.DeleteTodoResponse
-	(*EditTodoRequest)(nil),      // 10: todo.v1.EditTodoRequest
-	(*EditTodoResponse)(nil),     // 11: todo.v1.EditTodoResponse
-	(*timestamp.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+	(TodoStatus)(0),                // 0: todo.v1.TodoStatus
+	(ExportFormat)(0),              // 1: todo.v1.ExportFormat
+	(EraseMode)(0),                 // 2: todo.v1.EraseMode
+	(TodoFormat)(0),                // 3: todo.v1.TodoFormat
+	(*Todo)(nil),                   // 4: todo.v1.Todo
+	(*CreateTodoRequest)(nil),      // 5: todo.v1.CreateTodoRequest
+	(*CreateTodoResponse)(nil),     // 6: todo.v1.CreateTodoResponse
+	(*CompleteTodoRequest)(nil),    // 7: todo.v1.CompleteTodoRequest
+	(*CompleteTodoResponse)(nil),   // 8: todo.v1.CompleteTodoResponse
+	(*ListTodosRequest)(nil),       // 9: todo.v1.ListTodosRequest
+	(*ListTodosResponse)(nil),      // 10: todo.v1.ListTodosResponse
+	(*DeleteTodoRequest)(nil),      // 11: todo.v1.DeleteTodoRequest
+	(*DeleteTodoResponse)(nil),     // 12: todo.v1.DeleteTodoResponse
+	(*EditTodoRequest)(nil),        // 13: todo.v1.EditTodoRequest
+	(*EditTodoResponse)(nil),       // 14: todo.v1.EditTodoResponse
+	(*GetStatsRequest)(nil),        // 15: todo.v1.GetStatsRequest
+	(*DayCount)(nil),               // 16: todo.v1.DayCount
+	(*WeekCount)(nil),              // 17: todo.v1.WeekCount
+	(*GetStatsResponse)(nil),       // 18: todo.v1.GetStatsResponse
+	(*ExportUserDataRequest)(nil),  // 19: todo.v1.ExportUserDataRequest
+	(*ExportUserDataChunk)(nil),    // 20: todo.v1.ExportUserDataChunk
+	(*EraseUserDataRequest)(nil),   // 21: todo.v1.EraseUserDataRequest
+	(*EraseUserDataResponse)(nil),  // 22: todo.v1.EraseUserDataResponse
+	(*RestoreTodoRequest)(nil),     // 23: todo.v1.RestoreTodoRequest
+	(*RestoreTodoResponse)(nil),    // 24: todo.v1.RestoreTodoResponse
+	(*EmptyTrashRequest)(nil),      // 25: todo.v1.EmptyTrashRequest
+	(*EmptyTrashResponse)(nil),     // 26: todo.v1.EmptyTrashResponse
+	(*ImportTodosRequest)(nil),     // 27: todo.v1.ImportTodosRequest
+	(*ImportRowError)(nil),         // 28: todo.v1.ImportRowError
+	(*ImportTodosResponse)(nil),    // 29: todo.v1.ImportTodosResponse
+	(*ExportTodosRequest)(nil),     // 30: todo.v1.ExportTodosRequest
+	(*ExportTodosResponse)(nil),    // 31: todo.v1.ExportTodosResponse
+	(*RecordLLMUsageRequest)(nil),  // 32: todo.v1.RecordLLMUsageRequest
+	(*RecordLLMUsageResponse)(nil), // 33: todo.v1.RecordLLMUsageResponse
+	(*GetLLMUsageRequest)(nil),     // 34: todo.v1.GetLLMUsageRequest
+	(*LLMUsage)(nil),               // 35: todo.v1.LLMUsage
+	(*GetLLMUsageResponse)(nil),    // 36: todo.v1.GetLLMUsageResponse
+	(*timestamp.Timestamp)(nil),    // 37: google.protobuf.Timestamp
 }
 var file_todo_proto_depIdxs = []int32{
 	0,  // 0: todo.v1.Todo.status:type_name -> todo.v1.TodoStatus
-	12, // 1: todo.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
-	12, // 2: todo.v1.Todo.completed_at:type_name -> google.protobuf.Timestamp
-	1,  // 3: todo.v1.CreateTodoResponse.todo:type_name -> todo.v1.Todo
-	12, // 4: todo.v1.CompleteTodoRequest.completed_at:type_name -> google.protobuf.Timestamp
-	1,  // 5: todo.v1.CompleteTodoResponse.todo:type_name -> todo.v1.Todo
-	0,  // 6: todo.v1.ListTodosRequest.status:type_name -> todo.v1.TodoStatus
-	12, // 7: todo.v1.ListTodosRequest.completed_after:type_name -> google.protobuf.Timestamp
-	12, // 8: todo.v1.ListTodosRequest.completed_before:type_name -> google.protobuf.Timestamp
-	1,  // 9: todo.v1.ListTodosResponse.todos:type_name -> todo.v1.Todo
-	1,  // 10: todo.v1.DeleteTodoResponse.todo:type_name -> todo.v1.Todo
-	1,  // 11: todo.v1.EditTodoResponse.todo:type_name -> todo.v1.Todo
-	2,  // 12: todo.v1.TodoDomain.CreateTodo:input_type -> todo.v1.CreateTodoRequest
-	4,  // 13: todo.v1.TodoDomain.CompleteTodo:input_type -> todo.v1.CompleteTodoRequest
-	6,  // 14: todo.v1.TodoDomain.ListTodos:input_type -> todo.v1.ListTodosRequest
-	8,  // 15: todo.v1.TodoDomain.DeleteTodo:input_type -> todo.v1.DeleteTodoRequest
-	10, // 16: todo.v1.TodoDomain.EditTodo:input_type -> todo.v1.EditTodoRequest
-	3,  // 17: todo.v1.TodoDomain.CreateTodo:output_type -> todo.v1.CreateTodoResponse
-	5,  // 18: todo.v1.TodoDomain.CompleteTodo:output_type -> todo.v1.CompleteTodoResponse
-	7,  // 19: todo.v1.TodoDomain.ListTodos:output_type -> todo.v1.ListTodosResponse
-	9,  // 20: todo.v1.TodoDomain.DeleteTodo:output_type -> todo.v1.DeleteTodoResponse
-	11, // 21: todo.v1.TodoDomain.EditTodo:output_type -> todo.v1.EditTodoResponse
-	17, // [17:22] is the sub-list for method output_type
-	12, // [12:17] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	37, // 1: todo.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
+	37, // 2: todo.v1.Todo.completed_at:type_name -> google.protobuf.Timestamp
+	37, // 3: todo.v1.Todo.deleted_at:type_name -> google.protobuf.Timestamp
+	37, // 4: todo.v1.Todo.due_at:type_name -> google.protobuf.Timestamp
+	4,  // 5: todo.v1.CreateTodoResponse.todo:type_name -> todo.v1.Todo
+	37, // 6: todo.v1.CompleteTodoRequest.completed_at:type_name -> google.protobuf.Timestamp
+	4,  // 7: todo.v1.CompleteTodoResponse.todo:type_name -> todo.v1.Todo
+	0,  // 8: todo.v1.ListTodosRequest.status:type_name -> todo.v1.TodoStatus
+	37, // 9: todo.v1.ListTodosRequest.completed_after:type_name -> google.protobuf.Timestamp
+	37, // 10: todo.v1.ListTodosRequest.completed_before:type_name -> google.protobuf.Timestamp
+	4,  // 11: todo.v1.ListTodosResponse.todos:type_name -> todo.v1.Todo
+	4,  // 12: todo.v1.DeleteTodoResponse.todo:type_name -> todo.v1.Todo
+	4,  // 13: todo.v1.EditTodoResponse.todo:type_name -> todo.v1.Todo
+	16, // 14: todo.v1.GetStatsResponse.daily:type_name -> todo.v1.DayCount
+	17, // 15: todo.v1.GetStatsResponse.weekly:type_name -> todo.v1.WeekCount
+	4,  // 16: todo.v1.GetStatsResponse.oldest_open:type_name -> todo.v1.Todo
+	1,  // 17: todo.v1.ExportUserDataRequest.format:type_name -> todo.v1.ExportFormat
+	2,  // 18: todo.v1.EraseUserDataRequest.mode:type_name -> todo.v1.EraseMode
+	4,  // 19: todo.v1.RestoreTodoResponse.todo:type_name -> todo.v1.Todo
+	3,  // 20: todo.v1.ImportTodosRequest.format:type_name -> todo.v1.TodoFormat
+	28, // 21: todo.v1.ImportTodosResponse.errors:type_name -> todo.v1.ImportRowError
+	4,  // 22: todo.v1.ImportTodosResponse.todos:type_name -> todo.v1.Todo
+	3,  // 23: todo.v1.ExportTodosRequest.format:type_name -> todo.v1.TodoFormat
+	0,  // 24: todo.v1.ExportTodosRequest.status:type_name -> todo.v1.TodoStatus
+	37, // 25: todo.v1.GetLLMUsageRequest.since:type_name -> google.protobuf.Timestamp
+	37, // 26: todo.v1.GetLLMUsageRequest.until:type_name -> google.protobuf.Timestamp
+	35, // 27: todo.v1.GetLLMUsageResponse.usage:type_name -> todo.v1.LLMUsage
+	5,  // 28: todo.v1.TodoDomain.CreateTodo:input_type -> todo.v1.CreateTodoRequest
+	7,  // 29: todo.v1.TodoDomain.CompleteTodo:input_type -> todo.v1.CompleteTodoRequest
+	9,  // 30: todo.v1.TodoDomain.ListTodos:input_type -> todo.v1.ListTodosRequest
+	11, // 31: todo.v1.TodoDomain.DeleteTodo:input_type -> todo.v1.DeleteTodoRequest
+	13, // 32: todo.v1.TodoDomain.EditTodo:input_type -> todo.v1.EditTodoRequest
+	15, // 33: todo.v1.TodoDomain.GetStats:input_type -> todo.v1.GetStatsRequest
+	19, // 34: todo.v1.TodoDomain.ExportUserData:input_type -> todo.v1.ExportUserDataRequest
+	21, // 35: todo.v1.TodoDomain.EraseUserData:input_type -> todo.v1.EraseUserDataRequest
+	23, // 36: todo.v1.TodoDomain.RestoreTodo:input_type -> todo.v1.RestoreTodoRequest
+	25, // 37: todo.v1.TodoDomain.EmptyTrash:input_type -> todo.v1.EmptyTrashRequest
+	27, // 38: todo.v1.TodoDomain.ImportTodos:input_type -> todo.v1.ImportTodosRequest
+	30, // 39: todo.v1.TodoDomain.ExportTodos:input_type -> todo.v1.ExportTodosRequest
+	32, // 40: todo.v1.TodoDomain.RecordLLMUsage:input_type -> todo.v1.RecordLLMUsageRequest
+	34, // 41: todo.v1.TodoDomain.GetLLMUsage:input_type -> todo.v1.GetLLMUsageRequest
+	6,  // 42: todo.v1.TodoDomain.CreateTodo:output_type -> todo.v1.CreateTodoResponse
+	8,  // 43: todo.v1.TodoDomain.CompleteTodo:output_type -> todo.v1.CompleteTodoResponse
+	10, // 44: todo.v1.TodoDomain.ListTodos:output_type -> todo.v1.ListTodosResponse
+	12, // 45: todo.v1.TodoDomain.DeleteTodo:output_type -> todo.v1.DeleteTodoResponse
+	14, // 46: todo.v1.TodoDomain.EditTodo:output_type -> todo.v1.EditTodoResponse
+	18, // 47: todo.v1.TodoDomain.GetStats:output_type -> todo.v1.GetStatsResponse
+	20, // 48: todo.v1.TodoDomain.ExportUserData:output_type -> todo.v1.ExportUserDataChunk
+	22, // 49: todo.v1.TodoDomain.EraseUserData:output_type -> todo.v1.EraseUserDataResponse
+	24, // 50: todo.v1.TodoDomain.RestoreTodo:output_type -> todo.v1.RestoreTodoResponse
+	26, // 51: todo.v1.TodoDomain.EmptyTrash:output_type -> todo.v1.EmptyTrashResponse
+	29, // 52: todo.v1.TodoDomain.ImportTodos:output_type -> todo.v1.ImportTodosResponse
+	31, // 53: todo.v1.TodoDomain.ExportTodos:output_type -> todo.v1.ExportTodosResponse
+	33, // 54: todo.v1.TodoDomain.RecordLLMUsage:output_type -> todo.v1.RecordLLMUsageResponse
+	36, // 55: todo.v1.TodoDomain.GetLLMUsage:output_type -> todo.v1.GetLLMUsageResponse
+	42, // [42:56] is the sub-list for method output_type
+	28, // [28:42] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_todo_proto_init() }
@@ -868,8 +2699,8 @@ func file_todo_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_proto_rawDesc), len(file_todo_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   11,
+			NumEnums:      4,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,11 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TodoDomain_CreateTodo_FullMethodName   = "/todo.v1.TodoDomain/CreateTodo"
-	TodoDomain_CompleteTodo_FullMethodName = "/todo.v1.TodoDomain/CompleteTodo"
-	TodoDomain_ListTodos_FullMethodName    = "/todo.v1.TodoDomain/ListTodos"
-	TodoDomain_DeleteTodo_FullMethodName   = "/todo.v1.TodoDomain/DeleteTodo"
-	TodoDomain_EditTodo_FullMethodName     = "/todo.v1.TodoDomain/EditTodo"
+	TodoDomain_CreateTodo_FullMethodName     = "/todo.v1.TodoDomain/CreateTodo"
+	TodoDomain_CompleteTodo_FullMethodName   = "/todo.v1.TodoDomain/CompleteTodo"
+	TodoDomain_ListTodos_FullMethodName      = "/todo.v1.TodoDomain/ListTodos"
+	TodoDomain_DeleteTodo_FullMethodName     = "/todo.v1.TodoDomain/DeleteTodo"
+	TodoDomain_EditTodo_FullMethodName       = "/todo.v1.TodoDomain/EditTodo"
+	TodoDomain_GetStats_FullMethodName       = "/todo.v1.TodoDomain/GetStats"
+	TodoDomain_ExportUserData_FullMethodName = "/todo.v1.TodoDomain/ExportUserData"
+	TodoDomain_EraseUserData_FullMethodName  = "/todo.v1.TodoDomain/EraseUserData"
+	TodoDomain_RestoreTodo_FullMethodName    = "/todo.v1.TodoDomain/RestoreTodo"
+	TodoDomain_EmptyTrash_FullMethodName     = "/todo.v1.TodoDomain/EmptyTrash"
+	TodoDomain_ImportTodos_FullMethodName    = "/todo.v1.TodoDomain/ImportTodos"
+	TodoDomain_ExportTodos_FullMethodName    = "/todo.v1.TodoDomain/ExportTodos"
+	TodoDomain_RecordLLMUsage_FullMethodName = "/todo.v1.TodoDomain/RecordLLMUsage"
+	TodoDomain_GetLLMUsage_FullMethodName    = "/todo.v1.TodoDomain/GetLLMUsage"
 )
 
 // TodoDomainClient is the client API for TodoDomain service.
@@ -42,6 +51,24 @@ type TodoDomainClient interface {
 	DeleteTodo(ctx context.Context, in *DeleteTodoRequest, opts ...grpc.CallOption) (*DeleteTodoResponse, error)
 	// EditTodo updates a todo's title or description
 	EditTodo(ctx context.Context, in *EditTodoRequest, opts ...grpc.CallOption) (*EditTodoResponse, error)
+	// GetStats returns completion statistics and streaks for a user
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// ExportUserData streams a bundle of everything stored about a user
+	ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUserDataChunk], error)
+	// EraseUserData deletes or anonymizes a user's data across all databases
+	EraseUserData(ctx context.Context, in *EraseUserDataRequest, opts ...grpc.CallOption) (*EraseUserDataResponse, error)
+	// RestoreTodo brings a soft-deleted todo back out of the trash
+	RestoreTodo(ctx context.Context, in *RestoreTodoRequest, opts ...grpc.CallOption) (*RestoreTodoResponse, error)
+	// EmptyTrash hard-deletes all of a user's soft-deleted todos
+	EmptyTrash(ctx context.Context, in *EmptyTrashRequest, opts ...grpc.CallOption) (*EmptyTrashResponse, error)
+	// ImportTodos creates todos from a file exported by another todo app
+	ImportTodos(ctx context.Context, in *ImportTodosRequest, opts ...grpc.CallOption) (*ImportTodosResponse, error)
+	// ExportTodos encodes a user's todos in a format other todo apps can read
+	ExportTodos(ctx context.Context, in *ExportTodosRequest, opts ...grpc.CallOption) (*ExportTodosResponse, error)
+	// RecordLLMUsage stores the tokens and cost of the LLM calls made for one message
+	RecordLLMUsage(ctx context.Context, in *RecordLLMUsageRequest, opts ...grpc.CallOption) (*RecordLLMUsageResponse, error)
+	// GetLLMUsage totals LLM usage per user, day and model over a period
+	GetLLMUsage(ctx context.Context, in *GetLLMUsageRequest, opts ...grpc.CallOption) (*GetLLMUsageResponse, error)
 }
 
 type todoDomainClient struct {
@@ -102,6 +129,105 @@ func (c *todoDomainClient) EditTodo(ctx context.Context, in *EditTodoRequest, op
 	return out, nil
 }
 
+func (c *todoDomainClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUserDataChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TodoDomain_ServiceDesc.Streams[0], TodoDomain_ExportUserData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportUserDataRequest, ExportUserDataChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TodoDomain_ExportUserDataClient = grpc.ServerStreamingClient[ExportUserDataChunk]
+
+func (c *todoDomainClient) EraseUserData(ctx context.Context, in *EraseUserDataRequest, opts ...grpc.CallOption) (*EraseUserDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EraseUserDataResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_EraseUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) RestoreTodo(ctx context.Context, in *RestoreTodoRequest, opts ...grpc.CallOption) (*RestoreTodoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreTodoResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_RestoreTodo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) EmptyTrash(ctx context.Context, in *EmptyTrashRequest, opts ...grpc.CallOption) (*EmptyTrashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EmptyTrashResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_EmptyTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) ImportTodos(ctx context.Context, in *ImportTodosRequest, opts ...grpc.CallOption) (*ImportTodosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportTodosResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_ImportTodos_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) ExportTodos(ctx context.Context, in *ExportTodosRequest, opts ...grpc.CallOption) (*ExportTodosResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportTodosResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_ExportTodos_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) RecordLLMUsage(ctx context.Context, in *RecordLLMUsageRequest, opts ...grpc.CallOption) (*RecordLLMUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordLLMUsageResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_RecordLLMUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoDomainClient) GetLLMUsage(ctx context.Context, in *GetLLMUsageRequest, opts ...grpc.CallOption) (*GetLLMUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLLMUsageResponse)
+	err := c.cc.Invoke(ctx, TodoDomain_GetLLMUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TodoDomainServer is the server API for TodoDomain service.
 // All implementations must embed UnimplementedTodoDomainServer
 // for forward compatibility.
@@ -118,6 +244,24 @@ type TodoDomainServer interface {
 	DeleteTodo(context.Context, *DeleteTodoRequest) (*DeleteTodoResponse, error)
 	// EditTodo updates a todo's title or description
 	EditTodo(context.Context, *EditTodoRequest) (*EditTodoResponse, error)
+	// GetStats returns completion statistics and streaks for a user
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// ExportUserData streams a bundle of everything stored about a user
+	ExportUserData(*ExportUserDataRequest, grpc.ServerStreamingServer[ExportUserDataChunk]) error
+	// EraseUserData deletes or anonymizes a user's data across all databases
+	EraseUserData(context.Context, *EraseUserDataRequest) (*EraseUserDataResponse, error)
+	// RestoreTodo brings a soft-deleted todo back out of the trash
+	RestoreTodo(context.Context, *RestoreTodoRequest) (*RestoreTodoResponse, error)
+	// EmptyTrash hard-deletes all of a user's soft-deleted todos
+	EmptyTrash(context.Context, *EmptyTrashRequest) (*EmptyTrashResponse, error)
+	// ImportTodos creates todos from a file exported by another todo app
+	ImportTodos(context.Context, *ImportTodosRequest) (*ImportTodosResponse, error)
+	// ExportTodos encodes a user's todos in a format other todo apps can read
+	ExportTodos(context.Context, *ExportTodosRequest) (*ExportTodosResponse, error)
+	// RecordLLMUsage stores the tokens and cost of the LLM calls made for one message
+	RecordLLMUsage(context.Context, *RecordLLMUsageRequest) (*RecordLLMUsageResponse, error)
+	// GetLLMUsage totals LLM usage per user, day and model over a period
+	GetLLMUsage(context.Context, *GetLLMUsageRequest) (*GetLLMUsageResponse, error)
 	mustEmbedUnimplementedTodoDomainServer()
 }
 
@@ -143,6 +287,33 @@ func (UnimplementedTodoDomainServer) DeleteTodo(context.Context, *DeleteTodoRequ
 func (UnimplementedTodoDomainServer) EditTodo(context.Context, *EditTodoRequest) (*EditTodoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method EditTodo not implemented")
 }
+func (UnimplementedTodoDomainServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedTodoDomainServer) ExportUserData(*ExportUserDataRequest, grpc.ServerStreamingServer[ExportUserDataChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportUserData not implemented")
+}
+func (UnimplementedTodoDomainServer) EraseUserData(context.Context, *EraseUserDataRequest) (*EraseUserDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EraseUserData not implemented")
+}
+func (UnimplementedTodoDomainServer) RestoreTodo(context.Context, *RestoreTodoRequest) (*RestoreTodoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreTodo not implemented")
+}
+func (UnimplementedTodoDomainServer) EmptyTrash(context.Context, *EmptyTrashRequest) (*EmptyTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EmptyTrash not implemented")
+}
+func (UnimplementedTodoDomainServer) ImportTodos(context.Context, *ImportTodosRequest) (*ImportTodosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportTodos not implemented")
+}
+func (UnimplementedTodoDomainServer) ExportTodos(context.Context, *ExportTodosRequest) (*ExportTodosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportTodos not implemented")
+}
+func (UnimplementedTodoDomainServer) RecordLLMUsage(context.Context, *RecordLLMUsageRequest) (*RecordLLMUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordLLMUsage not implemented")
+}
+func (UnimplementedTodoDomainServer) GetLLMUsage(context.Context, *GetLLMUsageRequest) (*GetLLMUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLLMUsage not implemented")
+}
 func (UnimplementedTodoDomainServer) mustEmbedUnimplementedTodoDomainServer() {}
 func (UnimplementedTodoDomainServer) testEmbeddedByValue()                    {}
 
@@ -254,6 +425,161 @@ func _TodoDomain_EditTodo_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoDomain_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_ExportUserData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUserDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoDomainServer).ExportUserData(m, &grpc.GenericServerStream[ExportUserDataRequest, ExportUserDataChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TodoDomain_ExportUserDataServer = grpc.ServerStreamingServer[ExportUserDataChunk]
+
+func _TodoDomain_EraseUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).EraseUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_EraseUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).EraseUserData(ctx, req.(*EraseUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_RestoreTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreTodoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).RestoreTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_RestoreTodo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).RestoreTodo(ctx, req.(*RestoreTodoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_EmptyTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).EmptyTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_EmptyTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).EmptyTrash(ctx, req.(*EmptyTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_ImportTodos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportTodosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).ImportTodos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_ImportTodos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).ImportTodos(ctx, req.(*ImportTodosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_ExportTodos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportTodosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).ExportTodos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_ExportTodos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).ExportTodos(ctx, req.(*ExportTodosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_RecordLLMUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordLLMUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).RecordLLMUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_RecordLLMUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).RecordLLMUsage(ctx, req.(*RecordLLMUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoDomain_GetLLMUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLLMUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoDomainServer).GetLLMUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoDomain_GetLLMUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoDomainServer).GetLLMUsage(ctx, req.(*GetLLMUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TodoDomain_ServiceDesc is the grpc.ServiceDesc for TodoDomain service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,7 +607,45 @@ var TodoDomain_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "EditTodo",
 			Handler:    _TodoDomain_EditTodo_Handler,
 		},
+		{
+			MethodName: "GetStats",
+			Handler:    _TodoDomain_GetStats_Handler,
+		},
+		{
+			MethodName: "EraseUserData",
+			Handler:    _TodoDomain_EraseUserData_Handler,
+		},
+		{
+			MethodName: "RestoreTodo",
+			Handler:    _TodoDomain_RestoreTodo_Handler,
+		},
+		{
+			MethodName: "EmptyTrash",
+			Handler:    _TodoDomain_EmptyTrash_Handler,
+		},
+		{
+			MethodName: "ImportTodos",
+			Handler:    _TodoDomain_ImportTodos_Handler,
+		},
+		{
+			MethodName: "ExportTodos",
+			Handler:    _TodoDomain_ExportTodos_Handler,
+		},
+		{
+			MethodName: "RecordLLMUsage",
+			Handler:    _TodoDomain_RecordLLMUsage_Handler,
+		},
+		{
+			MethodName: "GetLLMUsage",
+			Handler:    _TodoDomain_GetLLMUsage_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportUserData",
+			Handler:       _TodoDomain_ExportUserData_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "todo.proto",
 }
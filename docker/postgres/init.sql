@@ -27,20 +27,56 @@ CREATE TABLE IF NOT EXISTS todos (
     created_at TIMESTAMP NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
     completed_at TIMESTAMP,
-    deleted_at TIMESTAMP
+    deleted_at TIMESTAMP,
+    due_at TIMESTAMP,
+    priority SMALLINT NOT NULL DEFAULT 0, -- 1 (highest) to 9 (lowest), 0 means none
+    parent_id BIGINT REFERENCES todos(id) ON DELETE SET NULL, -- The todo this is a step of
+    category VARCHAR(50) NOT NULL DEFAULT '' -- Like errands or work, empty if unknown
 );
 
 CREATE INDEX idx_todos_user_id ON todos(user_id);
 CREATE INDEX idx_todos_status ON todos(status);
 CREATE INDEX idx_todos_user_status ON todos(user_id, status);
+CREATE INDEX idx_todos_deleted_at ON todos(deleted_at) WHERE status = 'deleted'; -- retention purge
+
+-- Columns added after the first release, for databases created before them
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_at TIMESTAMP;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES todos(id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS category VARCHAR(50) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_todos_user_category ON todos(user_id, category);
 
 -- Idempotency keys table to prevent duplicate operations
 CREATE TABLE IF NOT EXISTS idempotency_keys (
     key VARCHAR(255) PRIMARY KEY,
+    user_id VARCHAR(255), -- NULL for responses kept after their user was erased
     response JSONB,
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
+-- Added after the first release, for databases created before it
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS user_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_user_id ON idempotency_keys(user_id);
+
+-- LLM tokens and cost per handled message, for budgets and usage reports
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    calls INTEGER NOT NULL DEFAULT 1,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_micros BIGINT NOT NULL DEFAULT 0, -- millionths of a US dollar
+    idempotency_key VARCHAR(255) UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_usage_user_created ON llm_usage(user_id, created_at);
+CREATE INDEX idx_llm_usage_created ON llm_usage(created_at);
+
 -- =============================================================================
 -- audit_db schema (append-only event log)
 -- =============================================================================
@@ -77,3 +113,4 @@ CREATE TABLE IF NOT EXISTS transcriptions (
 
 CREATE INDEX idx_transcriptions_user ON transcriptions(user_id);
 CREATE INDEX idx_transcriptions_twilio ON transcriptions(twilio_message_sid);
+CREATE INDEX idx_transcriptions_created ON transcriptions(created_at); -- retention purge
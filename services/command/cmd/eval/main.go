@@ -0,0 +1,168 @@
+// Command eval scores the command parser against the golden SMS dataset.
+//
+//	eval run   -provider openai -out runs/base.json   Parse every case live and record the responses
+//	eval score -run runs/base.json                    Score a recorded run again
+//	eval diff  -base runs/base.json -head runs/new.json -threshold 0.02
+//
+// diff exits with status 1 when the head run's exact match rate drops more
+// than the threshold below the base run's.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/eval"
+)
+
+const defaultDataset = "internal/eval/testdata/golden.json"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = runCmd(os.Args[2:])
+	case "score":
+		err = scoreCmd(os.Args[2:])
+	case "diff":
+		err = diffCmd(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  eval run   -provider openai -model gpt-5-mini -out runs/base.json")
+	fmt.Println("  eval score -run runs/base.json")
+	fmt.Println("  eval diff  -base runs/base.json -head runs/new.json -threshold 0.02")
+	fmt.Println("")
+	fmt.Println("Run from services/command, or pass -dataset. API keys are read from")
+	fmt.Println("OPENAI_API_KEY and ANTHROPIC_API_KEY.")
+}
+
+func runCmd(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	dataset := fs.String("dataset", defaultDataset, "Golden dataset file")
+	provider := fs.String("provider", getEnvOrDefault("AI_PROVIDER", ai.ProviderOpenAI), "openai, openai_compatible, anthropic or fake")
+	model := fs.String("model", "", "Model override, the provider's default if empty")
+	baseURL := fs.String("base-url", os.Getenv("AI_BASE_URL"), "Server URL for openai_compatible")
+	out := fs.String("out", "", "File to record the responses to")
+	fs.Parse(args)
+
+	ds, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		return err
+	}
+
+	opts := ai.Options{Provider: *provider, BaseURL: *baseURL, Model: *model, APIKey: os.Getenv("OPENAI_API_KEY")}
+	if *provider == ai.ProviderAnthropic {
+		opts.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	parser, err := ai.New(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	run, err := eval.Record(ctx, parser, ds, *provider, *model)
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		if err := run.Save(*out); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded %d responses to %s\n\n", len(run.Responses), *out)
+	}
+
+	report, err := eval.Score(ds, run)
+	if err != nil {
+		return err
+	}
+	report.Write(os.Stdout)
+	return nil
+}
+
+func scoreCmd(args []string) error {
+	fs := flag.NewFlagSet("score", flag.ExitOnError)
+	dataset := fs.String("dataset", defaultDataset, "Golden dataset file")
+	runFile := fs.String("run", "", "Recorded run to score")
+	fs.Parse(args)
+
+	if *runFile == "" {
+		return fmt.Errorf("-run is required")
+	}
+	report, err := scoreFile(*dataset, *runFile)
+	if err != nil {
+		return err
+	}
+	report.Write(os.Stdout)
+	return nil
+}
+
+func diffCmd(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	dataset := fs.String("dataset", defaultDataset, "Golden dataset file")
+	baseFile := fs.String("base", "", "Recorded run of the current prompt or model")
+	headFile := fs.String("head", "", "Recorded run of the candidate")
+	threshold := fs.Float64("threshold", 0.02, "Largest allowed drop in exact match rate, 0 to 1")
+	fs.Parse(args)
+
+	if *baseFile == "" || *headFile == "" {
+		return fmt.Errorf("-base and -head are required")
+	}
+	base, err := scoreFile(*dataset, *baseFile)
+	if err != nil {
+		return err
+	}
+	head, err := scoreFile(*dataset, *headFile)
+	if err != nil {
+		return err
+	}
+
+	cmp, err := eval.Compare(base, head, *threshold)
+	if err != nil {
+		return err
+	}
+	cmp.Write(os.Stdout)
+	if !cmp.Pass {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func scoreFile(datasetPath, runPath string) (*eval.Report, error) {
+	ds, err := eval.LoadDataset(datasetPath)
+	if err != nil {
+		return nil, err
+	}
+	run, err := eval.LoadRun(runPath)
+	if err != nil {
+		return nil, err
+	}
+	return eval.Score(ds, run)
+}
+
+func getEnvOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
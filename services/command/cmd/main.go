@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"hound-todo/services/command/internal/agent"
 	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/budget"
+	"hound-todo/services/command/internal/cache"
 	"hound-todo/services/command/internal/config"
 	"hound-todo/services/command/internal/consumer"
 	"hound-todo/services/command/internal/domain"
+	"hound-todo/services/command/internal/exports"
 	"hound-todo/services/command/internal/handler"
 	"hound-todo/services/command/internal/publisher"
 	"hound-todo/shared/logging"
@@ -25,12 +32,39 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create OpenAI client
-	aiClient := ai.NewClient(cfg.OpenAIAPIKey)
-	if cfg.OpenAIModel != "" {
-		aiClient.SetModel(cfg.OpenAIModel)
-		logger.Info("Using OpenAI model: %s", cfg.OpenAIModel)
+	// Create the LLM command parser for the configured provider
+	parserOpts := ai.Options{
+		Provider:         cfg.AIProvider,
+		BaseURL:          cfg.AIBaseURL,
+		MaxAttempts:      cfg.AIMaxAttempts,
+		BreakerThreshold: cfg.AIBreakerThreshold,
+		BreakerCooldown:  cfg.AIBreakerCooldown,
+		OnRetry: func(attempt, maxAttempts int, wait time.Duration, err error) {
+			logger.Info("LLM call failed (attempt %d/%d), retrying in %s: %v", attempt, maxAttempts, wait, err)
+		},
+		OnBreakerChange: func(open bool, failures int) {
+			if open {
+				logger.Error("LLM circuit breaker opened after %d failures", failures)
+			} else {
+				logger.Info("LLM circuit breaker closed")
+			}
+		},
 	}
+	switch cfg.AIProvider {
+	case ai.ProviderAnthropic:
+		parserOpts.APIKey = cfg.AnthropicAPIKey
+		parserOpts.Model = cfg.AnthropicModel
+	default:
+		parserOpts.APIKey = cfg.OpenAIAPIKey
+		parserOpts.Model = cfg.OpenAIModel
+	}
+	aiParser, err := ai.New(parserOpts)
+	if err != nil {
+		logger.Error("Failed to create AI parser: %v", err)
+		os.Exit(1)
+	}
+	model := cfg.Model()
+	logger.Info("Using AI provider: %s (model: %s)", cfg.AIProvider, model)
 
 	// Connect to todo-domain-svc via gRPC
 	domainClient, err := domain.NewClient(cfg.TodoDomainAddr)
@@ -59,7 +93,51 @@ func main() {
 	defer pub.Close()
 
 	// Create command handler
-	h := handler.New(aiClient, domainClient, pub, logger)
+	h := handler.New(aiParser, domainClient, pub, logger)
+	exportStore := exports.New(cfg.ExportDir, cfg.ExportBaseURL, []byte(cfg.ExportSecret), cfg.ExportTTL)
+	h.SetExports(exportStore)
+	if err := h.SetConfirmThresholds(cfg.ConfirmThresholds); err != nil {
+		logger.Error("Invalid CONFIRM_THRESHOLDS: %v", err)
+		os.Exit(1)
+	}
+	logger.Info("Confirming actions below: %s", h.ConfirmPolicy())
+	h.SetTimezones(cfg.Timezone, cfg.UserTimezones)
+	h.SetCategories(cfg.Categories)
+	logger.Info("Sorting todos into: %s", strings.Join(cfg.Categories.Names(), ", "))
+
+	// Every message's LLM cost is recorded; budgets apply when configured
+	h.SetBudget(budget.New(domainClient, cfg.LLMPrices, cfg.LLMBudget))
+	logger.Info("LLM budget per user: %s a day, %s a month",
+		formatLimit(cfg.LLMBudget.Daily), formatLimit(cfg.LLMBudget.Monthly))
+
+	// Repeated context-free messages like "what's on my list?" skip the LLM
+	if cfg.AICache {
+		h.SetCache(cache.New(model, cfg.AICacheSize, cfg.AICacheTTL))
+		logger.Info("LLM cache enabled: %d entries for %s (prompt %s)",
+			orDefaultInt(cfg.AICacheSize, cache.DefaultSize), orDefaultDuration(cfg.AICacheTTL, cache.DefaultTTL), ai.PromptVersion())
+	}
+
+	// "buy milk" twice is asked about, or dropped if it came minutes apart
+	if cfg.DuplicateCheck {
+		h.SetDuplicateCheck(cfg.DuplicateThreshold, cfg.DuplicateMergeWindow)
+		logger.Info("Duplicate check enabled: asking at %.2f similarity, merging within %s",
+			orDefaultFloat(cfg.DuplicateThreshold, handler.DefaultDuplicateThreshold),
+			orDefaultDuration(cfg.DuplicateMergeWindow, handler.DefaultMergeWindow))
+	}
+
+	// In agent mode the model calls todo operations itself
+	if cfg.AIMode == "agent" {
+		caller, ok := aiParser.(ai.ToolCaller)
+		if !ok {
+			logger.Error("AI_PROVIDER %s does not support AI_MODE=agent", cfg.AIProvider)
+			os.Exit(1)
+		}
+		a := agent.New(caller, domainClient, logger)
+		a.SetMaxSteps(cfg.AgentMaxSteps)
+		a.SetCategories(cfg.Categories)
+		h.SetAgent(a)
+		logger.Info("Using agent mode (max %d steps)", orDefaultInt(cfg.AgentMaxSteps, agent.DefaultMaxSteps))
+	}
 
 	// Set up graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -71,6 +149,25 @@ func main() {
 		cancel()
 	}()
 
+	// Serve data exports at the links sent to users, deleting expired ones hourly
+	mux := http.NewServeMux()
+	mux.Handle("/exports/", exportStore)
+	exportServer := &http.Server{
+		Addr:         ":" + cfg.ExportHTTPPort,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	go func() {
+		logger.Info("Serving exports on port %s for %s at %s", cfg.ExportHTTPPort,
+			orDefaultDuration(cfg.ExportTTL, exports.DefaultTTL), cfg.ExportBaseURL)
+		if err := exportServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Export server error: %v", err)
+		}
+	}()
+	go purgeExports(ctx, exportStore, logger)
+
 	logger.Info("Starting command-svc, waiting for messages...")
 
 	// Start consuming messages
@@ -79,5 +176,62 @@ func main() {
 		os.Exit(1)
 	}
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := exportServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Export server forced to shutdown: %v", err)
+	}
+
+	logger.Info("Rule parser: %s", h.RuleStats())
+	logger.Info("LLM cache: %s", h.CacheStats())
 	logger.Info("Command-svc stopped")
 }
+
+func orDefaultInt(val, defaultVal int) int {
+	if val == 0 {
+		return defaultVal
+	}
+	return val
+}
+
+func orDefaultFloat(val, defaultVal float64) float64 {
+	if val == 0 {
+		return defaultVal
+	}
+	return val
+}
+
+func orDefaultDuration(val, defaultVal time.Duration) time.Duration {
+	if val == 0 {
+		return defaultVal
+	}
+	return val
+}
+
+// purgeExports deletes expired exports every hour until ctx is done
+func purgeExports(ctx context.Context, s *exports.Store, logger *logging.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.Purge(); err != nil {
+			logger.Error("Failed to purge expired exports: %v", err)
+		} else if n > 0 {
+			logger.Info("Purged %d expired exports", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatLimit formats a budget in millionths of a dollar, 0 meaning none
+func formatLimit(micros int64) string {
+	if micros == 0 {
+		return "unlimited"
+	}
+	return budget.FormatDollars(micros)
+}
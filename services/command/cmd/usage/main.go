@@ -0,0 +1,81 @@
+// Command usage reports what LLM calls have cost, per user.
+//
+//	usage                                      Every user, this month so far
+//	usage -user +15551234567 -by-day           One user, a row per day and model
+//	usage -since 2026-01-01 -until 2026-02-01  A fixed period, until exclusive
+//
+// Dates are midnight in -timezone. The todo-domain address is read from
+// TODO_DOMAIN_GRPC_ADDR.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"hound-todo/services/command/internal/budget"
+	"hound-todo/services/command/internal/domain"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	addr := flag.String("addr", getEnvOrDefault("TODO_DOMAIN_GRPC_ADDR", "localhost:50051"), "todo-domain gRPC address")
+	user := flag.String("user", "", "Only this user, all users if empty")
+	since := flag.String("since", "", "First day to include as YYYY-MM-DD, the start of this month if empty")
+	until := flag.String("until", "", "Day to stop before as YYYY-MM-DD, now if empty")
+	timezone := flag.String("timezone", getEnvOrDefault("DEFAULT_TIMEZONE", "UTC"), "Timezone for day boundaries")
+	byDay := flag.Bool("by-day", false, "Show a row per day and model")
+	flag.Parse()
+
+	loc, err := time.LoadLocation(*timezone)
+	if err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+
+	now := time.Now().In(loc)
+	filter := domain.LLMUsageFilter{UserID: *user, Timezone: loc.String()}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if *since != "" {
+		if start, err = time.ParseInLocation("2006-01-02", *since, loc); err != nil {
+			return fmt.Errorf("since: %w", err)
+		}
+	}
+	filter.Since = &start
+	if *until != "" {
+		end, err := time.ParseInLocation("2006-01-02", *until, loc)
+		if err != nil {
+			return fmt.Errorf("until: %w", err)
+		}
+		filter.Until = &end
+	}
+
+	client, err := domain.NewClient(*addr)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	usage, err := client.GetLLMUsage(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return budget.WriteReport(os.Stdout, usage, *byDay)
+}
+
+func getEnvOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
@@ -0,0 +1,203 @@
+// Package agent answers messages by letting the model call todo-domain
+// operations as tools, instead of mapping each message to one fixed command
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/category"
+	"hound-todo/shared/logging"
+)
+
+const (
+	// Model turns allowed per message before giving up
+	DefaultMaxSteps = 6
+
+	fallbackReply = "Sorry, I couldn't finish that. Try something simpler, like \"add buy milk\" or \"list\"."
+)
+
+// Request is one message for the agent to act on
+type Request struct {
+	UserID         string // Every tool call is scoped to this user
+	Message        string
+	IdempotencyKey string         // Mutations derive their keys from it, empty to skip
+	Now            time.Time      // Zero means the current time
+	Location       *time.Location // The user's timezone, nil means UTC
+	History        *ai.History    // Recent conversation, nil if there is none
+}
+
+// Call is one tool call made while answering a message
+type Call struct {
+	Name      string
+	Arguments string
+	Result    string
+	IsError   bool
+}
+
+// Result is the agent's answer and how it got there
+type Result struct {
+	Reply      string
+	Held       []string // Sent with the reply in place of changes the gate held back
+	HandedOff  bool     // The model asked for the message to be handled without tools; Reply is empty
+	Transcript []Call
+	Steps      int // Model turns taken
+}
+
+// Gate is asked before a tool changes a todo, with the change described as
+// the command the parser would have produced. reason is why the change must
+// be confirmed whatever its confidence, as from guard.Review, or "". A
+// non-empty message holds the change back and is sent to the user instead -
+// usually a question whose YES makes the change later.
+type Gate func(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command, reason string) (string, error)
+
+// Agent runs the tool-calling loop
+type Agent struct {
+	model      ai.ToolCaller
+	api        TodoAPI
+	gate       Gate
+	categories category.Categories
+	maxSteps   int
+	logger     *logging.Logger
+}
+
+// New creates an agent backed by a tool-calling model and the todo-domain API
+func New(model ai.ToolCaller, api TodoAPI, logger *logging.Logger) *Agent {
+	return &Agent{
+		model:      model,
+		api:        api,
+		categories: category.Defaults(),
+		maxSteps:   DefaultMaxSteps,
+		logger:     logger,
+	}
+}
+
+// SetMaxSteps overrides how many model turns a message may take
+func (a *Agent) SetMaxSteps(n int) {
+	if n > 0 {
+		a.maxSteps = n
+	}
+}
+
+// SetGate sets what changes are checked against before they are made.
+// Without one, every change the model asks for is made at once.
+func (a *Agent) SetGate(g Gate) {
+	a.gate = g
+}
+
+// SetCategories sets the categories new todos are sorted into
+func (a *Agent) SetCategories(c category.Categories) {
+	a.categories = c
+}
+
+// Run answers a message, calling tools until the model replies in plain text
+// or runs out of steps. Tool errors are shown to the model so it can recover;
+// only model errors are returned.
+func (a *Agent) Run(ctx context.Context, req *Request) (*Result, error) {
+	s := &session{api: a.api, gate: a.gate, categories: a.categories, userID: req.UserID, idempotencyKey: req.IdempotencyKey}
+	system := BuildSystemPrompt(req)
+	messages := []ai.Message{{Role: ai.RoleUser, Content: req.History.Render(req.Message)}}
+	specs := Specs()
+	result := &Result{}
+
+	defer func() { a.logTranscript(req.UserID, result) }()
+
+	for result.Steps < a.maxSteps {
+		result.Steps++
+
+		reply, err := a.model.NextStep(ctx, system, messages, specs)
+		if err != nil {
+			return result, err
+		}
+		messages = append(messages, *reply)
+
+		if len(reply.ToolCalls) == 0 {
+			result.Reply = strings.TrimSpace(reply.Content)
+			if result.Reply == "" {
+				result.Reply = fallbackReply
+			}
+			result.Held = s.held
+			return result, nil
+		}
+
+		results := ai.Message{Role: ai.RoleTool}
+		for _, call := range reply.ToolCalls {
+			if call.Name == handOffTool && !s.changed {
+				result.Transcript = append(result.Transcript, Call{Name: call.Name, Arguments: string(call.Arguments)})
+				result.HandedOff = true
+				return result, nil
+			}
+
+			var out string
+			var err error
+			if call.Name == handOffTool {
+				// Handling the whole message again would repeat what was changed
+				err = fmt.Errorf("too late to hand off after changing todos, tell the user what was done and to send the rest again")
+			} else {
+				out, err = s.call(ctx, call)
+			}
+			entry := Call{Name: call.Name, Arguments: string(call.Arguments), Result: out}
+			if err != nil {
+				entry.Result = "error: " + err.Error()
+				entry.IsError = true
+			}
+			result.Transcript = append(result.Transcript, entry)
+			results.Results = append(results.Results, ai.ToolResult{CallID: call.ID, Content: entry.Result, IsError: entry.IsError})
+		}
+		messages = append(messages, results)
+	}
+
+	result.Reply = fallbackReply
+	result.Held = s.held
+	return result, nil
+}
+
+// logTranscript logs every tool call made for a message
+func (a *Agent) logTranscript(userID string, result *Result) {
+	if a.logger == nil {
+		return
+	}
+	for i, call := range result.Transcript {
+		a.logger.Info("Agent tool call %d for %s: %s(%s) error=%v result=%s",
+			i+1, userID, call.Name, call.Arguments, call.IsError, call.Result)
+	}
+	a.logger.Info("Agent finished for %s in %d steps with %d tool calls (%d held, handed off: %t)",
+		userID, result.Steps, len(result.Transcript), len(result.Held), result.HandedOff)
+}
+
+// BuildSystemPrompt tells the model what it is, the time in the user's
+// timezone and the rules it acts under
+func BuildSystemPrompt(req *Request) string {
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	var b strings.Builder
+	b.WriteString(systemPrompt)
+	fmt.Fprintf(&b, "\nCurrent time: %s (%s)\n", now.Format(time.RFC3339), now.Format("Monday"))
+	fmt.Fprintf(&b, "User's timezone: %s\n", loc)
+	return b.String()
+}
+
+const systemPrompt = `You manage a user's todo list over SMS using the tools provided.
+
+## Rules
+
+1. Look todos up with list_todos or search_todos before changing them. Never guess an id.
+2. If a description matches several todos and it isn't clear which is meant, ask instead of acting.
+3. Only act on what the user asked for. Never delete more than the user clearly named.
+4. Once done, reply in plain text with a short SMS-sized confirmation of what changed, e.g. "Done: #4 buy milk". No markdown.
+5. If a tool returns an error, either fix the call or tell the user what went wrong.
+6. If a change comes back held, don't retry it. Its message_to_user is sent after your reply, so don't repeat it.
+7. If the user asks for something no tool does, call hand_off before any other tool.
+8. If the message isn't about todos, say briefly what you can help with.
+`
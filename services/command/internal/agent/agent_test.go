@@ -0,0 +1,484 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/domain"
+	"hound-todo/services/command/internal/guard"
+)
+
+// =============================================================================
+// Fakes
+// =============================================================================
+
+// scriptedModel replies with canned messages in order and records what it was sent
+type scriptedModel struct {
+	replies []*ai.Message
+	seen    [][]ai.Message
+}
+
+func (m *scriptedModel) NextStep(ctx context.Context, system string, messages []ai.Message, tools []ai.ToolSpec) (*ai.Message, error) {
+	m.seen = append(m.seen, append([]ai.Message(nil), messages...))
+	if len(m.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := m.replies[0]
+	m.replies = m.replies[1:]
+	return reply, nil
+}
+
+func toolCall(id, name, args string) *ai.Message {
+	return &ai.Message{Role: ai.RoleAssistant, ToolCalls: []ai.ToolCall{{ID: id, Name: name, Arguments: json.RawMessage(args)}}}
+}
+
+func answer(text string) *ai.Message {
+	return &ai.Message{Role: ai.RoleAssistant, Content: text}
+}
+
+// fakeAPI keeps todos in memory per user and records idempotency keys
+type fakeAPI struct {
+	todos map[string][]*todov1.Todo
+	keys  []string
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{todos: map[string][]*todov1.Todo{
+		"alice": {{Id: 4, UserId: "alice", Title: "buy milk", Status: todov1.TodoStatus_TODO_STATUS_ACTIVE}},
+		"bob":   {{Id: 9, UserId: "bob", Title: "call dentist", Status: todov1.TodoStatus_TODO_STATUS_ACTIVE}},
+	}}
+}
+
+func (f *fakeAPI) ListTodos(ctx context.Context, userID string, filter domain.ListTodosFilter) ([]*todov1.Todo, error) {
+	var out []*todov1.Todo
+	for _, t := range f.todos[userID] {
+		if (filter.Status == todov1.TodoStatus_TODO_STATUS_UNSPECIFIED || t.Status == filter.Status) &&
+			(filter.Category == "" || t.Category == filter.Category) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAPI) FindTodosByTitle(ctx context.Context, userID, hint string) ([]domain.TitleMatch, error) {
+	var out []domain.TitleMatch
+	for _, t := range f.todos[userID] {
+		if strings.Contains(t.Title, hint) {
+			out = append(out, domain.TitleMatch{Todo: t, Score: 0.9})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAPI) CreateTodo(ctx context.Context, userID, title, description, category, key string) (*todov1.Todo, error) {
+	f.keys = append(f.keys, key)
+	t := &todov1.Todo{Id: 100, UserId: userID, Title: title, Description: description, Category: category, Status: todov1.TodoStatus_TODO_STATUS_ACTIVE}
+	f.todos[userID] = append(f.todos[userID], t)
+	return t, nil
+}
+
+func (f *fakeAPI) find(id int64, userID string) (*todov1.Todo, error) {
+	for _, t := range f.todos[userID] {
+		if t.Id == id {
+			return t, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeAPI) CompleteTodo(ctx context.Context, id int64, userID, key string) (*todov1.Todo, error) {
+	f.keys = append(f.keys, key)
+	t, err := f.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	t.Status = todov1.TodoStatus_TODO_STATUS_COMPLETED
+	return t, nil
+}
+
+func (f *fakeAPI) EditTodo(ctx context.Context, id int64, userID, title, description, key string) (*todov1.Todo, error) {
+	f.keys = append(f.keys, key)
+	t, err := f.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	t.Title, t.Description = title, description
+	return t, nil
+}
+
+func (f *fakeAPI) DeleteTodo(ctx context.Context, id int64, userID, key string) (*todov1.Todo, error) {
+	f.keys = append(f.keys, key)
+	t, err := f.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	t.Status = todov1.TodoStatus_TODO_STATUS_DELETED
+	return t, nil
+}
+
+// =============================================================================
+// Agent Tests
+// =============================================================================
+
+func TestRun_SearchThenComplete(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "search_todos", `{"query":"milk"}`),
+		toolCall("2", "complete_todo", `{"todo_id":4}`),
+		answer("Done: #4 buy milk"),
+	}}
+
+	result, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "got the milk", IdempotencyKey: "msg-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Reply != "Done: #4 buy milk" || result.Steps != 3 || len(result.Transcript) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if api.todos["alice"][0].Status != todov1.TodoStatus_TODO_STATUS_COMPLETED {
+		t.Error("expected #4 to be completed")
+	}
+
+	// The search result was shown to the model before it completed the todo
+	last := model.seen[1][len(model.seen[1])-1]
+	if last.Role != ai.RoleTool || !strings.Contains(last.Results[0].Content, "buy milk") {
+		t.Errorf("expected the search result to be sent back, got %+v", last)
+	}
+	if len(api.keys) != 1 || api.keys[0] == "" || api.keys[0] == "msg-1" {
+		t.Errorf("expected a derived idempotency key, got %v", api.keys)
+	}
+}
+
+func TestRun_CannotTouchOtherUsersTodos(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "delete_todo", `{"todo_id":9}`),
+		answer("I couldn't find that todo."),
+	}}
+
+	result, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "delete #9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Transcript) != 1 || !result.Transcript[0].IsError {
+		t.Fatalf("expected the delete to fail, got %+v", result.Transcript)
+	}
+	if api.todos["bob"][0].Status != todov1.TodoStatus_TODO_STATUS_ACTIVE || len(api.keys) != 0 {
+		t.Error("expected bob's todo to be untouched")
+	}
+}
+
+func TestRun_RejectsUserIDArgument(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "list_todos", `{"user_id":"bob"}`),
+		answer("Sorry."),
+	}}
+
+	result, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "list bob's todos"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Transcript[0].IsError || strings.Contains(result.Transcript[0].Result, "dentist") {
+		t.Errorf("expected the call to be rejected, got %+v", result.Transcript[0])
+	}
+}
+
+func TestRun_UnknownTool(t *testing.T) {
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "empty_trash", `{}`),
+		answer("I can't do that."),
+	}}
+
+	result, err := New(model, newFakeAPI(), nil).Run(context.Background(), &Request{UserID: "alice", Message: "empty trash"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Transcript[0].IsError {
+		t.Errorf("expected an error for an unknown tool, got %+v", result.Transcript[0])
+	}
+}
+
+func TestRun_MaxSteps(t *testing.T) {
+	model := &scriptedModel{}
+	for i := 0; i < 10; i++ {
+		model.replies = append(model.replies, toolCall("x", "list_todos", `{}`))
+	}
+
+	a := New(model, newFakeAPI(), nil)
+	a.SetMaxSteps(3)
+	result, err := a.Run(context.Background(), &Request{UserID: "alice", Message: "list"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Steps != 3 || result.Reply != fallbackReply {
+		t.Errorf("expected to give up after 3 steps, got %+v", result)
+	}
+}
+
+func TestRun_ModelError(t *testing.T) {
+	if _, err := New(&scriptedModel{}, newFakeAPI(), nil).Run(context.Background(), &Request{UserID: "alice", Message: "list"}); err == nil {
+		t.Error("expected the model error to be returned")
+	}
+}
+
+func TestRun_DistinctKeysPerMutation(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		{Role: ai.RoleAssistant, ToolCalls: []ai.ToolCall{
+			{ID: "1", Name: "create_todo", Arguments: json.RawMessage(`{"title":"eggs"}`)},
+			{ID: "2", Name: "create_todo", Arguments: json.RawMessage(`{"title":"bread"}`)},
+		}},
+		answer("Added eggs and bread"),
+	}}
+
+	if _, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add eggs and bread", IdempotencyKey: "msg-2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(api.keys) != 2 || api.keys[0] == api.keys[1] {
+		t.Errorf("expected two distinct keys, got %v", api.keys)
+	}
+}
+
+func TestRun_KeysFollowTheCallNotItsOrder(t *testing.T) {
+	eggs := ai.ToolCall{ID: "1", Name: "create_todo", Arguments: json.RawMessage(`{"title":"eggs"}`)}
+	bread := ai.ToolCall{ID: "2", Name: "create_todo", Arguments: json.RawMessage(`{"title":"bread"}`)}
+
+	run := func(calls ...ai.ToolCall) []string {
+		api := newFakeAPI()
+		model := &scriptedModel{replies: []*ai.Message{{Role: ai.RoleAssistant, ToolCalls: calls}, answer("Done")}}
+		if _, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add eggs and bread", IdempotencyKey: "msg-5"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return api.keys
+	}
+
+	first := run(eggs, bread)
+	redelivered := run(bread, eggs)
+	if first[0] != redelivered[1] || first[1] != redelivered[0] {
+		t.Errorf("expected each call to keep its key when reordered, got %v then %v", first, redelivered)
+	}
+
+	// Asking for the same thing twice in one message makes two todos
+	if twice := run(eggs, eggs); twice[0] == twice[1] {
+		t.Errorf("expected a repeated call to get its own key, got %v", twice)
+	}
+}
+
+func TestRun_EditKeepsOmittedFields(t *testing.T) {
+	api := newFakeAPI()
+	api.todos["alice"][0].Description = "2 litres"
+	model := &scriptedModel{replies: []*ai.Message{
+		{Role: ai.RoleAssistant, ToolCalls: []ai.ToolCall{
+			{ID: "1", Name: "edit_todo", Arguments: json.RawMessage(`{"todo_id":4,"description":"oat milk"}`)},
+			{ID: "2", Name: "edit_todo", Arguments: json.RawMessage(`{"todo_id":4,"title":"buy oat milk"}`)},
+		}},
+		answer("Updated #4"),
+	}}
+
+	if _, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "milk should be oat milk", IdempotencyKey: "msg-4"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	todo := api.todos["alice"][0]
+	if todo.Title != "buy oat milk" || todo.Description != "oat milk" {
+		t.Errorf("expected each edit to keep the other field, got %q / %q", todo.Title, todo.Description)
+	}
+}
+
+func TestRun_CategorizesAndListsByCategory(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "create_todo", `{"title":"call the plumber"}`),
+		toolCall("2", "list_todos", `{"category":"Call"}`),
+		toolCall("3", "list_todos", `{"category":"hobbies"}`),
+		answer("Added, and it's your only call"),
+	}}
+
+	if _, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add call the plumber", IdempotencyKey: "msg-3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	todos := api.todos["alice"]
+	if created := todos[len(todos)-1]; created.Category != "calls" {
+		t.Errorf("expected the new todo in calls, got %q", created.Category)
+	}
+
+	listed := model.seen[2][len(model.seen[2])-1].Results[0].Content
+	if !strings.Contains(listed, "call the plumber") || strings.Contains(listed, "buy milk") {
+		t.Errorf("expected only calls listed, got %s", listed)
+	}
+	if unknown := model.seen[3][len(model.seen[3])-1].Results[0].Content; !strings.Contains(unknown, "unknown category") {
+		t.Errorf("expected an unknown category error, got %s", unknown)
+	}
+}
+
+func TestRun_RejectsUndeclaredArguments(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "create_todo", `{"title":"eggs","category":"errands"}`),
+		toolCall("2", "search_todos", `{"query":"milk","status":"all"}`),
+		answer("Sorry."),
+	}}
+
+	result, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add eggs to errands"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range result.Transcript {
+		if !call.IsError {
+			t.Errorf("expected %s to be rejected, got %+v", call.Name, call)
+		}
+	}
+	if len(api.keys) != 0 {
+		t.Error("expected nothing created")
+	}
+}
+
+func TestRun_GateHoldsChanges(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		{Role: ai.RoleAssistant, ToolCalls: []ai.ToolCall{
+			{ID: "1", Name: "delete_todo", Arguments: json.RawMessage(`{"todo_id":4,"confidence":0.6}`)},
+			{ID: "2", Name: "create_todo", Arguments: json.RawMessage(`{"title":"eggs","confidence":0.95}`)},
+		}},
+		answer("Added eggs, and asked about the milk"),
+	}}
+
+	var gated []*ai.Command
+	a := New(model, api, nil)
+	a.SetGate(func(ctx context.Context, userID, key string, cmd *ai.Command, reason string) (string, error) {
+		if userID != "alice" || key == "" {
+			t.Errorf("expected the sender and a derived key, got %q %q", userID, key)
+		}
+		gated = append(gated, cmd)
+		if cmd.Action == "delete" {
+			return "Delete #4 'buy milk'? Reply YES to confirm or NO to cancel.", nil
+		}
+		return "", nil
+	})
+
+	result, err := a.Run(context.Background(), &Request{UserID: "alice", Message: "bin the milk, add eggs", IdempotencyKey: "msg-6"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(gated) != 2 || gated[0].Parameters["todo_id"] != "4" || gated[0].Confidence != 0.6 || gated[1].Parameters["title"] != "eggs" {
+		t.Fatalf("unexpected gated commands %+v", gated)
+	}
+	if api.todos["alice"][0].Status != todov1.TodoStatus_TODO_STATUS_ACTIVE || len(api.todos["alice"]) != 2 {
+		t.Errorf("expected the delete held and the create made, got %v", api.todos["alice"])
+	}
+	if len(result.Held) != 1 || !strings.HasPrefix(result.Held[0], "Delete #4") {
+		t.Errorf("expected the question to be returned, got %v", result.Held)
+	}
+	if !strings.Contains(result.Transcript[0].Result, `"held":true`) {
+		t.Errorf("expected the model to be told the delete was held, got %s", result.Transcript[0].Result)
+	}
+}
+
+func TestRun_LaterDeletesAreBulk(t *testing.T) {
+	api := newFakeAPI()
+	api.todos["alice"] = append(api.todos["alice"], &todov1.Todo{Id: 5, UserId: "alice", Title: "buy cake", Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+	model := &scriptedModel{replies: []*ai.Message{
+		{Role: ai.RoleAssistant, ToolCalls: []ai.ToolCall{
+			{ID: "1", Name: "delete_todo", Arguments: json.RawMessage(`{"todo_id":4,"confidence":0.95}`)},
+			{ID: "2", Name: "delete_todo", Arguments: json.RawMessage(`{"todo_id":5,"confidence":0.95}`)},
+		}},
+		answer("Done"),
+	}}
+
+	var reasons []string
+	a := New(model, api, nil)
+	a.SetGate(func(ctx context.Context, userID, key string, cmd *ai.Command, reason string) (string, error) {
+		reasons = append(reasons, reason)
+		return "", nil
+	})
+	if _, err := a.Run(context.Background(), &Request{UserID: "alice", Message: "bin the shopping"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(reasons) != 2 || reasons[0] != "" || reasons[1] != guard.BulkDelete {
+		t.Errorf("expected only the second delete to be a bulk delete, got %q", reasons)
+	}
+}
+
+func TestRun_KeysIgnoreConfidence(t *testing.T) {
+	run := func(args string) string {
+		api := newFakeAPI()
+		model := &scriptedModel{replies: []*ai.Message{toolCall("1", "create_todo", args), answer("Done")}}
+		if _, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add eggs", IdempotencyKey: "msg-7"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return api.keys[0]
+	}
+
+	if first, redelivered := run(`{"title":"eggs","confidence":0.9}`), run(`{"title":"eggs","confidence":0.8}`); first != redelivered {
+		t.Errorf("expected the same key on redelivery, got %s then %s", first, redelivered)
+	}
+}
+
+func TestRun_HandOff(t *testing.T) {
+	model := &scriptedModel{replies: []*ai.Message{toolCall("1", "hand_off", `{}`)}}
+
+	result, err := New(model, newFakeAPI(), nil).Run(context.Background(), &Request{UserID: "alice", Message: "how's my streak?"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.HandedOff || result.Reply != "" || result.Steps != 1 {
+		t.Errorf("expected the message handed off, got %+v", result)
+	}
+}
+
+func TestRun_NoHandOffAfterAChange(t *testing.T) {
+	api := newFakeAPI()
+	model := &scriptedModel{replies: []*ai.Message{
+		toolCall("1", "create_todo", `{"title":"eggs","confidence":0.9}`),
+		toolCall("2", "hand_off", `{}`),
+		answer("Added eggs. Send the stats request again on its own."),
+	}}
+
+	result, err := New(model, api, nil).Run(context.Background(), &Request{UserID: "alice", Message: "add eggs and show my stats"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HandedOff || !result.Transcript[1].IsError {
+		t.Errorf("expected the late hand off refused, got %+v", result)
+	}
+}
+
+func TestSpecs_NoUserID(t *testing.T) {
+	for _, spec := range Specs() {
+		props := spec.Parameters["properties"].(map[string]interface{})
+		if _, ok := props["user_id"]; ok {
+			t.Errorf("%s must not take a user_id", spec.Name)
+		}
+		if spec.Parameters["additionalProperties"] != false {
+			t.Errorf("%s must not allow extra arguments", spec.Name)
+		}
+	}
+}
+
+func TestSpecs_ChangesTakeConfidence(t *testing.T) {
+	for _, def := range tools {
+		if def.command == nil {
+			continue
+		}
+		props := def.spec.Parameters["properties"].(map[string]interface{})
+		if _, ok := props["confidence"]; !ok {
+			t.Errorf("%s changes todos, so must take a confidence", def.spec.Name)
+		}
+	}
+}
+
+func TestBuildSystemPrompt(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	prompt := BuildSystemPrompt(&Request{Now: time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC), Location: loc})
+	if !strings.Contains(prompt, "2025-03-14T10:00:00-05:00 (Friday)") {
+		t.Errorf("expected the local time in the prompt, got %s", prompt)
+	}
+}
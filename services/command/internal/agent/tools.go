@@ -0,0 +1,500 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/category"
+	"hound-todo/services/command/internal/domain"
+	"hound-todo/services/command/internal/guard"
+	"hound-todo/shared/idempotency"
+)
+
+// Most todos returned by one list or search call, to keep the prompt small
+const maxListed = 50
+
+// TodoAPI is the part of the todo-domain client the tools are backed by
+type TodoAPI interface {
+	ListTodos(ctx context.Context, userID string, filter domain.ListTodosFilter) ([]*todov1.Todo, error)
+	FindTodosByTitle(ctx context.Context, userID, titleHint string) ([]domain.TitleMatch, error)
+	CreateTodo(ctx context.Context, userID, title, description, category, idempotencyKey string) (*todov1.Todo, error)
+	CompleteTodo(ctx context.Context, todoID int64, userID, idempotencyKey string) (*todov1.Todo, error)
+	EditTodo(ctx context.Context, todoID int64, userID, title, description, idempotencyKey string) (*todov1.Todo, error)
+	DeleteTodo(ctx context.Context, todoID int64, userID, idempotencyKey string) (*todov1.Todo, error)
+}
+
+// errForbidden is returned to the model when a call reaches outside the sender's own todos
+var errForbidden = errors.New("not allowed")
+
+// toolFunc runs a tool for the session's user with arguments already checked against its schema
+type toolFunc func(ctx context.Context, s *session, args toolArgs) (interface{}, error)
+
+// commandFunc describes a tool's change as the command the parser would
+// have produced for it, so the gate can judge it like one
+type commandFunc func(args toolArgs) *ai.Command
+
+// toolDef is a tool the model can call
+type toolDef struct {
+	spec    ai.ToolSpec
+	owned   bool        // Takes a todo_id that must belong to the sender
+	command commandFunc // Set for tools that change todos, which pass the gate first
+	run     toolFunc
+}
+
+// toolArgs is the union of every tool's arguments. Keys a tool's schema
+// doesn't declare - a user_id in particular - are rejected before decoding.
+type toolArgs struct {
+	Status      string  `json:"status"`
+	Query       string  `json:"query"`
+	TodoID      int64   `json:"todo_id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Confidence  float64 `json:"confidence"`
+
+	todo *todov1.Todo // The sender's todo named by todo_id, set by authorize for owned tools
+	key  string       // Idempotency key for the call, see keyFor
+}
+
+// todoView is a todo as shown to the model
+type todoView struct {
+	ID          int64   `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Status      string  `json:"status"`
+	Category    string  `json:"category,omitempty"`
+	CreatedAt   string  `json:"created_at,omitempty"`
+	CompletedAt string  `json:"completed_at,omitempty"`
+	Score       float64 `json:"match_score,omitempty"` // search_todos only
+}
+
+func viewTodo(t *todov1.Todo) todoView {
+	v := todoView{ID: t.Id, Title: t.Title, Description: t.Description, Category: t.Category}
+	switch t.Status {
+	case todov1.TodoStatus_TODO_STATUS_COMPLETED:
+		v.Status = "completed"
+	case todov1.TodoStatus_TODO_STATUS_DELETED:
+		v.Status = "deleted"
+	default:
+		v.Status = "active"
+	}
+	if t.CreatedAt != nil {
+		v.CreatedAt = t.CreatedAt.AsTime().Format(time.RFC3339)
+	}
+	if t.CompletedAt != nil {
+		v.CompletedAt = t.CompletedAt.AsTime().Format(time.RFC3339)
+	}
+	return v
+}
+
+// schema builds an object schema that allows no properties beyond those given
+func schema(required []string, properties map[string]interface{}) map[string]interface{} {
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+var (
+	todoIDProperty     = map[string]interface{}{"type": "integer", "description": "The todo's id"}
+	confidenceProperty = map[string]interface{}{"type": "number", "description": "How sure you are, from 0 to 1, that the user asked for exactly this change"}
+
+	tools = []toolDef{
+		{
+			spec: ai.ToolSpec{
+				Name:        "list_todos",
+				Description: "List the user's todos, newest first.",
+				Parameters: schema(nil, map[string]interface{}{
+					"status":   map[string]interface{}{"type": "string", "enum": []string{"active", "completed", "all"}, "description": "Which todos to list, active if omitted"},
+					"category": map[string]interface{}{"type": "string", "description": "Only todos of this kind, like errands, calls, home, work or health"},
+				}),
+			},
+			run: listTodos,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name:        "search_todos",
+				Description: "Find the user's active todos whose titles match a description, best match first. Tolerates typos.",
+				Parameters: schema([]string{"query"}, map[string]interface{}{
+					"query": map[string]interface{}{"type": "string", "description": "Words from the todo's title"},
+				}),
+			},
+			run: searchTodos,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name:        "create_todo",
+				Description: "Add a todo to the user's list.",
+				Parameters: schema([]string{"title", "confidence"}, map[string]interface{}{
+					"title":       map[string]interface{}{"type": "string"},
+					"description": map[string]interface{}{"type": "string"},
+					"confidence":  confidenceProperty,
+				}),
+			},
+			command: func(args toolArgs) *ai.Command {
+				return change("create", args, "title", args.Title, "description", args.Description)
+			},
+			run: createTodo,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name:        "complete_todo",
+				Description: "Mark one of the user's active todos as done.",
+				Parameters: schema([]string{"todo_id", "confidence"}, map[string]interface{}{
+					"todo_id":    todoIDProperty,
+					"confidence": confidenceProperty,
+				}),
+			},
+			owned: true,
+			command: func(args toolArgs) *ai.Command {
+				return change("complete", args)
+			},
+			run: completeTodo,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name:        "edit_todo",
+				Description: "Change the title and/or description of one of the user's active todos. Omitted fields are kept.",
+				Parameters: schema([]string{"todo_id", "confidence"}, map[string]interface{}{
+					"todo_id":     todoIDProperty,
+					"title":       map[string]interface{}{"type": "string"},
+					"description": map[string]interface{}{"type": "string"},
+					"confidence":  confidenceProperty,
+				}),
+			},
+			owned: true,
+			command: func(args toolArgs) *ai.Command {
+				return change("edit", args, "new_title", args.Title, "new_description", args.Description)
+			},
+			run: editTodo,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name:        "delete_todo",
+				Description: "Move one of the user's active todos to the trash, from which it can be restored.",
+				Parameters: schema([]string{"todo_id", "confidence"}, map[string]interface{}{
+					"todo_id":    todoIDProperty,
+					"confidence": confidenceProperty,
+				}),
+			},
+			owned: true,
+			command: func(args toolArgs) *ai.Command {
+				return change("delete", args)
+			},
+			run: deleteTodo,
+		},
+		{
+			spec: ai.ToolSpec{
+				Name: handOffTool,
+				Description: "Pass the message on to be handled without tools. Call it first, before any other tool, " +
+					"when the user asks for anything the other tools can't do: completion stats or streaks, exporting " +
+					"their data, deleting their account, the trash (showing, restoring or emptying it), a nudge or " +
+					"suggestion of what to do next, or breaking a todo down into steps.",
+				Parameters: schema(nil, map[string]interface{}{}),
+			},
+		},
+	}
+)
+
+// handOffTool ends the run without a reply, see Result.HandedOff
+const handOffTool = "hand_off"
+
+// change builds the command equivalent to a tool call. Pairs of parameter
+// names and values are set when the value isn't empty.
+func change(action string, args toolArgs, params ...string) *ai.Command {
+	cmd := &ai.Command{Action: action, Parameters: map[string]string{}, Confidence: args.Confidence}
+	if args.TodoID > 0 {
+		cmd.Parameters["todo_id"] = strconv.FormatInt(args.TodoID, 10)
+	}
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] != "" {
+			cmd.Parameters[params[i]] = params[i+1]
+		}
+	}
+	return cmd
+}
+
+// Specs returns the tools offered to the model
+func Specs() []ai.ToolSpec {
+	specs := make([]ai.ToolSpec, len(tools))
+	for i, t := range tools {
+		specs[i] = t.spec
+	}
+	return specs
+}
+
+func findTool(name string) (toolDef, bool) {
+	for _, t := range tools {
+		if t.spec.Name == name {
+			return t, true
+		}
+	}
+	return toolDef{}, false
+}
+
+// session runs tools for one message. Every call is scoped to the sender:
+// the user id comes from the message, never from the model.
+type session struct {
+	api            TodoAPI
+	gate           Gate
+	categories     category.Categories
+	userID         string
+	idempotencyKey string
+	calls          map[string]int // How often each call has been made, see keyFor
+	changed        bool           // A tool has changed a todo, or asked to
+	deletes        int            // delete_todo calls made so far
+	held           []string       // What the gate sent in place of held changes
+}
+
+// heldResult is shown to the model in place of a change the gate held back
+type heldResult struct {
+	Held    bool   `json:"held"`
+	Message string `json:"message_to_user"`
+}
+
+// call decodes the arguments, authorizes the call and runs the tool
+func (s *session) call(ctx context.Context, call ai.ToolCall) (string, error) {
+	def, ok := findTool(call.Name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", call.Name)
+	}
+
+	args, err := decodeArgs(def.spec, call.Arguments)
+	if err != nil {
+		return "", err
+	}
+
+	if def.owned {
+		todo, err := s.authorize(ctx, args.TodoID)
+		if err != nil {
+			return "", err
+		}
+		args.todo = todo
+	}
+	args.key = s.keyFor(call.Name, args)
+
+	var out interface{}
+	if def.command != nil {
+		s.changed = true
+		out, err = s.change(ctx, def, args)
+	} else {
+		out, err = def.run(ctx, s, args)
+	}
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// change runs a tool that changes a todo, unless the gate holds it back.
+// A message asking for more than one delete is a bulk delete, as guard.Review
+// has it, so every delete after the first is confirmed.
+func (s *session) change(ctx context.Context, def toolDef, args toolArgs) (interface{}, error) {
+	cmd := def.command(args)
+	reason := ""
+	if cmd.Action == "delete" {
+		s.deletes++
+		if s.deletes > 1 {
+			reason = guard.BulkDelete
+		}
+	}
+
+	if s.gate != nil {
+		message, err := s.gate(ctx, s.userID, args.key, cmd, reason)
+		if err != nil {
+			return nil, err
+		}
+		if message != "" {
+			s.held = append(s.held, message)
+			return heldResult{Held: true, Message: message}, nil
+		}
+	}
+	return def.run(ctx, s, args)
+}
+
+// decodeArgs decodes a call's arguments, rejecting keys its schema doesn't declare
+func decodeArgs(spec ai.ToolSpec, raw json.RawMessage) (toolArgs, error) {
+	var args toolArgs
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return args, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return args, fmt.Errorf("invalid arguments: %w", err)
+	}
+	declared, _ := spec.Parameters["properties"].(map[string]interface{})
+	for name := range fields {
+		if _, ok := declared[name]; !ok {
+			return args, fmt.Errorf("invalid arguments: %s takes no %q", spec.Name, name)
+		}
+	}
+
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return args, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return args, nil
+}
+
+// authorize checks that a todo is one of the sender's active todos and returns it
+func (s *session) authorize(ctx context.Context, todoID int64) (*todov1.Todo, error) {
+	if todoID <= 0 {
+		return nil, fmt.Errorf("todo_id is required")
+	}
+	todos, err := s.api.ListTodos(ctx, s.userID, domain.ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		if todo.Id == todoID {
+			return todo, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: todo #%d is not one of this user's active todos", errForbidden, todoID)
+}
+
+// keyFor derives a call's idempotency key from the tool and its arguments,
+// so a redelivered message that makes the same calls in another order still
+// doesn't repeat their work. Repeats of one call in a message are numbered.
+func (s *session) keyFor(name string, args toolArgs) string {
+	if s.idempotencyKey == "" {
+		return ""
+	}
+	// How sure the model says it is may differ on redelivery; the call doesn't
+	args.Confidence = 0
+	encoded, _ := json.Marshal(args)
+	call := s.idempotencyKey + "|" + name + string(encoded)
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	n := s.calls[call]
+	s.calls[call]++
+	return idempotency.DeriveKey(call, n)
+}
+
+func listTodos(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	filter := domain.ListTodosFilter{}
+	switch args.Status {
+	case "", "active":
+		filter.Status = todov1.TodoStatus_TODO_STATUS_ACTIVE
+	case "completed":
+		filter.Status = todov1.TodoStatus_TODO_STATUS_COMPLETED
+	case "all":
+		filter.Status = todov1.TodoStatus_TODO_STATUS_UNSPECIFIED
+	default:
+		return nil, fmt.Errorf("unknown status %q", args.Status)
+	}
+
+	if args.Category != "" {
+		name, ok := s.categories.Lookup(args.Category)
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q, expected one of %s", args.Category, strings.Join(s.categories.Names(), ", "))
+		}
+		filter.Category = name
+	}
+
+	todos, err := s.api.ListTodos(ctx, s.userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := struct {
+		Todos []todoView `json:"todos"`
+		More  int        `json:"more_not_shown,omitempty"`
+	}{Todos: []todoView{}}
+	for i, todo := range todos {
+		if i == maxListed {
+			result.More = len(todos) - maxListed
+			break
+		}
+		result.Todos = append(result.Todos, viewTodo(todo))
+	}
+	return result, nil
+}
+
+func searchTodos(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	matches, err := s.api.FindTodosByTitle(ctx, s.userID, args.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []todoView{}
+	for i, m := range matches {
+		if i == maxListed {
+			break
+		}
+		v := viewTodo(m.Todo)
+		v.Score = m.Score
+		views = append(views, v)
+	}
+	return struct {
+		Todos []todoView `json:"todos"`
+	}{views}, nil
+}
+
+func createTodo(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	if strings.TrimSpace(args.Title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	todo, err := s.api.CreateTodo(ctx, s.userID, args.Title, args.Description, s.categories.Classify(args.Title), args.key)
+	if err != nil {
+		return nil, err
+	}
+	return viewTodo(todo), nil
+}
+
+func completeTodo(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	todo, err := s.api.CompleteTodo(ctx, args.TodoID, s.userID, args.key)
+	if err != nil {
+		return nil, err
+	}
+	return viewTodo(todo), nil
+}
+
+func editTodo(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	if args.Title == "" && args.Description == "" {
+		return nil, fmt.Errorf("give a new title or description")
+	}
+	// The domain sets both fields, so an omitted one is sent as it is now
+	title, description := args.Title, args.Description
+	if title == "" {
+		title = args.todo.Title
+	}
+	if description == "" {
+		description = args.todo.Description
+	}
+	todo, err := s.api.EditTodo(ctx, args.TodoID, s.userID, title, description, args.key)
+	if err != nil {
+		return nil, err
+	}
+	return viewTodo(todo), nil
+}
+
+func deleteTodo(ctx context.Context, s *session, args toolArgs) (interface{}, error) {
+	todo, err := s.api.DeleteTodo(ctx, args.TodoID, s.userID, args.key)
+	if err != nil {
+		return nil, err
+	}
+	return viewTodo(todo), nil
+}
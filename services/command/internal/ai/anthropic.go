@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	anthropicURL          = "https://api.anthropic.com/v1/messages"
+	anthropicVersion      = "2023-06-01"
+	defaultAnthropicModel = "claude-haiku-4-5" // Fast and cheap for simple parsing tasks
+)
+
+// AnthropicClient handles communication with Anthropic's Messages API
+type AnthropicClient struct {
+	transport
+	url    string
+	apiKey string
+	model  string
+}
+
+// NewAnthropicClient creates a new Anthropic client
+func NewAnthropicClient(apiKey string) *AnthropicClient {
+	return &AnthropicClient{
+		transport: newTransport("Anthropic"),
+		url:       anthropicURL,
+		apiKey:    apiKey,
+		model:     defaultAnthropicModel,
+	}
+}
+
+// SetModel allows overriding the default model
+func (c *AnthropicClient) SetModel(model string) {
+	c.model = model
+}
+
+const commandToolName = "record_command"
+
+// messagesRequest is the Anthropic Messages API request format
+type messagesRequest struct {
+	Model      string             `json:"model"`
+	MaxTokens  int                `json:"max_tokens"`
+	System     string             `json:"system"`
+	Messages   []anthropicMessage `json:"messages"`
+	Tools      []tool             `json:"tools,omitempty"`
+	ToolChoice *toolChoice        `json:"tool_choice,omitempty"`
+}
+
+// anthropicMessage holds either a plain string or a list of content blocks
+type anthropicMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// tool lets the reply be constrained to a JSON schema by forcing a tool call
+type tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// messagesResponse is the Anthropic Messages API response format
+type messagesResponse struct {
+	ID      string `json:"id"`
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		ID    string          `json:"id"`    // Set on tool_use blocks
+		Name  string          `json:"name"`  // Set on tool_use blocks
+		Input json.RawMessage `json:"input"` // Set on tool_use blocks
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ParseCommands sends a user message and its context to Claude and parses the response into commands
+func (c *AnthropicClient) ParseCommands(ctx context.Context, req *Request) ([]*Command, error) {
+	return parseWithRepair(ctx, c.complete, req)
+}
+
+// complete sends the conversation and returns the command the model recorded as JSON
+func (c *AnthropicClient) complete(ctx context.Context, system string, messages []chatMessage) (string, error) {
+	reqBody := messagesRequest{
+		Model:     c.model,
+		MaxTokens: 2000,
+		System:    system,
+		Tools: []tool{{
+			Name:        commandToolName,
+			Description: "Record the command the user's message maps to",
+			InputSchema: ResponseSchema(),
+		}},
+		ToolChoice: &toolChoice{Type: "tool", Name: commandToolName},
+	}
+	for _, m := range messages {
+		reqBody.Messages = append(reqBody.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var msgResp messagesResponse
+	if err := c.post(ctx, reqBody, &msgResp); err != nil {
+		return "", err
+	}
+
+	if msgResp.Error != nil {
+		return "", fmt.Errorf("Anthropic API error: %s", msgResp.Error.Message)
+	}
+
+	addUsage(ctx, c.model, msgResp.Usage.InputTokens, msgResp.Usage.OutputTokens)
+
+	// The forced tool call carries the command; fall back to text for models without tool use
+	var content strings.Builder
+	for _, block := range msgResp.Content {
+		if block.Type == "tool_use" {
+			content.Reset()
+			content.Write(block.Input)
+			break
+		}
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no response from Anthropic")
+	}
+
+	return content.String(), nil
+}
+
+// post sends a request body to the Messages API and decodes the reply into out
+func (c *AnthropicClient) post(ctx context.Context, reqBody, out interface{}) error {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+	return c.postJSON(ctx, c.url, headers, jsonBody, out)
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	fakeIDPattern       = regexp.MustCompile(`#?(\d+)`)
+	fakeFillerPrefix    = regexp.MustCompile(`(?i)^(?:remind me to|add|don't let me forget to|i need to)\s+`)
+	fakeCategoryPattern = regexp.MustCompile(`\b(errands|calls|home|work|health)\b`)
+)
+
+// Fake is a deterministic in-process Parser for offline runs and tests.
+// Messages registered with Set get their canned command; anything else is
+// classified with simple keyword rules.
+type Fake struct {
+	mu        sync.Mutex
+	responses map[string][]*Command
+	calls     []string
+}
+
+// NewFake creates a fake parser with no canned responses
+func NewFake() *Fake {
+	return &Fake{responses: make(map[string][]*Command)}
+}
+
+// Set registers the commands returned for a message, matched case-insensitively
+func (f *Fake) Set(userMessage string, cmds ...*Command) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[normalizeFakeMessage(userMessage)] = cmds
+}
+
+// Calls returns every message the fake has been asked to parse
+func (f *Fake) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// ParseCommands returns the canned commands for the message, or a keyword-based guess.
+// Everything in the request but the message is ignored.
+func (f *Fake) ParseCommands(ctx context.Context, req *Request) ([]*Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	userMessage := req.Message
+
+	f.mu.Lock()
+	f.calls = append(f.calls, userMessage)
+	canned, ok := f.responses[normalizeFakeMessage(userMessage)]
+	f.mu.Unlock()
+
+	if ok {
+		// Copy so callers can't change the canned response
+		cmds := make([]*Command, len(canned))
+		for i, c := range canned {
+			cmd := *c
+			cmd.Parameters = make(map[string]string, len(c.Parameters))
+			for k, v := range c.Parameters {
+				cmd.Parameters[k] = v
+			}
+			cmds[i] = &cmd
+		}
+		return cmds, nil
+	}
+
+	return []*Command{guessCommand(userMessage)}, nil
+}
+
+// guessCommand classifies a message by its first word, defaulting to create
+func guessCommand(userMessage string) *Command {
+	text := strings.TrimSpace(userMessage)
+	lower := strings.ToLower(text)
+	words := strings.Fields(lower)
+	if len(words) == 0 {
+		return fakeCommand("unclear", 0.5, "reason", "empty message")
+	}
+
+	target := func(action string) *Command {
+		if m := fakeIDPattern.FindStringSubmatch(text); m != nil {
+			return fakeCommand(action, 0.9, "todo_id", m[1])
+		}
+		hint := strings.TrimSpace(strings.TrimPrefix(lower, words[0]))
+		return fakeCommand(action, 0.8, "title_hint", hint)
+	}
+
+	switch words[0] {
+	case "list", "show":
+		filter := "active"
+		if strings.Contains(lower, "completed") || strings.Contains(lower, "done") {
+			filter = "completed"
+		}
+		cmd := fakeCommand("list", 0.9, "filter", filter)
+		if m := fakeCategoryPattern.FindStringSubmatch(lower); m != nil {
+			cmd.Parameters["category"] = m[1]
+		}
+		return cmd
+	case "done", "finished", "complete", "completed":
+		return target("complete")
+	case "delete", "remove":
+		return target("delete")
+	case "stats":
+		return fakeCommand("stats", 0.9, "", "")
+	case "break", "breakdown":
+		cmd := target("breakdown")
+		if hint := cmd.Parameters["title_hint"]; hint != "" {
+			cmd.Parameters["title_hint"] = strings.TrimPrefix(hint, "down ")
+		}
+		cmd.Parameters["steps"] = "Gather what you need\nDo the first part\nFinish up and put things away"
+		return cmd
+	case "nudge":
+		cmd := target("nudge")
+		cmd.Parameters["suggested_action"] = "Set a timer for two minutes and do the very first step"
+		return cmd
+	}
+
+	if m := fakeCategoryPattern.FindStringSubmatch(lower); m != nil && words[0] == "what" {
+		cmd := fakeCommand("list", 0.9, "filter", "active")
+		cmd.Parameters["category"] = m[1]
+		return cmd
+	}
+	if strings.Contains(lower, "can't choose") || strings.Contains(lower, "what should i do") {
+		// No task named, so the handler picks one
+		return fakeCommand("nudge", 0.8, "", "")
+	}
+	if strings.Contains(lower, "stuck") || strings.Contains(lower, "help me") {
+		cmd := fakeCommand("nudge", 0.8, "task_context", text)
+		cmd.Parameters["suggested_action"] = "Set a timer for two minutes and do the very first step"
+		return cmd
+	}
+
+	title := fakeFillerPrefix.ReplaceAllString(text, "")
+	return fakeCommand("create", 0.9, "title", title)
+}
+
+func fakeCommand(action string, confidence float64, key, value string) *Command {
+	params := map[string]string{}
+	if key != "" {
+		params[key] = value
+	}
+	return &Command{
+		Action:      action,
+		Parameters:  params,
+		Confidence:  confidence,
+		Explanation: "Fake parser",
+	}
+}
+
+func normalizeFakeMessage(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// How much of each earlier reply is shown to the model. Lists can be long and
+// only the start is needed to follow up on them.
+const maxHistoryReplyLen = 300
+
+// Turn is one message from the user and the reply they got
+type Turn struct {
+	Message string
+	Reply   string
+}
+
+// History is the recent conversation with a user, given to the parser so
+// follow-ups like "make that tomorrow" or "done with it" can be understood
+type History struct {
+	Turns   []Turn  // Oldest first
+	TodoIDs []int64 // Todos the conversation referred to, most recent first
+}
+
+// LastTodoID returns the todo the conversation referred to most recently
+func (h *History) LastTodoID() (int64, bool) {
+	if h == nil || len(h.TodoIDs) == 0 {
+		return 0, false
+	}
+	return h.TodoIDs[0], true
+}
+
+// smsTag matches the delimiters user text is wrapped in, however written
+var smsTag = regexp.MustCompile(`(?i)<\s*/?\s*sms\s*>`)
+
+// Delimit wraps text from the user in <sms> tags, which the prompt says hold
+// data and never instructions. Tags already in the text are removed, so a
+// message can't close its own block and carry on as if it were the prompt.
+func Delimit(text string) string {
+	return "<sms>" + smsTag.ReplaceAllString(text, "") + "</sms>"
+}
+
+// titleTag matches the delimiters todo titles are wrapped in, and the ones
+// around messages, however written
+var titleTag = regexp.MustCompile(`(?i)<\s*/?\s*(?:title|sms)\s*>`)
+
+// delimitTitle puts a todo title in <title> tags on one line. Titles are
+// written by the user, so they get the same treatment as messages: invisible
+// characters and tags are removed, and a line break can't start what looks
+// like a new line of the prompt.
+func delimitTitle(title string) string {
+	clean, _ := Sanitize(title)
+	clean = strings.ReplaceAll(clean, "\n", " ")
+	return "<title>" + titleTag.ReplaceAllString(clean, "") + "</title>"
+}
+
+// Sanitize removes control characters and invisible formatting such as
+// zero-width spaces and bidi overrides, which can hide text from a reader
+// but not from the model. Line breaks are kept, tabs become spaces, and runs
+// of spaces collapse. Returns the text and how many characters were removed.
+func Sanitize(text string) (string, int) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var b strings.Builder
+	stripped := 0
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			b.WriteRune(r)
+		case r == '\t' || r == '\r':
+			b.WriteRune(' ')
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			stripped++
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), stripped
+}
+
+// Render puts the conversation in front of the new message. Without any
+// history the message is sent on its own. Whatever the user wrote is
+// delimited, earlier messages included.
+func (h *History) Render(userMessage string) string {
+	if h == nil || (len(h.Turns) == 0 && len(h.TodoIDs) == 0) {
+		return Delimit(userMessage)
+	}
+
+	var b strings.Builder
+	if len(h.Turns) > 0 {
+		b.WriteString("Recent conversation, oldest first:\n")
+		for _, turn := range h.Turns {
+			fmt.Fprintf(&b, "User: %s\n", Delimit(turn.Message))
+			fmt.Fprintf(&b, "You replied: %s\n", truncate(turn.Reply, maxHistoryReplyLen))
+		}
+		b.WriteString("\n")
+	}
+	if len(h.TodoIDs) > 0 {
+		ids := make([]string, len(h.TodoIDs))
+		for i, id := range h.TodoIDs {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		fmt.Fprintf(&b, "Todos referred to recently, most recent first: %s\n\n", strings.Join(ids, ", "))
+	}
+	b.WriteString("New message:\n")
+	b.WriteString(Delimit(userMessage))
+
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	// Don't cut a UTF-8 sequence in half
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n] + "..."
+}
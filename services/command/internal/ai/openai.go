@@ -1,39 +1,45 @@
 package ai
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
 	"time"
 )
 
 const (
-	openAIURL     = "https://api.openai.com/v1/chat/completions"
-	defaultModel  = "gpt-5-mini" // Fast and cheap for simple parsing tasks
+	openAIURL      = "https://api.openai.com/v1/chat/completions"
+	defaultModel   = "gpt-5-mini" // Fast and cheap for simple parsing tasks
 	requestTimeout = 30 * time.Second
 )
 
-// Client handles communication with OpenAI API
+// Client handles communication with OpenAI API, or any server that speaks it
 type Client struct {
-	apiKey     string
-	model      string
-	httpClient *http.Client
+	transport
+	url    string
+	apiKey string
+	model  string
 }
 
 // NewClient creates a new OpenAI client
 func NewClient(apiKey string) *Client {
 	return &Client{
-		apiKey: apiKey,
-		model:  defaultModel,
-		httpClient: &http.Client{
-			Timeout: requestTimeout,
-		},
+		transport: newTransport("OpenAI"),
+		url:       openAIURL,
+		apiKey:    apiKey,
+		model:     defaultModel,
 	}
 }
 
+// NewCompatibleClient creates a client for an OpenAI-compatible server such as
+// llama.cpp or Ollama, e.g. "http://192.168.1.20:11434/v1". The API key may be empty.
+func NewCompatibleClient(baseURL, apiKey string) *Client {
+	c := NewClient(apiKey)
+	c.url = strings.TrimSuffix(baseURL, "/") + "/chat/completions"
+	return c
+}
+
 // SetModel allows overriding the default model
 func (c *Client) SetModel(model string) {
 	c.model = model
@@ -41,14 +47,29 @@ func (c *Client) SetModel(model string) {
 
 // chatRequest is the OpenAI API request format
 type chatRequest struct {
-	Model               string        `json:"model"`
-	Messages            []chatMessage `json:"messages"`
-	MaxCompletionTokens int           `json:"max_completion_tokens,omitempty"`
+	Model               string          `json:"model"`
+	Messages            []chatMessage   `json:"messages"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	ResponseFormat      *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat constrains the reply to a JSON schema (structured outputs)
+type responseFormat struct {
+	Type       string     `json:"type"`
+	JSONSchema jsonSchema `json:"json_schema"`
+}
+
+type jsonSchema struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
 }
 
 type chatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`   // Assistant messages in tool-calling mode
+	ToolCallID string           `json:"tool_call_id,omitempty"` // Tool results
 }
 
 // chatResponse is the OpenAI API response format
@@ -56,7 +77,8 @@ type chatResponse struct {
 	ID      string `json:"id"`
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -71,61 +93,40 @@ type chatResponse struct {
 	} `json:"error"`
 }
 
-// ParseCommand sends a user message to the LLM and parses the response into a Command
-func (c *Client) ParseCommand(ctx context.Context, userMessage string) (*Command, error) {
+// ParseCommands sends a user message and its context to the LLM and parses the response into commands
+func (c *Client) ParseCommands(ctx context.Context, req *Request) ([]*Command, error) {
+	return parseWithRepair(ctx, c.complete, req)
+}
+
+// complete sends the conversation and returns the model's raw reply
+func (c *Client) complete(ctx context.Context, system string, messages []chatMessage) (string, error) {
 	// Build the request
 	reqBody := chatRequest{
-		Model: c.model,
-		Messages: []chatMessage{
-			{Role: "system", Content: SystemPrompt},
-			{Role: "user", Content: userMessage},
-		},
+		Model:               c.model,
+		Messages:            append([]chatMessage{{Role: "system", Content: system}}, messages...),
 		MaxCompletionTokens: 2000,
+		ResponseFormat: &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: jsonSchema{Name: "command", Strict: true, Schema: ResponseSchema()},
+		},
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", openAIURL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	// Send request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse response
 	var chatResp chatResponse
-	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	if err := c.post(ctx, reqBody, &chatResp); err != nil {
+		return "", err
 	}
 
 	// Check for API errors
 	if chatResp.Error != nil {
-		return nil, fmt.Errorf("OpenAI API error: %s", chatResp.Error.Message)
+		return "", fmt.Errorf("OpenAI API error: %s", chatResp.Error.Message)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+		return "", fmt.Errorf("no response from OpenAI")
 	}
 
-	// Parse the LLM's JSON response into a Command
+	addUsage(ctx, c.model, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens)
+
 	content := chatResp.Choices[0].Message.Content
 	finishReason := chatResp.Choices[0].FinishReason
 
@@ -133,16 +134,19 @@ func (c *Client) ParseCommand(ctx context.Context, userMessage string) (*Command
 	fmt.Printf("[DEBUG] LLM finish_reason=%s tokens=%d raw_response=%s\n",
 		finishReason, chatResp.Usage.TotalTokens, content)
 
-	var cmd Command
-	if err := json.Unmarshal([]byte(content), &cmd); err != nil {
-		// LLM didn't return valid JSON - treat as unclear
-		return &Command{
-			Action:      "unclear",
-			Parameters:  map[string]string{"reason": "Failed to parse LLM response", "raw": content},
-			Confidence:  0.0,
-			Explanation: "LLM response was not valid JSON",
-		}, nil
+	return content, nil
+}
+
+// post sends a request body to the chat completions endpoint and decodes the reply into out
+func (c *Client) post(ctx context.Context, reqBody, out interface{}) error {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	return &cmd, nil
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	return c.postJSON(ctx, c.url, headers, jsonBody, out)
 }
@@ -0,0 +1,124 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Parser turns a user's SMS into the commands it asks for, in order
+type Parser interface {
+	ParseCommands(ctx context.Context, req *Request) ([]*Command, error)
+}
+
+// Providers a Parser can be built for
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai_compatible" // llama.cpp, Ollama or any server speaking the OpenAI API
+	ProviderAnthropic        = "anthropic"
+	ProviderFake             = "fake" // Deterministic, in-process, no network
+)
+
+// Options selects and configures a Parser
+type Options struct {
+	Provider string
+	APIKey   string
+	BaseURL  string // Required for ProviderOpenAICompatible
+	Model    string // Optional, each provider has a default
+
+	// Transport carries the HTTP requests, http.DefaultTransport if nil.
+	// Tests set it to replay recorded calls.
+	Transport http.RoundTripper
+
+	// Retries and circuit breaking, zero values keep the defaults
+	MaxAttempts      int           // Tries per call for rate limits and server errors
+	BreakerThreshold int           // Consecutive failed calls that open the breaker
+	BreakerCooldown  time.Duration // How long it stays open before a trial call
+
+	// Optional hooks for logging retries and the breaker opening or closing
+	OnRetry         func(attempt, maxAttempts int, wait time.Duration, err error)
+	OnBreakerChange func(open bool, failures int)
+}
+
+// New builds the Parser for the configured provider
+func New(opts Options) (Parser, error) {
+	switch opts.Provider {
+	case ProviderOpenAI, "":
+		c := NewClient(opts.APIKey)
+		if opts.Model != "" {
+			c.SetModel(opts.Model)
+		}
+		c.configure(opts)
+		return c, nil
+	case ProviderOpenAICompatible:
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("a base URL is required for provider %s", opts.Provider)
+		}
+		c := NewCompatibleClient(opts.BaseURL, opts.APIKey)
+		if opts.Model != "" {
+			c.SetModel(opts.Model)
+		}
+		c.configure(opts)
+		return c, nil
+	case ProviderAnthropic:
+		c := NewAnthropicClient(opts.APIKey)
+		if opts.Model != "" {
+			c.SetModel(opts.Model)
+		}
+		c.configure(opts)
+		return c, nil
+	case ProviderFake:
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider: %s", opts.Provider)
+	}
+}
+
+// DefaultModel returns the model a provider uses when Options.Model is empty
+func DefaultModel(provider string) string {
+	switch provider {
+	case ProviderAnthropic:
+		return defaultAnthropicModel
+	case ProviderFake:
+		return ProviderFake
+	default:
+		return defaultModel
+	}
+}
+
+// completeFunc sends a system prompt and conversation to a model and returns its raw reply
+type completeFunc func(ctx context.Context, system string, messages []chatMessage) (string, error)
+
+// parseWithRepair asks the model for commands. If the reply fails validation
+// the errors are fed back once so the model can correct itself; a reply that
+// is still invalid is returned as an *InvalidReplyError.
+func parseWithRepair(ctx context.Context, complete completeFunc, req *Request) ([]*Command, error) {
+	system := BuildSystemPrompt(req)
+	messages := []chatMessage{{Role: "user", Content: req.History.Render(req.Message)}}
+
+	content, err := complete(ctx, system, messages)
+	if err != nil {
+		return nil, err
+	}
+	cmds, errs := decodeCommands(content)
+	if len(errs) == 0 {
+		return cmds, nil
+	}
+
+	messages = append(messages,
+		chatMessage{Role: "assistant", Content: content},
+		chatMessage{Role: "user", Content: repairPrompt(errs)},
+	)
+
+	content, err = complete(ctx, system, messages)
+	if err != nil {
+		return nil, err
+	}
+	cmds, errs = decodeCommands(content)
+	if len(errs) == 0 {
+		return cmds, nil
+	}
+
+	return nil, &InvalidReplyError{Errs: errs}
+}
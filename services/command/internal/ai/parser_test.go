@@ -0,0 +1,235 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Factory Tests
+// =============================================================================
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"default is openai", Options{APIKey: "sk-test"}, false},
+		{"openai", Options{Provider: ProviderOpenAI, APIKey: "sk-test", Model: "gpt-4"}, false},
+		{"compatible", Options{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:11434/v1"}, false},
+		{"compatible without base url", Options{Provider: ProviderOpenAICompatible}, true},
+		{"anthropic", Options{Provider: ProviderAnthropic, APIKey: "sk-ant-test"}, false},
+		{"fake", Options{Provider: ProviderFake}, false},
+		{"unknown", Options{Provider: "carrier-pigeon"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && p == nil {
+				t.Error("expected a parser")
+			}
+		})
+	}
+}
+
+func TestNewCompatibleClient_URL(t *testing.T) {
+	c := NewCompatibleClient("http://localhost:8080/v1/", "")
+	if c.url != "http://localhost:8080/v1/chat/completions" {
+		t.Errorf("unexpected url: %s", c.url)
+	}
+}
+
+// =============================================================================
+// Response Decoding Tests
+// =============================================================================
+
+func TestDecodeCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		valid    bool
+	}{
+		{"plain json", `{"action": "list", "parameters": {}, "confidence": 0.9}`, "list", true},
+		{"fenced json", "```json\n{\"action\": \"stats\", \"confidence\": 0.9}\n```", "stats", true},
+		{"bare fence", "```\n{\"action\": \"stats\", \"confidence\": 1}\n```", "stats", true},
+		{"numeric todo id", `{"action": "complete", "parameters": {"todo_id": 3}, "confidence": 0.9}`, "complete", true},
+		{"null parameters dropped", `{"action": "create", "parameters": {"title": "milk", "description": null}, "confidence": 0.9}`, "create", true},
+		{"missing confidence", `{"action": "stats"}`, "stats", false},
+		{"nested parameter", `{"action": "create", "parameters": {"title": {"text": "milk"}}, "confidence": 0.9}`, "create", false},
+		{"not json", "Sure! I added that for you.", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds, errs := decodeCommands(tt.content)
+			if (len(errs) == 0) != tt.valid {
+				t.Fatalf("expected valid=%v, got errors %v", tt.valid, errs)
+			}
+			if len(cmds) > 0 && cmds[0].Action != tt.expected {
+				t.Errorf("expected action %s, got %s", tt.expected, cmds[0].Action)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Backend Tests
+// =============================================================================
+
+func TestCompatibleClient_ParseCommands(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header without a key, got %q", auth)
+		}
+
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "llama3" || len(req.Messages) != 2 || req.Messages[1].Content != "<sms>show my list</sms>" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+
+		w.Write([]byte(`{"choices": [{"message": {"content": "{\"commands\": [{\"action\": \"list\", \"confidence\": 0.95}]}"}}]}`))
+	}))
+	defer server.Close()
+
+	c := NewCompatibleClient(server.URL+"/v1", "")
+	c.SetModel("llama3")
+
+	cmds, err := c.ParseCommands(context.Background(), NewRequest("show my list"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmds) != 1 || cmds[0].Action != "list" || cmds[0].Confidence != 0.95 {
+		t.Errorf("unexpected commands: %+v", cmds)
+	}
+}
+
+func TestAnthropicClient_ParseCommands(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-test" {
+			t.Errorf("expected x-api-key header, got %q", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("expected anthropic-version header, got %q", r.Header.Get("anthropic-version"))
+		}
+
+		var req messagesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !strings.HasPrefix(req.System, CommandSchema) || len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+			t.Errorf("expected system prompt and a single user message, got %+v", req.Messages)
+		}
+		if req.ToolChoice == nil || req.ToolChoice.Name != commandToolName || len(req.Tools) != 1 {
+			t.Errorf("expected the command tool to be forced, got %+v", req.ToolChoice)
+		}
+
+		w.Write([]byte(`{"content": [{"type": "tool_use", "name": "record_command", "input": {"commands": [{"action": "create", "parameters": {"title": "buy milk"}, "confidence": 0.9}]}}], "stop_reason": "tool_use"}`))
+	}))
+	defer server.Close()
+
+	c := NewAnthropicClient("sk-ant-test")
+	c.url = server.URL
+
+	cmds, err := c.ParseCommands(context.Background(), NewRequest("remind me to buy milk"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmds) != 1 || cmds[0].Action != "create" || cmds[0].Parameters["title"] != "buy milk" {
+		t.Errorf("unexpected commands: %+v", cmds)
+	}
+}
+
+func TestAnthropicClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	c := NewAnthropicClient("bad")
+	c.url = server.URL
+
+	if _, err := c.ParseCommands(context.Background(), NewRequest("list")); err == nil {
+		t.Error("expected an error for an API error response")
+	}
+}
+
+// =============================================================================
+// Fake Tests
+// =============================================================================
+
+func TestFake_CannedResponse(t *testing.T) {
+	f := NewFake()
+	f.Set("What's up?", &Command{Action: "list", Parameters: map[string]string{"filter": "active"}, Confidence: 1})
+
+	cmds, err := f.ParseCommands(context.Background(), NewRequest("  what's up?  "))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmds) != 1 || cmds[0].Action != "list" {
+		t.Fatalf("expected canned list command, got %+v", cmds)
+	}
+
+	// Changing the result must not change the canned response
+	cmds[0].Parameters["filter"] = "all"
+	again, _ := f.ParseCommands(context.Background(), NewRequest("what's up?"))
+	if again[0].Parameters["filter"] != "active" {
+		t.Error("expected canned response to be copied")
+	}
+
+	if calls := f.Calls(); len(calls) != 2 {
+		t.Errorf("expected 2 recorded calls, got %v", calls)
+	}
+}
+
+func TestFake_Guesses(t *testing.T) {
+	tests := []struct {
+		input  string
+		action string
+		key    string
+		value  string
+	}{
+		{"remind me to call mom", "create", "title", "call mom"},
+		{"buy milk", "create", "title", "buy milk"},
+		{"show completed", "list", "filter", "completed"},
+		{"done with #3", "complete", "todo_id", "3"},
+		{"finished groceries", "complete", "title_hint", "groceries"},
+		{"delete 2", "delete", "todo_id", "2"},
+		{"I'm stuck on the report", "nudge", "task_context", "I'm stuck on the report"},
+		{"nudge me on #3", "nudge", "todo_id", "3"},
+		{"I can't choose what to do", "nudge", "todo_id", ""},
+		{"break down the garage", "breakdown", "title_hint", "the garage"},
+		{"what errands do I have?", "list", "category", "errands"},
+		{"show my work todos", "list", "category", "work"},
+		{"   ", "unclear", "reason", "empty message"},
+	}
+
+	f := NewFake()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cmds, err := f.ParseCommands(context.Background(), NewRequest(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cmd := cmds[0]
+			if cmd.Action != tt.action || cmd.Parameters[tt.key] != tt.value {
+				t.Errorf("expected %s %s=%q, got %s %v", tt.action, tt.key, tt.value, cmd.Action, cmd.Parameters)
+			}
+			if errs := Validate(cmd); len(errs) > 0 {
+				t.Errorf("expected fake commands to be valid, got %v", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rough budget for the todo list in the prompt. Tokens are estimated at four
+// characters each, which is close enough for short English titles.
+const (
+	todoTokenBudget = 400
+	charsPerToken   = 4
+)
+
+// TodoRef is an active todo as shown to the model
+type TodoRef struct {
+	ID    int64
+	Title string
+}
+
+// Request is everything the parser is told about one message
+type Request struct {
+	Message  string
+	History  *History       // Recent conversation, nil if there is none
+	Now      time.Time      // Zero means the current time
+	Location *time.Location // The user's timezone, nil means UTC
+	Todos    []TodoRef      // The user's active todos, newest first
+}
+
+// NewRequest creates a request for a message with no other context
+func NewRequest(message string) *Request {
+	return &Request{Message: message}
+}
+
+// PromptVersion identifies the prompt and response schema, so results from
+// different versions can be told apart. It changes with any edit to either.
+func PromptVersion() string {
+	schema, _ := json.Marshal(ResponseSchema())
+	sum := sha256.New()
+	sum.Write([]byte(SystemPrompt))
+	sum.Write(schema)
+	return hex.EncodeToString(sum.Sum(nil))[:12]
+}
+
+// BuildSystemPrompt puts the current time, the user's timezone and their
+// active todos after the command schema, so relative dates can be worked out
+// and references can be resolved to real ids
+func BuildSystemPrompt(req *Request) string {
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	var b strings.Builder
+	b.WriteString(CommandSchema)
+	b.WriteString("\n## Context\n\n")
+	fmt.Fprintf(&b, "Current time: %s (%s)\n", now.Format(time.RFC3339), now.Format("Monday"))
+	fmt.Fprintf(&b, "User's timezone: %s\n", loc)
+	b.WriteString("Work out relative dates like \"yesterday\" in the user's timezone.\n\n")
+	b.WriteString(formatTodoRefs(req.Todos, todoTokenBudget))
+	b.WriteString(systemPromptEnd)
+
+	return b.String()
+}
+
+// formatTodoRefs lists todos one per line, newest first, stopping before the
+// list goes over the token budget
+func formatTodoRefs(todos []TodoRef, budget int) string {
+	if len(todos) == 0 {
+		return "The user has no active todos.\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("The user's active todos, newest first. Use these ids for todo_id:\n")
+
+	remaining := budget * charsPerToken
+	shown := 0
+	for _, todo := range todos {
+		line := fmt.Sprintf("#%d: %s\n", todo.ID, delimitTitle(todo.Title))
+		if len(line) > remaining {
+			break
+		}
+		b.WriteString(line)
+		remaining -= len(line)
+		shown++
+	}
+	if shown < len(todos) {
+		fmt.Fprintf(&b, "(%d older todos not shown - use title_hint for those)\n", len(todos)-shown)
+	}
+
+	return b.String()
+}
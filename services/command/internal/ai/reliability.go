@@ -0,0 +1,379 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for retries and the circuit breaker
+const (
+	DefaultMaxAttempts      = 3
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 8 * time.Second
+
+	// A Retry-After longer than this isn't waited out; the call fails instead
+	// so the message isn't held up
+	maxRetryAfter = 20 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the provider while it is failing
+var ErrCircuitOpen = errors.New("LLM provider is unavailable, circuit breaker is open")
+
+// APIError is a failed response from a provider
+type APIError struct {
+	Provider   string
+	StatusCode int // 0 when no response arrived
+	Message    string
+	RetryAfter time.Duration // From the Retry-After header, 0 if not sent
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s request failed: %s", e.Provider, e.Message)
+	}
+	return fmt.Sprintf("%s API error (HTTP %d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the same request may succeed later: rate limits,
+// overload, server errors and requests that never got a response
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case 0, http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout, 529: // 529 is Anthropic's "overloaded"
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether an error is worth retrying
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable()
+}
+
+// IsUnavailable reports whether the provider couldn't be reached or kept
+// failing, as opposed to rejecting the request itself
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || IsRetryable(err)
+}
+
+// IsRejected reports whether the provider answered but refused the request,
+// e.g. a malformed request or a bad key. Sending it again won't help.
+func IsRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && !apiErr.Retryable()
+}
+
+// parseRetryAfter reads Retry-After as seconds or an HTTP date, and OpenAI's
+// retry-after-ms when present
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.Atoi(h.Get("Retry-After-Ms")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	value := h.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// =============================================================================
+// Retries
+// =============================================================================
+
+// retryPolicy retries retryable errors with exponential backoff and full
+// jitter, waiting at least as long as the provider asked
+type retryPolicy struct {
+	maxAttempts int
+	sleep       func(ctx context.Context, d time.Duration) error
+	onRetry     func(attempt, maxAttempts int, wait time.Duration, err error) // Optional
+}
+
+func newRetryPolicy(maxAttempts int) retryPolicy {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	return retryPolicy{maxAttempts: maxAttempts, sleep: sleepContext}
+}
+
+// do calls fn until it succeeds, fails permanently or runs out of attempts
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryable(err) || attempt >= p.maxAttempts {
+			return err
+		}
+
+		wait := backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > wait {
+			if apiErr.RetryAfter > maxRetryAfter {
+				return err
+			}
+			wait = apiErr.RetryAfter
+		}
+
+		if p.onRetry != nil {
+			p.onRetry(attempt, p.maxAttempts, wait, err)
+		}
+		if sleepErr := p.sleep(ctx, wait); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// backoff picks a random wait up to 2^(attempt-1) times the base delay
+func backoff(attempt int) time.Duration {
+	ceiling := baseBackoff << (attempt - 1)
+	if ceiling > maxBackoff || ceiling <= 0 {
+		ceiling = maxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling))) + time.Millisecond
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// =============================================================================
+// Circuit Breaker
+// =============================================================================
+
+// Breaker stops calls to a provider after repeated failures. Once the
+// cooldown has passed one call is let through; its result closes the breaker
+// or opens it for another cooldown.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	onChange  func(open bool, failures int) // Optional
+
+	mu       sync.Mutex
+	failures int // Consecutive failures
+	openedAt time.Time
+	probing  bool // A call is testing whether the provider is back
+}
+
+// NewBreaker creates a breaker that opens after threshold consecutive failures
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = DefaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// OnChange calls fn whenever the breaker opens or closes, outside its lock
+func (b *Breaker) OnChange(fn func(open bool, failures int)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = fn
+}
+
+// Allow returns ErrCircuitOpen if a call shouldn't be made now
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// Record counts the result of an allowed call. Only unavailability counts
+// as a failure; a rejected request says nothing about the provider's health.
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	wasOpen := b.failures >= b.threshold
+	b.probing = false
+	if err == nil || !IsRetryable(err) {
+		b.failures = 0
+	} else {
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openedAt = b.now()
+		}
+	}
+	open, failures, onChange := b.failures >= b.threshold, b.failures, b.onChange
+	b.mu.Unlock()
+
+	if onChange != nil && open != wasOpen {
+		onChange(open, failures)
+	}
+}
+
+// Release gives up an allowed call without a result
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Open reports whether calls are currently being refused
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.threshold
+}
+
+// =============================================================================
+// Shared HTTP
+// =============================================================================
+
+// transport sends JSON requests to a provider, with retries and a breaker
+type transport struct {
+	provider   string
+	httpClient *http.Client
+	retry      retryPolicy
+	breaker    *Breaker
+}
+
+func newTransport(provider string) transport {
+	return transport{
+		provider:   provider,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		retry:      newRetryPolicy(DefaultMaxAttempts),
+		breaker:    NewBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+	}
+}
+
+// SetTransport replaces the HTTP transport, nil keeps the default
+func (t *transport) SetTransport(rt http.RoundTripper) {
+	if rt != nil {
+		t.httpClient.Transport = rt
+	}
+}
+
+// SetMaxAttempts sets how many times a retryable failure is tried, 0 keeps the default
+func (t *transport) SetMaxAttempts(n int) {
+	if n > 0 {
+		t.retry.maxAttempts = n
+	}
+}
+
+// SetBreaker replaces the circuit breaker, nil keeps the default
+func (t *transport) SetBreaker(b *Breaker) {
+	if b != nil {
+		t.breaker = b
+	}
+}
+
+// Breaker returns the circuit breaker guarding the provider
+func (t *transport) Breaker() *Breaker {
+	return t.breaker
+}
+
+// configure applies the transport settings from Options
+func (t *transport) configure(opts Options) {
+	t.SetTransport(opts.Transport)
+	t.SetMaxAttempts(opts.MaxAttempts)
+	if opts.BreakerThreshold > 0 || opts.BreakerCooldown > 0 {
+		t.SetBreaker(NewBreaker(opts.BreakerThreshold, opts.BreakerCooldown))
+	}
+	t.retry.onRetry = opts.OnRetry
+	if opts.OnBreakerChange != nil {
+		t.breaker.OnChange(opts.OnBreakerChange)
+	}
+}
+
+// postJSON sends body to url and decodes a successful reply into out.
+// Failed responses become *APIError.
+func (t *transport) postJSON(ctx context.Context, url string, headers map[string]string, body []byte, out interface{}) error {
+	if err := t.breaker.Allow(); err != nil {
+		return err
+	}
+	err := t.retry.do(ctx, func() error {
+		return t.send(ctx, url, headers, body, out)
+	})
+	if ctx.Err() != nil {
+		// Cancelled by us, which says nothing about the provider
+		t.breaker.Release()
+	} else {
+		t.breaker.Record(err)
+	}
+	return err
+}
+
+// send makes one attempt
+func (t *transport) send(ctx context.Context, url string, headers map[string]string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &APIError{Provider: t.provider, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &APIError{Provider: t.provider, StatusCode: resp.StatusCode, Message: "failed to read response: " + err.Error()}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{
+			Provider:   t.provider,
+			StatusCode: resp.StatusCode,
+			Message:    http.StatusText(resp.StatusCode),
+			RetryAfter: parseRetryAfter(resp.Header, time.Now()),
+		}
+		// Both providers describe the problem as {"error": {"message": ...}}
+		var errBody struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errBody) == nil && errBody.Error.Message != "" {
+			apiErr.Message = errBody.Error.Message
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// noSleep records the waits instead of sleeping
+func noSleep(waits *[]time.Duration) func(context.Context, time.Duration) error {
+	return func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+}
+
+// =============================================================================
+// Error Classification Tests
+// =============================================================================
+
+func TestAPIError_Retryable(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{0, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+		{529, true},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		err := &APIError{Provider: "OpenAI", StatusCode: tt.status}
+		if IsRetryable(err) != tt.retryable {
+			t.Errorf("status %d: expected retryable=%v", tt.status, tt.retryable)
+		}
+		if IsRejected(err) == tt.retryable {
+			t.Errorf("status %d: expected rejected=%v", tt.status, !tt.retryable)
+		}
+	}
+
+	if IsRetryable(errors.New("boom")) || IsRejected(errors.New("boom")) || !IsUnavailable(ErrCircuitOpen) {
+		t.Error("expected plain errors to be permanent and an open circuit to be unavailable")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", http.Header{}, 0},
+		{"seconds", http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{"date", http.Header{"Retry-After": {now.Add(3 * time.Second).Format(http.TimeFormat)}}, 3 * time.Second},
+		{"milliseconds win", http.Header{"Retry-After": {"7"}, "Retry-After-Ms": {"250"}}, 250 * time.Millisecond},
+		{"garbage", http.Header{"Retry-After": {"soon"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Retry Tests
+// =============================================================================
+
+func TestRetryPolicy(t *testing.T) {
+	var waits []time.Duration
+	var retried []int
+	p := retryPolicy{maxAttempts: 3, sleep: noSleep(&waits), onRetry: func(attempt, _ int, _ time.Duration, _ error) {
+		retried = append(retried, attempt)
+	}}
+
+	calls := 0
+	err := p.do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 || len(waits) != 2 {
+		t.Errorf("expected success on the third try after two waits, got %v after %d calls", err, calls)
+	}
+	if len(retried) != 2 || retried[0] != 1 || retried[1] != 2 {
+		t.Errorf("expected both retries reported, got %v", retried)
+	}
+	for i, wait := range waits {
+		if ceiling := baseBackoff << i; wait <= 0 || wait > ceiling+time.Millisecond {
+			t.Errorf("wait %d out of range: %s", i, wait)
+		}
+	}
+
+	calls = 0
+	err = p.do(context.Background(), func() error {
+		calls++
+		return &APIError{StatusCode: http.StatusBadRequest}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a permanent error not to be retried, got %d calls", calls)
+	}
+
+	calls = 0
+	err = p.do(context.Background(), func() error {
+		calls++
+		return &APIError{StatusCode: http.StatusTooManyRequests}
+	})
+	if !IsRetryable(err) || calls != 3 {
+		t.Errorf("expected to give up after 3 attempts, got %d calls", calls)
+	}
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	var waits []time.Duration
+	p := retryPolicy{maxAttempts: 2, sleep: noSleep(&waits)}
+
+	p.do(context.Background(), func() error {
+		return &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 15 * time.Second}
+	})
+	if len(waits) != 1 || waits[0] != 15*time.Second {
+		t.Errorf("expected to wait the 15s asked for, got %v", waits)
+	}
+
+	// Too long to hold a message for
+	waits = nil
+	calls := 0
+	p.do(context.Background(), func() error {
+		calls++
+		return &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	})
+	if calls != 1 || len(waits) != 0 {
+		t.Errorf("expected to give up instead of waiting an hour, got %d calls", calls)
+	}
+}
+
+// =============================================================================
+// Circuit Breaker Tests
+// =============================================================================
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	var changes []bool
+	b.OnChange(func(open bool, _ int) { changes = append(changes, open) })
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+
+	b.Record(&APIError{StatusCode: http.StatusBadRequest})
+	b.Record(&APIError{StatusCode: http.StatusBadRequest})
+	if b.Open() {
+		t.Fatal("rejected requests must not open the breaker")
+	}
+
+	b.Record(unavailable)
+	b.Record(unavailable)
+	if !b.Open() || b.Allow() != ErrCircuitOpen {
+		t.Fatal("expected the breaker to open after 2 failures")
+	}
+
+	// After the cooldown one trial call goes through
+	now = now.Add(time.Minute)
+	if b.Allow() != nil {
+		t.Fatal("expected a trial call after the cooldown")
+	}
+	if b.Allow() != ErrCircuitOpen {
+		t.Fatal("expected only one trial call at a time")
+	}
+
+	// A failed trial opens it for another cooldown
+	b.Record(unavailable)
+	if b.Allow() != ErrCircuitOpen {
+		t.Fatal("expected the breaker to reopen after a failed trial")
+	}
+
+	now = now.Add(time.Minute)
+	if b.Allow() != nil {
+		t.Fatal("expected another trial call")
+	}
+	b.Record(nil)
+	if b.Open() || b.Allow() != nil {
+		t.Fatal("expected a successful trial to close the breaker")
+	}
+
+	// Reopening after a failed trial isn't a change
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("expected the breaker to report opening then closing, got %v", changes)
+	}
+}
+
+func TestClient_RetriesThenOpensBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "requests"}}`))
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	c := NewCompatibleClient(server.URL, "")
+	c.retry = retryPolicy{maxAttempts: 2, sleep: noSleep(&waits)}
+	c.SetBreaker(NewBreaker(2, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		_, err := c.ParseCommands(context.Background(), NewRequest("remind me to buy milk"))
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "Rate limit reached" {
+			t.Fatalf("expected a rate limit error, got %v", err)
+		}
+	}
+	if calls != 4 || waits[0] != time.Second {
+		t.Errorf("expected 2 attempts per call waiting 1s, got %d calls and waits %v", calls, waits)
+	}
+
+	_, err := c.ParseCommands(context.Background(), NewRequest("remind me to buy milk"))
+	if !errors.Is(err, ErrCircuitOpen) || calls != 4 {
+		t.Errorf("expected the open breaker to skip the call, got %v after %d calls", err, calls)
+	}
+}
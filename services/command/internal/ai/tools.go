@@ -0,0 +1,215 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolSpec describes a function the model may call
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments object
+}
+
+// ToolCall is the model asking for a function to be run
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// ToolResult is the output of a ToolCall, sent back to the model
+type ToolResult struct {
+	CallID  string
+	Content string
+	IsError bool
+}
+
+// Message is one entry of a tool-calling conversation. User messages carry
+// Content, assistant messages Content and/or ToolCalls, and tool messages
+// the Results of the previous assistant message's calls.
+type Message struct {
+	Role      string // "user", "assistant" or "tool"
+	Content   string
+	ToolCalls []ToolCall
+	Results   []ToolResult
+}
+
+// Message roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// ToolCaller is a model that can call functions. NextStep returns the
+// assistant's next message: tool calls to run, or a final answer in Content.
+type ToolCaller interface {
+	NextStep(ctx context.Context, system string, messages []Message, tools []ToolSpec) (*Message, error)
+}
+
+// =============================================================================
+// OpenAI
+// =============================================================================
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON encoded as a string
+	} `json:"function"`
+}
+
+// toolChatRequest is a chat completions request with functions
+type toolChatRequest struct {
+	Model               string        `json:"model"`
+	Messages            []chatMessage `json:"messages"`
+	Tools               []openAITool  `json:"tools"`
+	MaxCompletionTokens int           `json:"max_completion_tokens,omitempty"`
+}
+
+// NextStep sends the conversation with the available functions and returns the model's next message
+func (c *Client) NextStep(ctx context.Context, system string, messages []Message, tools []ToolSpec) (*Message, error) {
+	reqBody := toolChatRequest{
+		Model:               c.model,
+		Messages:            []chatMessage{{Role: "system", Content: system}},
+		MaxCompletionTokens: 2000,
+	}
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, openAITool{
+			Type:     "function",
+			Function: openAIFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters},
+		})
+	}
+
+	for _, m := range messages {
+		switch m.Role {
+		case RoleTool:
+			// One message per result, each answering a call by id
+			for _, r := range m.Results {
+				reqBody.Messages = append(reqBody.Messages, chatMessage{Role: "tool", Content: r.Content, ToolCallID: r.CallID})
+			}
+		case RoleAssistant:
+			msg := chatMessage{Role: "assistant", Content: m.Content}
+			for _, call := range m.ToolCalls {
+				tc := openAIToolCall{ID: call.ID, Type: "function"}
+				tc.Function.Name = call.Name
+				tc.Function.Arguments = string(call.Arguments)
+				msg.ToolCalls = append(msg.ToolCalls, tc)
+			}
+			reqBody.Messages = append(reqBody.Messages, msg)
+		default:
+			reqBody.Messages = append(reqBody.Messages, chatMessage{Role: m.Role, Content: m.Content})
+		}
+	}
+
+	var chatResp chatResponse
+	if err := c.post(ctx, reqBody, &chatResp); err != nil {
+		return nil, err
+	}
+	if chatResp.Error != nil {
+		return nil, fmt.Errorf("OpenAI API error: %s", chatResp.Error.Message)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from OpenAI")
+	}
+
+	addUsage(ctx, c.model, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens)
+
+	choice := chatResp.Choices[0]
+	reply := &Message{Role: RoleAssistant, Content: choice.Message.Content}
+	for _, tc := range choice.Message.ToolCalls {
+		args := json.RawMessage(tc.Function.Arguments)
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		reply.ToolCalls = append(reply.ToolCalls, ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: args})
+	}
+
+	return reply, nil
+}
+
+// =============================================================================
+// Anthropic
+// =============================================================================
+
+// contentBlock is one block of an Anthropic message
+type contentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+	IsError   bool            `json:"is_error,omitempty"`    // tool_result
+}
+
+// NextStep sends the conversation with the available tools and returns the model's next message
+func (c *AnthropicClient) NextStep(ctx context.Context, system string, messages []Message, tools []ToolSpec) (*Message, error) {
+	reqBody := messagesRequest{
+		Model:     c.model,
+		MaxTokens: 2000,
+		System:    system,
+	}
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, tool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters})
+	}
+
+	for _, m := range messages {
+		var blocks []contentBlock
+		role := m.Role
+		switch m.Role {
+		case RoleTool:
+			// Tool results go back to the model as a user message
+			role = RoleUser
+			for _, r := range m.Results {
+				blocks = append(blocks, contentBlock{Type: "tool_result", ToolUseID: r.CallID, Content: r.Content, IsError: r.IsError})
+			}
+		default:
+			if m.Content != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: m.Content})
+			}
+			for _, call := range m.ToolCalls {
+				blocks = append(blocks, contentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: call.Arguments})
+			}
+		}
+		reqBody.Messages = append(reqBody.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	var msgResp messagesResponse
+	if err := c.post(ctx, reqBody, &msgResp); err != nil {
+		return nil, err
+	}
+	if msgResp.Error != nil {
+		return nil, fmt.Errorf("Anthropic API error: %s", msgResp.Error.Message)
+	}
+
+	addUsage(ctx, c.model, msgResp.Usage.InputTokens, msgResp.Usage.OutputTokens)
+
+	reply := &Message{Role: RoleAssistant}
+	for _, block := range msgResp.Content {
+		switch block.Type {
+		case "text":
+			reply.Content += block.Text
+		case "tool_use":
+			reply.ToolCalls = append(reply.ToolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: block.Input})
+		}
+	}
+
+	return reply, nil
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// =============================================================================
+// Tool-Calling Tests
+// =============================================================================
+
+var testTools = []ToolSpec{{
+	Name:        "complete_todo",
+	Description: "Mark a todo as done",
+	Parameters:  map[string]interface{}{"type": "object", "properties": map[string]interface{}{"todo_id": map[string]interface{}{"type": "integer"}}},
+}}
+
+// testConversation is a tool call followed by its result
+var testConversation = []Message{
+	{Role: RoleUser, Content: "done with milk"},
+	{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Name: "complete_todo", Arguments: json.RawMessage(`{"todo_id":4}`)}}},
+	{Role: RoleTool, Results: []ToolResult{{CallID: "call_1", Content: `{"id":4}`}}},
+}
+
+func TestClient_NextStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req toolChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "complete_todo" {
+			t.Errorf("expected the complete_todo function, got %+v", req.Tools)
+		}
+		if len(req.Messages) != 4 || req.Messages[0].Role != "system" {
+			t.Fatalf("expected system, user, assistant and tool messages, got %+v", req.Messages)
+		}
+		if call := req.Messages[2].ToolCalls; len(call) != 1 || call[0].Function.Arguments != `{"todo_id":4}` {
+			t.Errorf("expected the tool call with string arguments, got %+v", call)
+		}
+		if result := req.Messages[3]; result.Role != "tool" || result.ToolCallID != "call_1" {
+			t.Errorf("expected a tool result for call_1, got %+v", result)
+		}
+
+		w.Write([]byte(`{"choices": [{"message": {"content": "", "tool_calls": [{"id": "call_2", "type": "function", "function": {"name": "complete_todo", "arguments": "{\"todo_id\":5}"}}]}, "finish_reason": "tool_calls"}]}`))
+	}))
+	defer server.Close()
+
+	c := NewCompatibleClient(server.URL, "")
+	reply, err := c.NextStep(context.Background(), "system", testConversation, testTools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reply.ToolCalls) != 1 || reply.ToolCalls[0].ID != "call_2" || string(reply.ToolCalls[0].Arguments) != `{"todo_id":5}` {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+}
+
+func TestAnthropicClient_NextStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Tools    []tool `json:"tools"`
+			Messages []struct {
+				Role    string         `json:"role"`
+				Content []contentBlock `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Tools) != 1 || req.Tools[0].Name != "complete_todo" {
+			t.Errorf("expected the complete_todo tool, got %+v", req.Tools)
+		}
+		if len(req.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %+v", req.Messages)
+		}
+		if use := req.Messages[1].Content; len(use) != 1 || use[0].Type != "tool_use" || use[0].ID != "call_1" {
+			t.Errorf("expected a tool_use block, got %+v", use)
+		}
+		if result := req.Messages[2]; result.Role != "user" || result.Content[0].Type != "tool_result" || result.Content[0].ToolUseID != "call_1" {
+			t.Errorf("expected the tool result as a user message, got %+v", result)
+		}
+
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Done: #4 buy milk"}], "stop_reason": "end_turn"}`))
+	}))
+	defer server.Close()
+
+	c := NewAnthropicClient("sk-ant-test")
+	c.url = server.URL
+
+	reply, err := c.NextStep(context.Background(), "system", testConversation, testTools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply.Content != "Done: #4 buy milk" || len(reply.ToolCalls) != 0 {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage adds up the tokens spent on the LLM calls made for one message. The
+// caller attaches it to the context with WithUsage and reads it once the
+// parser or agent returns; the clients add to it after every successful call.
+type Usage struct {
+	mu               sync.Mutex
+	model            string
+	calls            int
+	promptTokens     int64
+	completionTokens int64
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context that counts the LLM calls made with it
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	u := &Usage{}
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+// addUsage counts one call against the context's Usage, if it has one
+func addUsage(ctx context.Context, model string, promptTokens, completionTokens int) {
+	if u, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		u.Add(model, promptTokens, completionTokens)
+	}
+}
+
+// Add counts one call
+func (u *Usage) Add(model string, promptTokens, completionTokens int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.model = model
+	u.calls++
+	u.promptTokens += int64(promptTokens)
+	u.completionTokens += int64(completionTokens)
+}
+
+// Model is the model the calls went to, empty if none were made
+func (u *Usage) Model() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.model
+}
+
+// Calls is the number of successful calls
+func (u *Usage) Calls() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.calls
+}
+
+// Tokens returns the prompt and completion tokens used
+func (u *Usage) Tokens() (prompt, completion int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.promptTokens, u.completionTokens
+}
@@ -0,0 +1,420 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError describes one problem with a command returned by the LLM
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is every problem found with a command
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// InvalidReplyError is returned when the LLM's reply still fails validation
+// after it was asked to repair it
+type InvalidReplyError struct {
+	Errs ValidationErrors
+}
+
+func (e *InvalidReplyError) Error() string {
+	return "LLM reply failed validation after a repair: " + e.Errs.Error()
+}
+
+// IsInvalidReply reports whether the LLM answered but never produced valid
+// commands. The message is unclear rather than worth sending again.
+func IsInvalidReply(err error) bool {
+	var invalid *InvalidReplyError
+	return errors.As(err, &invalid)
+}
+
+// actionSpec describes the parameters an action accepts
+type actionSpec struct {
+	params   []string            // Every parameter the action uses
+	required []string            // Must be present and non-empty
+	oneOf    [][]string          // At least one of each group must be present
+	enums    map[string][]string // Allowed values
+}
+
+var (
+	targetParams = []string{"todo_id", "title_hint"}
+
+	actionSpecs = map[string]actionSpec{
+		"create": {
+			params:   []string{"title", "description"},
+			required: []string{"title"},
+		},
+		"complete": {params: targetParams, oneOf: [][]string{targetParams}},
+		"delete":   {params: targetParams, oneOf: [][]string{targetParams}},
+		"restore":  {params: targetParams, oneOf: [][]string{targetParams}},
+		"edit": {
+			params: []string{"todo_id", "title_hint", "new_title", "new_description"},
+			oneOf:  [][]string{targetParams, {"new_title", "new_description"}},
+		},
+		"list": {
+			params: []string{"filter", "completed_after", "completed_before", "category"},
+			enums:  map[string][]string{"filter": {"active", "completed", "all", "trash"}},
+		},
+		"nudge": {
+			// Without a task named, one is picked from the list
+			params: []string{"todo_id", "title_hint", "task_context", "suggested_action"},
+		},
+		"breakdown": {
+			params:   []string{"todo_id", "title_hint", "steps"},
+			required: []string{"steps"},
+			oneOf:    [][]string{targetParams},
+		},
+		"empty_trash":   {},
+		"stats":         {},
+		"export_data":   {},
+		"erase_account": {},
+		"unclear":       {params: []string{"reason"}},
+	}
+
+	// Parameters with a type other than free text, whichever action uses them
+	integerParams = map[string]bool{"todo_id": true}
+	dateParams    = map[string]bool{"completed_after": true, "completed_before": true}
+	stepParams    = map[string]bool{"steps": true}
+)
+
+// How many steps a todo is broken down into
+const (
+	MinSteps = 3
+	MaxSteps = 7
+)
+
+// stepBullet is numbering or a bullet a model may put in front of a step
+var stepBullet = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s*`)
+
+// SplitSteps returns the steps of a breakdown, one per line, without any
+// numbering or bullets and skipping blank lines
+func SplitSteps(value string) []string {
+	var steps []string
+	for _, line := range strings.Split(value, "\n") {
+		if step := strings.TrimSpace(stepBullet.ReplaceAllString(line, "")); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// Actions returns every action the LLM may choose, sorted
+func Actions() []string {
+	actions := make([]string, 0, len(actionSpecs))
+	for action := range actionSpecs {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Validate checks that a command names a known action, carries the parameters
+// that action requires and no others, and that typed parameters parse
+func Validate(cmd *Command) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	spec, ok := actionSpecs[cmd.Action]
+	if !ok {
+		fail("action", "must be one of %s, got %q", strings.Join(Actions(), ", "), cmd.Action)
+		return errs
+	}
+
+	if cmd.Confidence < 0 || cmd.Confidence > 1 {
+		fail("confidence", "must be between 0 and 1, got %g", cmd.Confidence)
+	}
+
+	// Anything else would reach the handler as if the model could set it
+	for _, name := range sortedKeys(cmd.Parameters) {
+		if !contains(spec.params, name) {
+			fail("parameters."+name, "is not a parameter of %s", cmd.Action)
+		}
+	}
+
+	for _, name := range spec.required {
+		if strings.TrimSpace(cmd.Parameters[name]) == "" {
+			fail("parameters."+name, "is required for %s", cmd.Action)
+		}
+	}
+	for _, group := range spec.oneOf {
+		found := false
+		for _, name := range group {
+			if strings.TrimSpace(cmd.Parameters[name]) != "" {
+				found = true
+			}
+		}
+		if !found {
+			fail("parameters", "%s needs one of %s", cmd.Action, strings.Join(group, " or "))
+		}
+	}
+
+	for _, name := range spec.params {
+		value := cmd.Parameters[name]
+		if value == "" {
+			continue
+		}
+		if integerParams[name] {
+			if n, err := strconv.ParseInt(value, 10, 64); err != nil || n <= 0 {
+				fail("parameters."+name, "must be a positive whole number, got %q", value)
+			}
+		}
+		if dateParams[name] {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				fail("parameters."+name, "must be an ISO 8601 timestamp like 2026-01-19T00:00:00Z, got %q", value)
+			}
+		}
+		if stepParams[name] {
+			if n := len(SplitSteps(value)); n < MinSteps || n > MaxSteps {
+				fail("parameters."+name, "must be %d to %d steps, one per line, got %d", MinSteps, MaxSteps, n)
+			}
+		}
+		if allowed, ok := spec.enums[name]; ok && !contains(allowed, value) {
+			fail("parameters."+name, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+		}
+	}
+
+	return errs
+}
+
+// maxCommands bounds how many commands a single message can produce
+const maxCommands = 10
+
+// decodeCommands parses the LLM's reply into an ordered list of commands and
+// validates each. A bare command object is accepted as a list of one.
+func decodeCommands(content string) ([]*Command, ValidationErrors) {
+	// Some models wrap JSON in a markdown code fence despite being asked not to
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "```") {
+		trimmed = strings.TrimPrefix(trimmed, "```json")
+		trimmed = strings.TrimPrefix(trimmed, "```")
+		trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+	}
+
+	var raw map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(trimmed)))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil || raw == nil {
+		return nil, ValidationErrors{{Message: "response must be a single JSON object"}}
+	}
+
+	list, ok := raw["commands"]
+	if !ok {
+		cmd, errs := decodeCommand(raw, "")
+		return []*Command{cmd}, errs
+	}
+
+	items, ok := list.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, ValidationErrors{{Field: "commands", Message: "must be a non-empty array"}}
+	}
+	if len(items) > maxCommands {
+		return nil, ValidationErrors{{Field: "commands", Message: fmt.Sprintf("must have at most %d commands", maxCommands)}}
+	}
+
+	var cmds []*Command
+	var errs ValidationErrors
+	for i, item := range items {
+		prefix := fmt.Sprintf("commands[%d].", i)
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			errs = append(errs, ValidationError{Field: strings.TrimSuffix(prefix, "."), Message: "must be an object"})
+			continue
+		}
+		cmd, cmdErrs := decodeCommand(obj, prefix)
+		cmds = append(cmds, cmd)
+		errs = append(errs, cmdErrs...)
+	}
+
+	return cmds, errs
+}
+
+// decodeCommand converts one decoded command object, prefixing error fields.
+// Parameter values that are numbers or booleans are accepted as text.
+func decodeCommand(raw map[string]interface{}, prefix string) (*Command, ValidationErrors) {
+	cmd := &Command{Parameters: map[string]string{}}
+	var errs ValidationErrors
+	fail := func(field, message string) {
+		errs = append(errs, ValidationError{Field: prefix + field, Message: message})
+	}
+
+	if action, ok := raw["action"].(string); ok {
+		cmd.Action = action
+	} else {
+		fail("action", "is required and must be a string")
+	}
+
+	switch c := raw["confidence"].(type) {
+	case json.Number:
+		cmd.Confidence, _ = c.Float64()
+	default:
+		fail("confidence", "is required and must be a number")
+	}
+
+	if explanation, ok := raw["explanation"].(string); ok {
+		cmd.Explanation = explanation
+	}
+
+	switch params := raw["parameters"].(type) {
+	case nil:
+	case map[string]interface{}:
+		for name, value := range params {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				if v != "" {
+					cmd.Parameters[name] = v
+				}
+			case json.Number:
+				cmd.Parameters[name] = v.String()
+			case bool:
+				cmd.Parameters[name] = strconv.FormatBool(v)
+			case []interface{}:
+				// Steps often come back as a list despite the schema
+				lines := make([]string, 0, len(v))
+				for _, item := range v {
+					if line, ok := item.(string); ok {
+						lines = append(lines, line)
+					}
+				}
+				if len(lines) != len(v) || !stepParams[name] {
+					fail("parameters."+name, "must be a string")
+					continue
+				}
+				cmd.Parameters[name] = strings.Join(lines, "\n")
+			default:
+				fail("parameters."+name, "must be a string")
+			}
+		}
+	default:
+		fail("parameters", "must be an object")
+	}
+
+	if cmd.Action != "" {
+		for _, e := range Validate(cmd) {
+			fail(e.Field, e.Message)
+		}
+	}
+
+	return cmd, errs
+}
+
+// ResponseSchema returns the JSON schema the LLM's reply must follow: an
+// ordered list of commands. It is strict - every parameter is listed and
+// nullable - so it can be used with OpenAI's strict structured outputs and
+// as an Anthropic tool schema.
+func ResponseSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"commands"},
+		"properties": map[string]interface{}{
+			"commands": map[string]interface{}{
+				"type":        "array",
+				"description": "One command per request in the message, in the order given",
+				"items":       commandSchema(),
+			},
+		},
+	}
+}
+
+// commandSchema returns the JSON schema for a single command
+func commandSchema() map[string]interface{} {
+	seen := map[string]bool{}
+	var names []string
+	for _, spec := range actionSpecs {
+		for _, name := range spec.params {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	params := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		params[name] = map[string]interface{}{"type": []string{"string", "null"}}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"action", "parameters", "confidence", "explanation"},
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type": "string",
+				"enum": Actions(),
+			},
+			"parameters": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             names,
+				"properties":           params,
+			},
+			"confidence": map[string]interface{}{
+				"type":        "number",
+				"description": "0-1, how sure you are",
+			},
+			"explanation": map[string]interface{}{
+				"type":        "string",
+				"description": "Brief explanation of your interpretation",
+			},
+		},
+	}
+}
+
+// repairPrompt asks the LLM to fix a reply that failed validation
+func repairPrompt(errs ValidationErrors) string {
+	var b strings.Builder
+	b.WriteString("Your response was not a valid command:\n")
+	for _, e := range errs {
+		b.WriteString("- " + e.Error() + "\n")
+	}
+	b.WriteString("Respond again with only the corrected JSON command.")
+	return b.String()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,245 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Validator Tests
+// =============================================================================
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    Command
+		fields []string // Fields expected to fail, empty for valid
+	}{
+		{"valid create", Command{Action: "create", Parameters: map[string]string{"title": "milk"}, Confidence: 0.9}, nil},
+		{"create without title", Command{Action: "create", Parameters: map[string]string{"description": "2%"}, Confidence: 0.9}, []string{"parameters.title"}},
+		{"unknown action", Command{Action: "fly", Confidence: 0.9}, []string{"action"}},
+		{"confidence out of range", Command{Action: "stats", Confidence: 7}, []string{"confidence"}},
+		{"complete by id", Command{Action: "complete", Parameters: map[string]string{"todo_id": "3"}, Confidence: 1}, nil},
+		{"complete by hint", Command{Action: "complete", Parameters: map[string]string{"title_hint": "milk"}, Confidence: 1}, nil},
+		{"complete without target", Command{Action: "complete", Confidence: 1}, []string{"parameters"}},
+		{"non-numeric todo id", Command{Action: "delete", Parameters: map[string]string{"todo_id": "three"}, Confidence: 1}, []string{"parameters.todo_id"}},
+		{"negative todo id", Command{Action: "delete", Parameters: map[string]string{"todo_id": "-1"}, Confidence: 1}, []string{"parameters.todo_id"}},
+		{"edit without change", Command{Action: "edit", Parameters: map[string]string{"todo_id": "1"}, Confidence: 1}, []string{"parameters"}},
+		{"bad list filter", Command{Action: "list", Parameters: map[string]string{"filter": "urgent"}, Confidence: 1}, []string{"parameters.filter"}},
+		{"bad date", Command{Action: "list", Parameters: map[string]string{"filter": "completed", "completed_after": "yesterday"}, Confidence: 1}, []string{"parameters.completed_after"}},
+		{"list by category", Command{Action: "list", Parameters: map[string]string{"filter": "active", "category": "errands"}, Confidence: 1}, nil},
+		{"nudge without a task", Command{Action: "nudge", Confidence: 1}, nil},
+		{"breakdown", Command{Action: "breakdown", Parameters: map[string]string{"todo_id": "4", "steps": "sort\nsweep\nbag trash"}, Confidence: 1}, nil},
+		{"breakdown too short", Command{Action: "breakdown", Parameters: map[string]string{"todo_id": "4", "steps": "sort\nsweep"}, Confidence: 1}, []string{"parameters.steps"}},
+		{"breakdown without target", Command{Action: "breakdown", Parameters: map[string]string{"steps": "a\nb\nc"}, Confidence: 1}, []string{"parameters"}},
+		{"undeclared parameter", Command{Action: "create", Parameters: map[string]string{"title": "milk", "allow_duplicate": "3"}, Confidence: 1}, []string{"parameters.allow_duplicate"}},
+		{"another action's parameter", Command{Action: "stats", Parameters: map[string]string{"todo_id": "3"}, Confidence: 1}, []string{"parameters.todo_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(&tt.cmd)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("expected %d errors, got %v", len(tt.fields), errs)
+			}
+			for i, field := range tt.fields {
+				if errs[i].Field != field {
+					t.Errorf("expected error on %s, got %v", field, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResponseSchema(t *testing.T) {
+	schema := ResponseSchema()
+
+	// Must be JSON serializable to be sent at all
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("schema is not serializable: %v", err)
+	}
+
+	command := schema["properties"].(map[string]interface{})["commands"].(map[string]interface{})["items"].(map[string]interface{})
+	params := command["properties"].(map[string]interface{})["parameters"].(map[string]interface{})
+	required := params["required"].([]string)
+	properties := params["properties"].(map[string]interface{})
+	if len(required) != len(properties) {
+		t.Errorf("strict mode needs every parameter required, got %d of %d", len(required), len(properties))
+	}
+	for _, name := range []string{"title", "todo_id", "filter", "suggested_action"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("expected parameter %s in schema", name)
+		}
+	}
+
+	actions := command["properties"].(map[string]interface{})["action"].(map[string]interface{})["enum"].([]string)
+	if len(actions) != len(actionSpecs) {
+		t.Errorf("expected %d actions, got %v", len(actionSpecs), actions)
+	}
+}
+
+// =============================================================================
+// Repair Round-Trip Tests
+// =============================================================================
+
+// scriptedServer answers chat completion requests with the given replies in order
+func scriptedServer(t *testing.T, replies ...string) (*httptest.Server, *[]chatRequest) {
+	var requests []chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		if len(requests) > len(replies) {
+			t.Fatalf("unexpected request %d", len(requests))
+		}
+		content, _ := json.Marshal(replies[len(requests)-1])
+		w.Write([]byte(`{"choices": [{"message": {"content": ` + string(content) + `}}]}`))
+	}))
+	return server, &requests
+}
+
+func TestParseCommand_RepairsInvalidReply(t *testing.T) {
+	server, requests := scriptedServer(t,
+		`{"commands": [{"action": "complete", "parameters": {}, "confidence": 0.9, "explanation": "done"}]}`,
+		`{"commands": [{"action": "complete", "parameters": {"title_hint": "milk"}, "confidence": 0.9, "explanation": "done"}]}`,
+	)
+	defer server.Close()
+
+	c := NewCompatibleClient(server.URL, "")
+	cmds, err := c.ParseCommands(context.Background(), NewRequest("got the milk"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cmds) != 1 || cmds[0].Action != "complete" || cmds[0].Parameters["title_hint"] != "milk" {
+		t.Errorf("expected repaired command, got %+v", cmds)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*requests))
+	}
+
+	repair := (*requests)[1].Messages
+	if len(repair) != 4 || repair[2].Role != "assistant" || repair[3].Role != "user" {
+		t.Fatalf("expected system, user, assistant, user messages, got %+v", repair)
+	}
+	if !strings.Contains(repair[3].Content, "commands[0].parameters: complete needs one of todo_id or title_hint") {
+		t.Errorf("expected validation errors fed back, got %q", repair[3].Content)
+	}
+	if (*requests)[0].ResponseFormat == nil || !(*requests)[0].ResponseFormat.JSONSchema.Strict {
+		t.Error("expected a strict JSON schema response format")
+	}
+}
+
+func TestParseCommand_GivesUpAfterOneRepair(t *testing.T) {
+	server, requests := scriptedServer(t, "not json", "still not json")
+	defer server.Close()
+
+	c := NewCompatibleClient(server.URL, "")
+	cmds, err := c.ParseCommands(context.Background(), NewRequest("hello"))
+	if !IsInvalidReply(err) || cmds != nil {
+		t.Errorf("expected an invalid reply error after a failed repair, got %+v (%v)", cmds, err)
+	}
+	if IsUnavailable(err) || IsRejected(err) {
+		t.Errorf("expected an invalid reply not to count as a provider failure: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("expected exactly one repair attempt, got %d requests", len(*requests))
+	}
+}
+
+func TestParseCommand_ValidReplyNeedsNoRepair(t *testing.T) {
+	server, requests := scriptedServer(t, `{"commands": [{"action": "stats", "parameters": {}, "confidence": 1, "explanation": "stats"}]}`)
+	defer server.Close()
+
+	c := NewCompatibleClient(server.URL, "")
+	if _, err := c.ParseCommands(context.Background(), NewRequest("how am I doing")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("expected a single request, got %d", len(*requests))
+	}
+}
+
+// =============================================================================
+// Multiple Command Tests
+// =============================================================================
+
+func TestDecodeCommands_List(t *testing.T) {
+	content := `{"commands": [
+		{"action": "create", "parameters": {"title": "milk"}, "confidence": 0.9, "explanation": ""},
+		{"action": "create", "parameters": {"title": "eggs"}, "confidence": 0.9, "explanation": ""},
+		{"action": "complete", "parameters": {"todo_id": 2}, "confidence": 0.95, "explanation": ""}
+	]}`
+
+	cmds, errs := decodeCommands(content)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := []string{"create", "create", "complete"}
+	if len(cmds) != len(expected) {
+		t.Fatalf("expected %d commands, got %d", len(expected), len(cmds))
+	}
+	for i, action := range expected {
+		if cmds[i].Action != action {
+			t.Errorf("command %d: expected %s, got %s", i, action, cmds[i].Action)
+		}
+	}
+	if cmds[1].Parameters["title"] != "eggs" || cmds[2].Parameters["todo_id"] != "2" {
+		t.Errorf("unexpected parameters: %v, %v", cmds[1].Parameters, cmds[2].Parameters)
+	}
+}
+
+func TestDecodeCommands_StepList(t *testing.T) {
+	content := `{"commands": [{"action": "breakdown", "parameters": {"todo_id": 4, "steps": ["1. Clear a path", "2. Sort tools", "3. Sweep"]}, "confidence": 0.9, "explanation": ""}]}`
+
+	cmds, errs := decodeCommands(content)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	steps := SplitSteps(cmds[0].Parameters["steps"])
+	if len(steps) != 3 || steps[0] != "Clear a path" || steps[2] != "Sweep" {
+		t.Errorf("expected the numbering dropped from 3 steps, got %q", steps)
+	}
+}
+
+func TestSplitSteps(t *testing.T) {
+	got := SplitSteps("1) Clear a path\n\n- Sort tools\n  * Sweep the floor  \n• Take out 2 bags")
+	want := []string{"Clear a path", "Sort tools", "Sweep the floor", "Take out 2 bags"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestDecodeCommands_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		field   string
+	}{
+		{"empty list", `{"commands": []}`, "commands"},
+		{"not a list", `{"commands": "create milk"}`, "commands"},
+		{"item not an object", `{"commands": [{"action": "stats", "confidence": 1}, "oops"]}`, "commands[1]"},
+		{"invalid second command", `{"commands": [{"action": "stats", "confidence": 1}, {"action": "create", "confidence": 1}]}`, "commands[1].parameters.title"},
+		{"too many", `{"commands": [` + strings.Repeat(`{"action": "stats", "confidence": 1},`, maxCommands) + `{"action": "stats", "confidence": 1}]}`, "commands"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := decodeCommands(tt.content)
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected a single error on %s, got %v", tt.field, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/domain"
+)
+
+// Periods a limit applies to
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// ErrNoPrice is returned for usage of a model Prices doesn't list
+var ErrNoPrice = errors.New("no price for model")
+
+// Price is what a model charges, in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Prices maps model names to what they charge
+type Prices map[string]Price
+
+// DefaultPrices are list prices for the providers' default models
+func DefaultPrices() Prices {
+	return Prices{
+		"gpt-5-mini":       {Input: 0.25, Output: 2.00},
+		"gpt-5-nano":       {Input: 0.05, Output: 0.40},
+		"gpt-5":            {Input: 1.25, Output: 10.00},
+		"claude-haiku-4-5": {Input: 1.00, Output: 5.00},
+		"fake":             {}, // The fake provider makes no calls
+	}
+}
+
+// ParsePrices reads a comma-separated list of model=input:output prices in
+// dollars per million tokens, e.g. "gpt-5-mini=0.25:2,llama3=0:0". Listed
+// models override the defaults.
+func ParsePrices(spec string) (Prices, error) {
+	prices := DefaultPrices()
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		model, value, ok := strings.Cut(pair, "=")
+		model = strings.TrimSpace(model)
+		input, output, ok2 := strings.Cut(value, ":")
+		if !ok || !ok2 || model == "" {
+			return nil, fmt.Errorf("expected model=input:output, got %q", pair)
+		}
+		in, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil || in < 0 {
+			return nil, fmt.Errorf("%s: invalid input price %q", model, input)
+		}
+		out, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+		if err != nil || out < 0 {
+			return nil, fmt.Errorf("%s: invalid output price %q", model, output)
+		}
+		prices[model] = Price{Input: in, Output: out}
+	}
+	return prices, nil
+}
+
+// Cost returns what the tokens cost in millionths of a dollar. Dated
+// snapshots such as gpt-5-mini-2025-08-07 are priced as their base model.
+// ok is false for a model with no price.
+func (p Prices) Cost(model string, promptTokens, completionTokens int64) (micros int64, ok bool) {
+	price, ok := p[model]
+	if !ok {
+		// Longest listed name the model starts with
+		names := make([]string, 0, len(p))
+		for name := range p {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		for _, name := range names {
+			if strings.HasPrefix(model, name+"-") {
+				price, ok = p[name], true
+				break
+			}
+		}
+		if !ok {
+			return 0, false
+		}
+	}
+	// Dollars per million tokens is the same as micro-dollars per token
+	return int64(math.Round(float64(promptTokens)*price.Input + float64(completionTokens)*price.Output)), true
+}
+
+// ParseDollars reads an amount like "0.50" or "$2" into millionths of a dollar
+func ParseDollars(value string) (int64, error) {
+	f, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(value), "$"), 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid amount %q: want dollars like 0.50", value)
+	}
+	return int64(math.Round(f * 1e6)), nil
+}
+
+// FormatDollars formats millionths of a dollar, e.g. "$0.0123"
+func FormatDollars(micros int64) string {
+	return fmt.Sprintf("$%.4f", float64(micros)/1e6)
+}
+
+// Limits caps what each user's LLM calls may cost, in millionths of a
+// dollar. 0 means no cap.
+type Limits struct {
+	Daily   int64
+	Monthly int64
+}
+
+// Store keeps usage records. *domain.Client in production.
+type Store interface {
+	RecordLLMUsage(ctx context.Context, usage *todov1.LLMUsage, idempotencyKey string) error
+	GetLLMUsage(ctx context.Context, filter domain.LLMUsageFilter) ([]*todov1.LLMUsage, error)
+}
+
+// Tracker prices each message's LLM usage, records it, and tells when a user
+// has spent their budget
+type Tracker struct {
+	store  Store
+	prices Prices
+	limits Limits
+	now    func() time.Time
+}
+
+// New creates a Tracker
+func New(store Store, prices Prices, limits Limits) *Tracker {
+	if prices == nil {
+		prices = DefaultPrices()
+	}
+	return &Tracker{store: store, prices: prices, limits: limits, now: time.Now}
+}
+
+// Limits returns the budgets in effect
+func (t *Tracker) Limits() Limits {
+	return t.limits
+}
+
+// Spent returns what a user's LLM calls have cost today and this month, with
+// days and months starting at midnight in loc
+func (t *Tracker) Spent(ctx context.Context, userID string, loc *time.Location) (today, month int64, err error) {
+	now := t.now().In(loc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	usage, err := t.store.GetLLMUsage(ctx, domain.LLMUsageFilter{UserID: userID, Since: &monthStart, Timezone: loc.String()})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	date := now.Format("2006-01-02")
+	for _, u := range usage {
+		month += u.CostMicros
+		if u.Date == date {
+			today += u.CostMicros
+		}
+	}
+	return today, month, nil
+}
+
+// Exceeded returns the period whose budget the user has used up, or "" if
+// they can still use the LLM. Without limits nothing is looked up.
+func (t *Tracker) Exceeded(ctx context.Context, userID string, loc *time.Location) (string, error) {
+	if t.limits.Daily == 0 && t.limits.Monthly == 0 {
+		return "", nil
+	}
+	today, month, err := t.Spent(ctx, userID, loc)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case t.limits.Monthly > 0 && month >= t.limits.Monthly:
+		return PeriodMonth, nil
+	case t.limits.Daily > 0 && today >= t.limits.Daily:
+		return PeriodDay, nil
+	}
+	return "", nil
+}
+
+// Record prices and stores the usage counted for one message, returning its
+// cost. Messages that made no calls aren't recorded. Usage of a model with no
+// price is still recorded, at no cost, and returned with ErrNoPrice.
+func (t *Tracker) Record(ctx context.Context, userID, idempotencyKey string, usage *ai.Usage) (int64, error) {
+	if usage.Calls() == 0 {
+		return 0, nil
+	}
+
+	model := usage.Model()
+	promptTokens, completionTokens := usage.Tokens()
+	cost, priced := t.prices.Cost(model, promptTokens, completionTokens)
+
+	err := t.store.RecordLLMUsage(ctx, &todov1.LLMUsage{
+		UserId:           userID,
+		Model:            model,
+		Calls:            int32(usage.Calls()),
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		CostMicros:       cost,
+	}, idempotencyKey)
+	if err != nil {
+		return 0, err
+	}
+	if !priced {
+		return 0, fmt.Errorf("%w: %s", ErrNoPrice, model)
+	}
+	return cost, nil
+}
@@ -0,0 +1,223 @@
+package budget
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/domain"
+)
+
+// fakeStore keeps usage in memory, returning the summaries it's given
+type fakeStore struct {
+	recorded   []*todov1.LLMUsage
+	keys       []string
+	summary    []*todov1.LLMUsage
+	lastFilter domain.LLMUsageFilter
+	err        error
+}
+
+func (f *fakeStore) RecordLLMUsage(ctx context.Context, usage *todov1.LLMUsage, idempotencyKey string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.recorded = append(f.recorded, usage)
+	f.keys = append(f.keys, idempotencyKey)
+	return nil
+}
+
+func (f *fakeStore) GetLLMUsage(ctx context.Context, filter domain.LLMUsageFilter) ([]*todov1.LLMUsage, error) {
+	f.lastFilter = filter
+	return f.summary, f.err
+}
+
+// =============================================================================
+// Pricing Tests
+// =============================================================================
+
+func TestParsePrices(t *testing.T) {
+	prices, err := ParsePrices("gpt-5-mini=0.5:4, llama3=0:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prices["gpt-5-mini"] != (Price{Input: 0.5, Output: 4}) {
+		t.Errorf("expected the override to win, got %+v", prices["gpt-5-mini"])
+	}
+	if _, ok := prices["llama3"]; !ok {
+		t.Error("expected llama3 to be added")
+	}
+	if prices["claude-haiku-4-5"] != DefaultPrices()["claude-haiku-4-5"] {
+		t.Error("expected unlisted models to keep their defaults")
+	}
+
+	for _, spec := range []string{"gpt-5-mini", "gpt-5-mini=0.5", "=1:2", "gpt-5-mini=cheap:2", "gpt-5-mini=1:-2"} {
+		if _, err := ParsePrices(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestPrices_Cost(t *testing.T) {
+	prices := Prices{"gpt-5-mini": {Input: 0.25, Output: 2}, "gpt-5": {Input: 1.25, Output: 10}}
+
+	tests := []struct {
+		model string
+		cost  int64
+		ok    bool
+	}{
+		{"gpt-5-mini", 1000*0.25 + 100*2, true},
+		{"gpt-5-mini-2025-08-07", 1000*0.25 + 100*2, true}, // Dated snapshot
+		{"gpt-5", 1000*1.25 + 100*10, true},
+		{"gpt-5-2025-08-07", 1000*1.25 + 100*10, true},
+		{"llama3", 0, false},
+	}
+	for _, tt := range tests {
+		cost, ok := prices.Cost(tt.model, 1000, 100)
+		if cost != tt.cost || ok != tt.ok {
+			t.Errorf("Cost(%s) = %d, %v; expected %d, %v", tt.model, cost, ok, tt.cost, tt.ok)
+		}
+	}
+}
+
+func TestParseDollars(t *testing.T) {
+	tests := []struct {
+		value  string
+		micros int64
+		ok     bool
+	}{
+		{"0.05", 50000, true},
+		{"$2", 2000000, true},
+		{" 1.5 ", 1500000, true},
+		{"-1", 0, false},
+		{"lots", 0, false},
+	}
+	for _, tt := range tests {
+		micros, err := ParseDollars(tt.value)
+		if micros != tt.micros || (err == nil) != tt.ok {
+			t.Errorf("ParseDollars(%q) = %d, %v", tt.value, micros, err)
+		}
+	}
+	if got := FormatDollars(12345); got != "$0.0123" {
+		t.Errorf("expected $0.0123, got %s", got)
+	}
+}
+
+// =============================================================================
+// Tracker Tests
+// =============================================================================
+
+func TestTracker_Exceeded(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	// 23:30 on the 20th in Chicago is already the 21st in UTC
+	now := time.Date(2026, 1, 21, 5, 30, 0, 0, time.UTC)
+	store := &fakeStore{summary: []*todov1.LLMUsage{
+		{Date: "2026-01-02", Model: "gpt-5-mini", CostMicros: 600000},
+		{Date: "2026-01-20", Model: "gpt-5-mini", CostMicros: 40000},
+		{Date: "2026-01-20", Model: "gpt-5", CostMicros: 15000},
+	}}
+
+	tests := []struct {
+		name   string
+		limits Limits
+		want   string
+	}{
+		{"no limits", Limits{}, ""},
+		{"under both", Limits{Daily: 100000, Monthly: 1000000}, ""},
+		{"daily spent", Limits{Daily: 50000, Monthly: 1000000}, PeriodDay},
+		{"monthly spent", Limits{Daily: 100000, Monthly: 600000}, PeriodMonth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := New(store, nil, tt.limits)
+			tracker.now = func() time.Time { return now }
+			got, err := tracker.Exceeded(context.Background(), "+15551234567", chicago)
+			if err != nil || got != tt.want {
+				t.Errorf("expected %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+
+	since := store.lastFilter.Since
+	if since == nil || !since.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, chicago)) || store.lastFilter.Timezone != "America/Chicago" {
+		t.Errorf("expected usage since the start of the month in Chicago, got %+v", store.lastFilter)
+	}
+}
+
+func TestTracker_ExceededLookupFails(t *testing.T) {
+	tracker := New(&fakeStore{err: errors.New("unavailable")}, nil, Limits{Daily: 1})
+	if _, err := tracker.Exceeded(context.Background(), "+15551234567", time.UTC); err == nil {
+		t.Error("expected the lookup error")
+	}
+}
+
+func TestTracker_Record(t *testing.T) {
+	store := &fakeStore{}
+	tracker := New(store, Prices{"gpt-5-mini": {Input: 0.25, Output: 2}}, Limits{})
+
+	// Nothing is recorded for a message the rules handled
+	if _, err := tracker.Record(context.Background(), "+15551234567", "msg-1", &ai.Usage{}); err != nil || len(store.recorded) != 0 {
+		t.Fatalf("expected nothing recorded, got %v (%v)", store.recorded, err)
+	}
+
+	// A repaired reply is two calls
+	usage := &ai.Usage{}
+	usage.Add("gpt-5-mini", 800, 50)
+	usage.Add("gpt-5-mini", 900, 40)
+	cost, err := tracker.Record(context.Background(), "+15551234567", "msg-2", usage)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := int64(1700*0.25 + 90*2); cost != want {
+		t.Errorf("expected cost %d, got %d", want, cost)
+	}
+	got := store.recorded[0]
+	if got.UserId != "+15551234567" || got.Model != "gpt-5-mini" || got.Calls != 2 ||
+		got.PromptTokens != 1700 || got.CompletionTokens != 90 || got.CostMicros != cost || store.keys[0] != "msg-2" {
+		t.Errorf("unexpected record %v under key %s", got, store.keys[0])
+	}
+
+	// An unpriced model is still recorded, and reported
+	usage = &ai.Usage{}
+	usage.Add("llama3", 500, 20)
+	if cost, err := tracker.Record(context.Background(), "+15551234567", "msg-3", usage); !errors.Is(err, ErrNoPrice) || cost != 0 || len(store.recorded) != 2 {
+		t.Errorf("expected a free record and ErrNoPrice, got %d (%v)", cost, err)
+	}
+}
+
+// =============================================================================
+// Report Tests
+// =============================================================================
+
+func TestWriteReport(t *testing.T) {
+	usage := []*todov1.LLMUsage{
+		{UserId: "+15550001111", Date: "2026-01-19", Model: "gpt-5-mini", Calls: 2, PromptTokens: 1600, CompletionTokens: 100, CostMicros: 600},
+		{UserId: "+15550001111", Date: "2026-01-20", Model: "gpt-5-mini", Calls: 1, PromptTokens: 800, CompletionTokens: 50, CostMicros: 300},
+		{UserId: "+15550002222", Date: "2026-01-20", Model: "claude-haiku-4-5", Calls: 1, PromptTokens: 900, CompletionTokens: 60, CostMicros: 1200},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, usage, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header, two users and a total, got:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "+15550001111") || !strings.Contains(lines[1], "$0.0009") {
+		t.Errorf("expected the first user's total, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], "TOTAL") || !strings.Contains(lines[3], "3300") || !strings.Contains(lines[3], "$0.0021") {
+		t.Errorf("expected the grand total, got %q", lines[3])
+	}
+
+	buf.Reset()
+	WriteReport(&buf, usage, true)
+	if n := strings.Count(buf.String(), "\n"); n != 7 {
+		t.Errorf("expected a row per day and model as well, got:\n%s", buf.String())
+	}
+}
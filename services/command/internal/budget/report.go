@@ -0,0 +1,43 @@
+package budget
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	todov1 "hound-todo/api/todo/v1"
+)
+
+// WriteReport writes usage as a table with a total per user and overall.
+// Usage must be ordered by user, as GetLLMUsage returns it. With byDay every
+// day and model gets its own row under the user.
+func WriteReport(w io.Writer, usage []*todov1.LLMUsage, byDay bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER\tDATE\tMODEL\tCALLS\tPROMPT TOKENS\tCOMPLETION TOKENS\tCOST\t")
+
+	var user *todov1.LLMUsage
+	grand := &todov1.LLMUsage{}
+	flush := func() {
+		if user != nil {
+			fmt.Fprintf(tw, "%s\t\t\t%d\t%d\t%d\t%s\t\n", user.UserId, user.Calls, user.PromptTokens, user.CompletionTokens, FormatDollars(user.CostMicros))
+		}
+	}
+	for _, u := range usage {
+		if user == nil || u.UserId != user.UserId {
+			flush()
+			user = &todov1.LLMUsage{UserId: u.UserId}
+		}
+		if byDay {
+			fmt.Fprintf(tw, "\t%s\t%s\t%d\t%d\t%d\t%s\t\n", u.Date, u.Model, u.Calls, u.PromptTokens, u.CompletionTokens, FormatDollars(u.CostMicros))
+		}
+		for _, total := range []*todov1.LLMUsage{user, grand} {
+			total.Calls += u.Calls
+			total.PromptTokens += u.PromptTokens
+			total.CompletionTokens += u.CompletionTokens
+			total.CostMicros += u.CostMicros
+		}
+	}
+	flush()
+	fmt.Fprintf(tw, "TOTAL\t\t\t%d\t%d\t%d\t%s\t\n", grand.Calls, grand.PromptTokens, grand.CompletionTokens, FormatDollars(grand.CostMicros))
+	return tw.Flush()
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// Defaults for the cache bounds
+const (
+	DefaultSize = 1000
+	DefaultTTL  = 24 * time.Hour
+)
+
+// contextParams are parameters the LLM fills in from the prompt's context
+// rather than the message: todo ids from the active list or conversation,
+// and dates worked out from the current time
+var contextParams = []string{"todo_id", "completed_after", "completed_before"}
+
+// entry is one cached parse
+type entry struct {
+	key     string
+	cmds    []*ai.Command
+	expires time.Time
+}
+
+// Cache remembers what context-free messages parsed to, so the same text
+// ("list", "what's on my list?") doesn't go to the LLM every time. Entries
+// are keyed on the normalized text, the prompt version and the model, so a
+// prompt or model change starts afresh. Least recently used entries are
+// evicted past the size bound, and entries expire after the TTL.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	prefix  string // Prompt version and model
+	entries map[string]*list.Element
+	lru     *list.List // Most recently used first
+	now     func() time.Time
+
+	hits     atomic.Int64
+	misses   atomic.Int64
+	bypassed atomic.Int64
+}
+
+// New creates a cache for parses by model, holding up to size entries for
+// ttl each. Zero values keep the defaults.
+func New(model string, size int, ttl time.Duration) *Cache {
+	if size < 1 {
+		size = DefaultSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		prefix:  ai.PromptVersion() + "\x00" + model + "\x00",
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// Normalize reduces a message to the form it's cached under: lower case,
+// single spaces, no apostrophes or closing punctuation
+func Normalize(text string) string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("'", "", "’", "").Replace(text)
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.TrimRight(text, ".!? ")
+}
+
+// contextFree reports whether a request's reply can only depend on the
+// message itself. Follow-ups need the conversation, so they're never cached.
+func contextFree(req *ai.Request) bool {
+	return req.History == nil || len(req.History.Turns) == 0
+}
+
+// cacheable reports whether a parse holds nothing worked out from the todo
+// list, conversation or clock, and so would be the same next time
+func cacheable(cmds []*ai.Command) bool {
+	if len(cmds) == 0 {
+		return false
+	}
+	for _, cmd := range cmds {
+		if cmd.Action == "unclear" {
+			// Often a failed repair; worth asking again
+			return false
+		}
+		for _, param := range contextParams {
+			if cmd.Parameters[param] != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Get returns a copy of the commands cached for a request
+func (c *Cache) Get(req *ai.Request) ([]*ai.Command, bool) {
+	if !contextFree(req) {
+		c.bypassed.Add(1)
+		return nil, false
+	}
+	key := c.prefix + Normalize(req.Message)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if ok && c.now().After(el.Value.(*entry).expires) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	c.lru.MoveToFront(el)
+	return clone(el.Value.(*entry).cmds), true
+}
+
+// Put caches what a request parsed to, if neither depends on context
+func (c *Cache) Put(req *ai.Request, cmds []*ai.Command) {
+	if !contextFree(req) || !cacheable(cmds) {
+		return
+	}
+	key := c.prefix + Normalize(req.Message)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&entry{key: key, cmds: clone(cmds), expires: c.now().Add(c.ttl)})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
+
+// clone deep-copies commands, which the handler edits as it resolves them
+func clone(cmds []*ai.Command) []*ai.Command {
+	out := make([]*ai.Command, len(cmds))
+	for i, cmd := range cmds {
+		cp := *cmd
+		cp.Parameters = make(map[string]string, len(cmd.Parameters))
+		for k, v := range cmd.Parameters {
+			cp.Parameters[k] = v
+		}
+		out[i] = &cp
+	}
+	return out
+}
+
+// Stats is a snapshot of the cache's counters
+type Stats struct {
+	Hits     int64 // Messages answered from the cache
+	Misses   int64 // Context-free messages sent to the LLM
+	Bypassed int64 // Messages with conversation context, never cached
+	Entries  int
+}
+
+// Stats returns the current counters
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Bypassed: c.bypassed.Load(), Entries: entries}
+}
+
+// HitRate returns the fraction of context-free messages answered from the cache
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d bypassed=%d entries=%d hit_rate=%.1f%%",
+		s.Hits, s.Misses, s.Bypassed, s.Entries, s.HitRate()*100)
+}
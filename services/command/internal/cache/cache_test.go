@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+func listCommand() []*ai.Command {
+	return []*ai.Command{{Action: "list", Parameters: map[string]string{"filter": "active"}, Confidence: 0.95}}
+}
+
+// =============================================================================
+// Normalize Tests
+// =============================================================================
+
+func TestNormalize(t *testing.T) {
+	want := Normalize("whats on my list")
+	for _, text := range []string{"What's on my list?", "  what’s   on my LIST?! ", "whats on my list."} {
+		if got := Normalize(text); got != want {
+			t.Errorf("Normalize(%q) = %q, expected %q", text, got, want)
+		}
+	}
+	if Normalize("show todos") == Normalize("show todo") {
+		t.Error("expected different words to stay different")
+	}
+}
+
+// =============================================================================
+// Get and Put Tests
+// =============================================================================
+
+func TestCache_HitsRepeatedPhrasing(t *testing.T) {
+	c := New("gpt-5-mini", 10, time.Hour)
+
+	if _, ok := c.Get(ai.NewRequest("What's on my list?")); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	c.Put(ai.NewRequest("What's on my list?"), listCommand())
+
+	cmds, ok := c.Get(ai.NewRequest("whats on my list"))
+	if !ok || len(cmds) != 1 || cmds[0].Action != "list" {
+		t.Fatalf("expected the cached list command, got %v", cmds)
+	}
+
+	// Callers edit commands in place; the cached copy must not change
+	cmds[0].Parameters["todo_id"] = "3"
+	again, _ := c.Get(ai.NewRequest("whats on my list"))
+	if again[0].Parameters["todo_id"] != "" {
+		t.Error("expected the cache to hand out copies")
+	}
+
+	st := c.Stats()
+	if st.Hits != 2 || st.Misses != 1 || st.Entries != 1 || st.HitRate() < 0.66 {
+		t.Errorf("unexpected stats: %s", st)
+	}
+}
+
+func TestCache_OnlyContextFree(t *testing.T) {
+	c := New("gpt-5-mini", 10, time.Hour)
+
+	// A follow-up depends on the conversation
+	followUp := &ai.Request{Message: "list", History: &ai.History{Turns: []ai.Turn{{Message: "add milk", Reply: "Added #1: milk"}}}}
+	c.Put(followUp, listCommand())
+	if _, ok := c.Get(followUp); ok {
+		t.Error("expected messages with a conversation never to be cached")
+	}
+	if _, ok := c.Get(ai.NewRequest("list")); ok {
+		t.Error("expected nothing stored from a follow-up")
+	}
+	if st := c.Stats(); st.Bypassed != 1 || st.Misses != 1 {
+		t.Errorf("expected the follow-up to bypass the cache, got %s", st)
+	}
+
+	// Parses that used the todo list, the clock or failed aren't kept
+	uncacheable := map[string][]*ai.Command{
+		"finished the groceries":  {{Action: "complete", Parameters: map[string]string{"todo_id": "2"}}},
+		"what did I do yesterday": {{Action: "list", Parameters: map[string]string{"filter": "completed", "completed_after": "2026-01-19T00:00:00Z"}}},
+		"asdfgh":                  {{Action: "unclear", Parameters: map[string]string{"reason": "gibberish"}}},
+		"nothing":                 {},
+	}
+	for message, cmds := range uncacheable {
+		c.Put(ai.NewRequest(message), cmds)
+		if _, ok := c.Get(ai.NewRequest(message)); ok {
+			t.Errorf("expected %q not to be cached", message)
+		}
+	}
+}
+
+func TestCache_KeyedOnModel(t *testing.T) {
+	mini := New("gpt-5-mini", 10, time.Hour)
+	nano := New("gpt-5-nano", 10, time.Hour)
+	if mini.prefix == nano.prefix {
+		t.Fatal("expected the model to be part of the key")
+	}
+	if mini.prefix[:len(ai.PromptVersion())] != ai.PromptVersion() {
+		t.Error("expected the prompt version to be part of the key")
+	}
+}
+
+func TestCache_Bounds(t *testing.T) {
+	now := time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+	c := New("gpt-5-mini", 2, time.Hour)
+	c.now = func() time.Time { return now }
+
+	c.Put(ai.NewRequest("list"), listCommand())
+	c.Put(ai.NewRequest("show todos"), listCommand())
+	c.Get(ai.NewRequest("list")) // "show todos" is now least recently used
+	c.Put(ai.NewRequest("my todos"), listCommand())
+
+	if _, ok := c.Get(ai.NewRequest("show todos")); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok := c.Get(ai.NewRequest("list")); !ok {
+		t.Error("expected the recently used entry to stay")
+	}
+	if c.Stats().Entries != 2 {
+		t.Errorf("expected 2 entries, got %s", c.Stats())
+	}
+
+	now = now.Add(time.Hour + time.Second)
+	if _, ok := c.Get(ai.NewRequest("list")); ok {
+		t.Error("expected the entry to expire after the TTL")
+	}
+	if c.Stats().Entries != 1 {
+		t.Errorf("expected the expired entry to be dropped, got %s", c.Stats())
+	}
+}
@@ -0,0 +1,316 @@
+// Package cassette records LLM API calls to a file and replays them, so code
+// that talks to a provider can be tested offline and deterministically
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mode is whether a cassette calls the provider or only plays back
+type Mode int
+
+const (
+	// ModeReplay answers from the file and fails on anything not recorded
+	ModeReplay Mode = iota
+	// ModeRecord calls the provider and adds every exchange to the file
+	ModeRecord
+)
+
+// Match is what two requests must share to be the same exchange
+type Match int
+
+const (
+	// MatchPromptAndMessage compares the system prompt and the conversation
+	MatchPromptAndMessage Match = iota
+	// MatchMessage compares only the conversation, so a cassette survives
+	// prompt edits. Prompt regressions are the eval harness's job.
+	MatchMessage
+)
+
+const redacted = "REDACTED"
+
+// Headers that carry credentials and never reach the file
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Api-Key", "Cookie"}
+
+// Timestamps change on every run, so they are blanked before matching. The
+// weekday the prompt shows after one goes with it.
+var timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})( \([A-Za-z]+\))?`)
+
+// Interaction is one recorded request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request, with credentials scrubbed
+type Request struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// Response is a recorded response
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// file is the on-disk format
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Transport is an http.RoundTripper backed by a cassette file
+type Transport struct {
+	path  string
+	mode  Mode
+	match Match
+	real  http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	played       map[string]int // Times each key has been replayed
+}
+
+// New opens a cassette. In replay mode the file must exist; in record mode
+// it is started afresh and real carries the requests, http.DefaultTransport if nil.
+func New(path string, mode Mode, match Match, real http.RoundTripper) (*Transport, error) {
+	t := &Transport{path: path, mode: mode, match: match, real: real, played: make(map[string]int)}
+	if t.real == nil {
+		t.real = http.DefaultTransport
+	}
+	if mode == ModeRecord {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	t.interactions = f.Interactions
+	return t, nil
+}
+
+// RoundTrip replays or records one request
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+	key := Key(body, t.match)
+
+	if t.mode == ModeReplay {
+		return t.replay(req, key)
+	}
+	return t.record(req, body)
+}
+
+// replay answers with the recorded response. A request made several times
+// gets its recordings in order, then the last one again.
+func (t *Transport) replay(req *http.Request, key string) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var matches []Interaction
+	for _, in := range t.interactions {
+		if Key(in.Request.Body, t.match) == key {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("cassette %s has no recording for this request, record it again", t.path)
+	}
+
+	i := t.played[key]
+	if i >= len(matches) {
+		i = len(matches) - 1
+	}
+	t.played[key]++
+	return matches[i].Response.toHTTP(req), nil
+}
+
+// record sends the request on and keeps a scrubbed copy of the exchange
+func (t *Transport) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := t.real.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: scrub(req.Header),
+			Body:    rawJSON(body),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: map[string]string{"Content-Type": resp.Header.Get("Content-Type")},
+			Body:    rawJSON(respBody),
+		},
+	}
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, in)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded exchanges. It does nothing in replay mode.
+func (t *Transport) Save() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+
+	t.mu.Lock()
+	data, err := json.MarshalIndent(file{Interactions: t.interactions}, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return os.WriteFile(t.path, append(data, '\n'), 0644)
+}
+
+// Key identifies a request by its normalized prompt and messages. It
+// understands the OpenAI and Anthropic request formats; any other body is
+// matched on its whitespace-normalized text.
+func Key(body []byte, match Match) string {
+	var req struct {
+		System   string `json:"system"` // Anthropic
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+
+	var b strings.Builder
+	if err := json.Unmarshal(body, &req); err != nil || len(req.Messages) == 0 {
+		b.WriteString(normalize(string(body)))
+	} else {
+		if match == MatchPromptAndMessage {
+			b.WriteString("system: " + normalize(req.System) + "\n")
+		}
+		for _, m := range req.Messages {
+			if m.Role == "system" {
+				// OpenAI sends the prompt as the first message
+				if match == MatchPromptAndMessage {
+					b.WriteString("system: " + normalize(contentText(m.Content)) + "\n")
+				}
+				continue
+			}
+			b.WriteString(m.Role + ": " + normalize(contentText(m.Content)) + "\n")
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentText is a message's text: the string itself, or content blocks re-encoded compactly
+func contentText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// normalize blanks timestamps and collapses whitespace
+func normalize(s string) string {
+	s = timestampPattern.ReplaceAllString(s, "<time>")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// scrub copies headers with credentials replaced
+func scrub(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name := range h {
+		out[name] = h.Get(name)
+	}
+	for _, name := range secretHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out[http.CanonicalHeaderKey(name)] = redacted
+		}
+	}
+	return out
+}
+
+// rawJSON keeps a JSON body as is, so cassettes stay readable, and quotes anything else
+func rawJSON(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, body); err == nil {
+			return buf.Bytes()
+		}
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+// toHTTP builds the response to hand back to the client
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	body := []byte(r.Body)
+	var s string
+	if err := json.Unmarshal(r.Body, &s); err == nil {
+		body = []byte(s) // Recorded as a quoted string
+	}
+
+	header := make(http.Header)
+	for name, value := range r.Headers {
+		header.Set(name, value)
+	}
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
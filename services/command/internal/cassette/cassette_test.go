@@ -0,0 +1,161 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openAIBody(system, message string) string {
+	return `{"model":"gpt-5-mini","messages":[{"role":"system","content":"` + system + `"},{"role":"user","content":"` + message + `"}]}`
+}
+
+func post(t *testing.T, rt http.RoundTripper, url, body string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if json.Valid(data) {
+		// Cassettes are indented on disk, so compare JSON compactly
+		var buf bytes.Buffer
+		json.Compact(&buf, data)
+		data = buf.Bytes()
+	}
+	return resp.StatusCode, string(data)
+}
+
+// =============================================================================
+// Key Tests
+// =============================================================================
+
+func TestKey(t *testing.T) {
+	base := Key([]byte(openAIBody("Current time: 2026-01-20T10:00:00-05:00 (Tuesday)", "list")), MatchPromptAndMessage)
+
+	tests := []struct {
+		name  string
+		body  string
+		match Match
+		same  bool
+	}{
+		{"different time", openAIBody("Current time: 2026-03-01T08:30:00Z (Sunday)", "list"), MatchPromptAndMessage, true},
+		{"extra whitespace", openAIBody("Current  time:   2026-01-20T10:00:00-05:00 (Tuesday)", " list "), MatchPromptAndMessage, true},
+		{"different message", openAIBody("Current time: 2026-01-20T10:00:00-05:00 (Tuesday)", "stats"), MatchPromptAndMessage, false},
+		{"different prompt", openAIBody("New prompt. Current time: 2026-01-20T10:00:00-05:00 (Tuesday)", "list"), MatchPromptAndMessage, false},
+		{"different model", strings.Replace(openAIBody("Current time: 2026-01-20T10:00:00-05:00 (Tuesday)", "list"), "gpt-5-mini", "gpt-5-nano", 1), MatchPromptAndMessage, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key([]byte(tt.body), tt.match) == base; got != tt.same {
+				t.Errorf("expected same key %v, got %v", tt.same, got)
+			}
+		})
+	}
+
+	// Matching on the message alone ignores prompt changes
+	a := Key([]byte(openAIBody("old prompt", "list")), MatchMessage)
+	b := Key([]byte(openAIBody("new prompt", "list")), MatchMessage)
+	if a != b {
+		t.Error("expected MatchMessage to ignore the prompt")
+	}
+
+	// Anthropic puts the prompt outside the messages
+	c := Key([]byte(`{"system":"old prompt","messages":[{"role":"user","content":"list"}]}`), MatchMessage)
+	if c != a {
+		t.Error("expected the same conversation to match across providers")
+	}
+}
+
+// =============================================================================
+// Record and Replay Tests
+// =============================================================================
+
+func TestRecordThenReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), `"content":"list"`) {
+			w.Write([]byte(`{"reply": "list"}`))
+			return
+		}
+		w.Write([]byte(`{"reply": "other"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+	rec, err := New(path, ModeRecord, MatchPromptAndMessage, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, body := post(t, rec, server.URL, openAIBody("prompt 2026-01-20T10:00:00Z", "list")); body != `{"reply":"list"}` {
+		t.Errorf("expected the live response while recording, got %s", body)
+	}
+	post(t, rec, server.URL, openAIBody("prompt", "stats"))
+	if err := rec.Save(); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-secret") || !strings.Contains(string(data), redacted) {
+		t.Errorf("expected the API key to be scrubbed, got %s", data)
+	}
+
+	play, err := New(path, ModeReplay, MatchPromptAndMessage, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status, body := post(t, play, "http://unreachable.invalid", openAIBody("prompt 2026-05-05T05:05:05Z", "list"))
+	if status != http.StatusOK || body != `{"reply":"list"}` {
+		t.Errorf("expected the recorded response, got %d %s", status, body)
+	}
+	if calls != 2 {
+		t.Errorf("expected replay not to reach the server, got %d calls", calls)
+	}
+
+	req, _ := http.NewRequest("POST", "http://unreachable.invalid", strings.NewReader(openAIBody("prompt", "delete everything")))
+	if _, err := play.RoundTrip(req); err == nil {
+		t.Error("expected an error for a request that was never recorded")
+	}
+}
+
+func TestReplay_RepeatedRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repeat.json")
+	os.WriteFile(path, []byte(`{"interactions": [
+		{"request": {"method": "POST", "url": "x", "body": {"messages": [{"role": "user", "content": "hi"}]}}, "response": {"status": 200, "body": {"n": 1}}},
+		{"request": {"method": "POST", "url": "x", "body": {"messages": [{"role": "user", "content": "hi"}]}}, "response": {"status": 500, "body": "overloaded"}}
+	]}`), 0644)
+
+	play, err := New(path, ModeReplay, MatchMessage, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body := `{"messages": [{"role": "user", "content": "hi"}]}`
+	if status, got := post(t, play, "http://x", body); status != 200 || got != `{"n":1}` {
+		t.Errorf("expected the first recording, got %d %s", status, got)
+	}
+	for i := 0; i < 2; i++ {
+		if status, got := post(t, play, "http://x", body); status != 500 || got != "overloaded" {
+			t.Errorf("expected the last recording again, got %d %s", status, got)
+		}
+	}
+}
+
+func TestNew_MissingCassette(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, MatchMessage, nil); err == nil {
+		t.Error("expected an error replaying a cassette that doesn't exist")
+	}
+}
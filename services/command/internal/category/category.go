@@ -0,0 +1,159 @@
+package category
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Category is a kind of todo and the words that give a title away as one
+type Category struct {
+	Name     string
+	Keywords []string // Words or phrases, lowercase
+}
+
+// Categories is every category a todo can be sorted into. Order breaks ties:
+// "call the dentist" is a call before it's health.
+type Categories []Category
+
+// Defaults returns the categories used when none are configured
+func Defaults() Categories {
+	return Categories{
+		{Name: "errands", Keywords: []string{
+			"buy", "pick up", "drop off", "grocery", "groceries", "store", "shop", "shopping",
+			"pharmacy", "post office", "mail", "package", "return", "bank", "dry cleaning",
+			"milk", "eggs", "bread", "stamps",
+		}},
+		{Name: "calls", Keywords: []string{"call", "phone", "ring", "dial", "voicemail", "call back"}},
+		{Name: "home", Keywords: []string{
+			"clean", "tidy", "laundry", "dishes", "vacuum", "garage", "garden", "mow", "lawn",
+			"fix", "repair", "trash", "cook", "dinner", "plants", "kitchen", "bathroom",
+		}},
+		{Name: "work", Keywords: []string{
+			"report", "meeting", "email", "deck", "presentation", "slides", "client", "boss",
+			"manager", "invoice", "deadline", "project", "proposal", "review", "standup",
+		}},
+		{Name: "health", Keywords: []string{
+			"dentist", "doctor", "gym", "workout", "run", "exercise", "meds", "medicine", "pills",
+			"prescription", "therapy", "yoga", "vitamins", "physio", "checkup",
+		}},
+	}
+}
+
+// Parse reads a comma-separated list of name=keyword|keyword categories, e.g.
+// "garden=mow|weed|plant,errands=post office". A listed default gets the extra
+// keywords; new categories come first, so they win ties with the defaults.
+func Parse(spec string) (Categories, error) {
+	categories := Defaults()
+	var added Categories
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=keyword|keyword, got %q", pair)
+		}
+		var keywords []string
+		for _, keyword := range strings.Split(value, "|") {
+			if keyword = strings.Join(words(keyword), " "); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+		if len(keywords) == 0 {
+			return nil, fmt.Errorf("%s: needs at least one keyword", name)
+		}
+
+		if i := categories.index(name); i >= 0 {
+			categories[i].Keywords = append(categories[i].Keywords, keywords...)
+		} else if i := added.index(name); i >= 0 {
+			added[i].Keywords = append(added[i].Keywords, keywords...)
+		} else {
+			added = append(added, Category{Name: name, Keywords: keywords})
+		}
+	}
+	return append(added, categories...), nil
+}
+
+// Classify returns the category whose keywords a title mentions most, or ""
+// if it mentions none
+func (c Categories) Classify(title string) string {
+	titleWords := words(title)
+
+	best, bestHits := "", 0
+	for _, category := range c {
+		hits := 0
+		for _, keyword := range category.Keywords {
+			if mentions(titleWords, strings.Fields(keyword)) {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = category.Name, hits
+		}
+	}
+	return best
+}
+
+// Lookup returns the category a name refers to, allowing for case and a
+// plural: "Errand" is errands. ok is false if there is no such category.
+func (c Categories) Lookup(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, category := range c {
+		if name == category.Name || name+"s" == category.Name || name == category.Name+"s" {
+			return category.Name, true
+		}
+	}
+	return "", false
+}
+
+// Names returns every category name, in order
+func (c Categories) Names() []string {
+	names := make([]string, len(c))
+	for i, category := range c {
+		names[i] = category.Name
+	}
+	return names
+}
+
+func (c Categories) index(name string) int {
+	for i, category := range c {
+		if category.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// mentions reports whether the keyword's words appear in a row in the title,
+// each as written or with a plural ending
+func mentions(title, keyword []string) bool {
+	if len(keyword) == 0 {
+		return false
+	}
+	for start := 0; start+len(keyword) <= len(title); start++ {
+		found := true
+		for i, word := range keyword {
+			if !sameWord(title[start+i], word) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func sameWord(word, keyword string) bool {
+	return word == keyword || word == keyword+"s" || word == keyword+"es"
+}
+
+// words lowercases text and splits it into words, dropping punctuation
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
@@ -0,0 +1,104 @@
+package category
+
+import (
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Classify Tests
+// =============================================================================
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"buy milk", "errands"},
+		{"Pick up the dry cleaning", "errands"},
+		{"call mom", "calls"},
+		{"call the dentist", "calls"},
+		{"book a dentist checkup", "health"},
+		{"water the plants", "home"},
+		{"finish the quarterly report for my boss", "work"},
+		{"send invoices", "work"},
+		{"think about life", ""},
+		{"", ""},
+	}
+
+	categories := Defaults()
+	for _, tt := range tests {
+		if got := categories.Classify(tt.title); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestClassify_PhraseKeywords(t *testing.T) {
+	categories := Categories{{Name: "errands", Keywords: []string{"post office"}}}
+
+	if got := categories.Classify("go to the post office"); got != "errands" {
+		t.Errorf("expected the phrase to match, got %q", got)
+	}
+	if got := categories.Classify("office party, post photos"); got != "" {
+		t.Errorf("expected the words apart not to match, got %q", got)
+	}
+}
+
+// =============================================================================
+// Parse Tests
+// =============================================================================
+
+func TestParse(t *testing.T) {
+	categories, err := Parse("Garden=mow|Weed, errands=Post  Office")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "garden,errands,calls,home,work,health"
+	if got := strings.Join(categories.Names(), ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	// The new category wins ties with home, which has mow too
+	if got := categories.Classify("mow the lawn and weed"); got != "garden" {
+		t.Errorf("expected garden, got %q", got)
+	}
+	if got := categories.Classify("mow"); got != "garden" {
+		t.Errorf("expected garden on a tie, got %q", got)
+	}
+	if got := categories.Classify("post office run"); got != "errands" {
+		t.Errorf("expected the extra errands keyword used, got %q", got)
+	}
+
+	if empty, err := Parse(""); err != nil || len(empty) != len(Defaults()) {
+		t.Errorf("expected the defaults for an empty spec, got %v, %v", empty, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, spec := range []string{"garden", "=mow", "garden=", "garden=|"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	categories := Defaults()
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"errands", "errands", true},
+		{"Errand", "errands", true},
+		{" WORK ", "work", true},
+		{"works", "work", true},
+		{"hobbies", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := categories.Lookup(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
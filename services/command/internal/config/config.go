@@ -3,36 +3,314 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/budget"
+	"hound-todo/services/command/internal/category"
+	"hound-todo/services/command/internal/pending"
 )
 
 // Config holds the configuration for command-svc
 type Config struct {
-	RabbitMQURL       string
-	TodoDomainAddr    string // gRPC address for todo-domain-svc
-	OpenAIAPIKey      string
-	OpenAIModel       string // Optional override for model
+	RabbitMQURL     string
+	TodoDomainAddr  string // gRPC address for todo-domain-svc
+	AIProvider      string // openai (default), openai_compatible, anthropic or fake
+	AIBaseURL       string // Server URL for openai_compatible, e.g. http://ollama:11434/v1
+	OpenAIAPIKey    string
+	OpenAIModel     string // Optional override for model, also used by openai_compatible
+	AnthropicAPIKey string
+	AnthropicModel  string // Optional override for model
+	ExportDir       string // Where user data exports are written
+
+	// Exports are served at EXPORT_BASE_URL/exports/<token> from
+	// EXPORT_HTTP_PORT, and deleted after EXPORT_TTL (0 for the default).
+	// EXPORT_SECRET keys the names that tie export files to their user.
+	ExportBaseURL  string
+	ExportHTTPPort string
+	ExportTTL      time.Duration
+	ExportSecret   string
+
+	// Per-action confidence below which a command waits for a YES, overriding
+	// the defaults. Set as CONFIRM_THRESHOLDS="delete=0.95,edit=always,create=never".
+	ConfirmThresholds map[string]float64
+
+	// Timezone used for dates in the LLM prompt, per user where known.
+	// USER_TIMEZONES="+15551234567=America/Chicago,+447700900123=Europe/London"
+	Timezone      *time.Location // DEFAULT_TIMEZONE, UTC if unset
+	UserTimezones map[string]*time.Location
+
+	// AIMode picks how messages rules can't handle reach the LLM: command
+	// (default) parses them into one fixed command each, agent lets the model
+	// call todo operations as tools, up to AgentMaxSteps turns (0 for the default)
+	AIMode        string
+	AgentMaxSteps int
+
+	// Retries and circuit breaking around LLM calls, 0 for the defaults.
+	// AI_MAX_ATTEMPTS=3 AI_BREAKER_THRESHOLD=5 AI_BREAKER_COOLDOWN=30s
+	AIMaxAttempts      int
+	AIBreakerThreshold int
+	AIBreakerCooldown  time.Duration
+
+	// What each model costs, in dollars per million tokens, over the defaults.
+	// LLM_PRICES="gpt-5-mini=0.25:2,llama3=0:0"
+	LLMPrices budget.Prices
+
+	// Per-user caps on LLM cost in millionths of a dollar, 0 for none. Set in
+	// dollars as LLM_DAILY_BUDGET=0.05 LLM_MONTHLY_BUDGET=1.00; a user over
+	// either only gets the rule parser until the period ends.
+	LLMBudget budget.Limits
+
+	// Cache of what context-free messages parse to, so repeats skip the LLM.
+	// AI_CACHE=off disables it; AI_CACHE_SIZE=1000 AI_CACHE_TTL=24h, 0 for the defaults
+	AICache     bool
+	AICacheSize int
+	AICacheTTL  time.Duration
+
+	// Check for todos already on the list before adding one. DUPLICATE_CHECK=off
+	// disables it; DUPLICATE_THRESHOLD=0.85 is how similar (0-1) a title must be
+	// to ask first, DUPLICATE_MERGE_WINDOW=5m how recent a duplicate is dropped
+	// without asking, 0 for the defaults
+	DuplicateCheck       bool
+	DuplicateThreshold   float64
+	DuplicateMergeWindow time.Duration
+
+	// What new todos are sorted into: errands, calls, home, work and health,
+	// with more keywords or categories from
+	// TODO_CATEGORIES="garden=mow|weed|plant,errands=post office".
+	// These are the same for every user; users can't define their own.
+	Categories category.Categories
 }
 
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
-		RabbitMQURL:    os.Getenv("RABBITMQ_URL"),
-		TodoDomainAddr: getEnvOrDefault("TODO_DOMAIN_GRPC_ADDR", "localhost:50051"),
-		OpenAIAPIKey:   os.Getenv("OPENAI_API_KEY"),
-		OpenAIModel:    os.Getenv("OPENAI_MODEL"), // Optional
+		RabbitMQURL:     os.Getenv("RABBITMQ_URL"),
+		TodoDomainAddr:  getEnvOrDefault("TODO_DOMAIN_GRPC_ADDR", "localhost:50051"),
+		AIProvider:      getEnvOrDefault("AI_PROVIDER", "openai"),
+		AIBaseURL:       os.Getenv("AI_BASE_URL"),
+		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
+		OpenAIModel:     os.Getenv("OPENAI_MODEL"), // Optional
+		AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
+		AnthropicModel:  os.Getenv("ANTHROPIC_MODEL"), // Optional
+		ExportDir:       getEnvOrDefault("EXPORT_DIR", "exports"),
+		ExportHTTPPort:  getEnvOrDefault("EXPORT_HTTP_PORT", "8081"),
+		ExportSecret:    os.Getenv("EXPORT_SECRET"),
+		AIMode:          getEnvOrDefault("AI_MODE", "command"),
+	}
+
+	thresholds, err := parseThresholds(os.Getenv("CONFIRM_THRESHOLDS"))
+	if err != nil {
+		return nil, fmt.Errorf("CONFIRM_THRESHOLDS: %w", err)
+	}
+	cfg.ConfirmThresholds = thresholds
+
+	cfg.Timezone, err = time.LoadLocation(getEnvOrDefault("DEFAULT_TIMEZONE", "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("DEFAULT_TIMEZONE: %w", err)
+	}
+	cfg.UserTimezones, err = parseUserTimezones(os.Getenv("USER_TIMEZONES"))
+	if err != nil {
+		return nil, fmt.Errorf("USER_TIMEZONES: %w", err)
+	}
+
+	if cfg.AIMode != "command" && cfg.AIMode != "agent" {
+		return nil, fmt.Errorf("unknown AI_MODE %q, expected command or agent", cfg.AIMode)
+	}
+	if cfg.AgentMaxSteps, err = getPositiveInt("AGENT_MAX_STEPS"); err != nil {
+		return nil, err
+	}
+	if cfg.AIMaxAttempts, err = getPositiveInt("AI_MAX_ATTEMPTS"); err != nil {
+		return nil, err
+	}
+	if cfg.AIBreakerThreshold, err = getPositiveInt("AI_BREAKER_THRESHOLD"); err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("AI_BREAKER_COOLDOWN"); v != "" {
+		cfg.AIBreakerCooldown, err = time.ParseDuration(v)
+		if err != nil || cfg.AIBreakerCooldown <= 0 {
+			return nil, fmt.Errorf("AI_BREAKER_COOLDOWN must be a positive duration like 30s, got %q", v)
+		}
+	}
+
+	switch v := getEnvOrDefault("AI_CACHE", "on"); v {
+	case "on":
+		cfg.AICache = true
+	case "off":
+	default:
+		return nil, fmt.Errorf("AI_CACHE must be on or off, got %q", v)
+	}
+	if cfg.AICacheSize, err = getPositiveInt("AI_CACHE_SIZE"); err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("AI_CACHE_TTL"); v != "" {
+		cfg.AICacheTTL, err = time.ParseDuration(v)
+		if err != nil || cfg.AICacheTTL <= 0 {
+			return nil, fmt.Errorf("AI_CACHE_TTL must be a positive duration like 24h, got %q", v)
+		}
+	}
+
+	switch v := getEnvOrDefault("DUPLICATE_CHECK", "on"); v {
+	case "on":
+		cfg.DuplicateCheck = true
+	case "off":
+	default:
+		return nil, fmt.Errorf("DUPLICATE_CHECK must be on or off, got %q", v)
+	}
+	if v := os.Getenv("DUPLICATE_THRESHOLD"); v != "" {
+		cfg.DuplicateThreshold, err = strconv.ParseFloat(v, 64)
+		if err != nil || cfg.DuplicateThreshold <= 0 || cfg.DuplicateThreshold > 1 {
+			return nil, fmt.Errorf("DUPLICATE_THRESHOLD must be a number above 0 and up to 1, got %q", v)
+		}
+	}
+	if v := os.Getenv("DUPLICATE_MERGE_WINDOW"); v != "" {
+		cfg.DuplicateMergeWindow, err = time.ParseDuration(v)
+		if err != nil || cfg.DuplicateMergeWindow <= 0 {
+			return nil, fmt.Errorf("DUPLICATE_MERGE_WINDOW must be a positive duration like 5m, got %q", v)
+		}
+	}
+
+	cfg.ExportBaseURL = getEnvOrDefault("EXPORT_BASE_URL", "http://localhost:"+cfg.ExportHTTPPort)
+	if v := os.Getenv("EXPORT_TTL"); v != "" {
+		cfg.ExportTTL, err = time.ParseDuration(v)
+		if err != nil || cfg.ExportTTL <= 0 {
+			return nil, fmt.Errorf("EXPORT_TTL must be a positive duration like 24h, got %q", v)
+		}
+	}
+
+	cfg.Categories, err = category.Parse(os.Getenv("TODO_CATEGORIES"))
+	if err != nil {
+		return nil, fmt.Errorf("TODO_CATEGORIES: %w", err)
+	}
+
+	cfg.LLMPrices, err = budget.ParsePrices(os.Getenv("LLM_PRICES"))
+	if err != nil {
+		return nil, fmt.Errorf("LLM_PRICES: %w", err)
+	}
+	if cfg.LLMBudget.Daily, err = getDollars("LLM_DAILY_BUDGET"); err != nil {
+		return nil, err
+	}
+	if cfg.LLMBudget.Monthly, err = getDollars("LLM_MONTHLY_BUDGET"); err != nil {
+		return nil, err
+	}
+	// An unpriced model would be recorded at no cost and never hit the budget
+	if cfg.LLMBudget.Daily > 0 || cfg.LLMBudget.Monthly > 0 {
+		if _, ok := cfg.LLMPrices.Cost(cfg.Model(), 0, 0); !ok {
+			return nil, fmt.Errorf("LLM_PRICES has no price for model %q, which a budget needs", cfg.Model())
+		}
 	}
 
 	if cfg.RabbitMQURL == "" {
 		return nil, fmt.Errorf("RABBITMQ_URL environment variable is required")
 	}
+	if cfg.ExportSecret == "" {
+		return nil, fmt.Errorf("EXPORT_SECRET environment variable is required")
+	}
 
-	if cfg.OpenAIAPIKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+	switch cfg.AIProvider {
+	case "openai":
+		if cfg.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+		}
+	case "openai_compatible":
+		// Local servers usually don't need a key
+		if cfg.AIBaseURL == "" {
+			return nil, fmt.Errorf("AI_BASE_URL environment variable is required for AI_PROVIDER=openai_compatible")
+		}
+	case "anthropic":
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required for AI_PROVIDER=anthropic")
+		}
+	case "fake":
+	default:
+		return nil, fmt.Errorf("unknown AI_PROVIDER %q", cfg.AIProvider)
 	}
 
 	return cfg, nil
 }
 
+// Model returns the model the configured provider uses
+func (c *Config) Model() string {
+	if c.AIProvider == ai.ProviderAnthropic {
+		if c.AnthropicModel != "" {
+			return c.AnthropicModel
+		}
+	} else if c.OpenAIModel != "" {
+		return c.OpenAIModel
+	}
+	return ai.DefaultModel(c.AIProvider)
+}
+
+// parseThresholds reads a comma-separated list of action=threshold pairs
+func parseThresholds(spec string) (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		action, value, ok := strings.Cut(pair, "=")
+		action = strings.TrimSpace(action)
+		if !ok || action == "" {
+			return nil, fmt.Errorf("expected action=threshold, got %q", pair)
+		}
+		threshold, err := pending.ParseThreshold(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", action, err)
+		}
+		thresholds[action] = threshold
+	}
+	return thresholds, nil
+}
+
+// parseUserTimezones reads a comma-separated list of user=timezone pairs
+func parseUserTimezones(spec string) (map[string]*time.Location, error) {
+	timezones := make(map[string]*time.Location)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		userID, name, ok := strings.Cut(pair, "=")
+		userID = strings.TrimSpace(userID)
+		if !ok || userID == "" {
+			return nil, fmt.Errorf("expected user=timezone, got %q", pair)
+		}
+		loc, err := time.LoadLocation(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", userID, err)
+		}
+		timezones[userID] = loc
+	}
+	return timezones, nil
+}
+
+// getPositiveInt reads an optional positive integer, 0 if unset
+func getPositiveInt(key string) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+// getDollars reads an optional dollar amount in millionths, 0 if unset
+func getDollars(key string) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+	micros, err := budget.ParseDollars(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return micros, nil
+}
+
 func getEnvOrDefault(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
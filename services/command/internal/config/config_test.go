@@ -2,9 +2,17 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
+// Every service needs an export secret, so the tests below leave it set
+func TestMain(m *testing.M) {
+	os.Setenv("EXPORT_SECRET", "test-secret")
+	os.Exit(m.Run())
+}
+
 func TestLoad_Success(t *testing.T) {
 	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
 	os.Setenv("OPENAI_API_KEY", "sk-test-key")
@@ -51,6 +59,21 @@ func TestLoad_MissingRabbitMQURL(t *testing.T) {
 	}
 }
 
+func TestLoad_MissingExportSecret(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("OPENAI_API_KEY", "sk-test-key")
+	os.Unsetenv("EXPORT_SECRET")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("OPENAI_API_KEY")
+		os.Setenv("EXPORT_SECRET", "test-secret")
+	}()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error when EXPORT_SECRET is missing")
+	}
+}
+
 func TestLoad_MissingOpenAIAPIKey(t *testing.T) {
 	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
 	os.Unsetenv("OPENAI_API_KEY")
@@ -155,3 +178,449 @@ func TestGetEnvOrDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_ExportDir(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("OPENAI_API_KEY", "sk-test-key")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("OPENAI_API_KEY")
+		os.Unsetenv("EXPORT_DIR")
+	}()
+
+	os.Unsetenv("EXPORT_DIR")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportDir != "exports" {
+		t.Errorf("expected default ExportDir exports, got %s", cfg.ExportDir)
+	}
+
+	os.Setenv("EXPORT_DIR", "/var/lib/hound/exports")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportDir != "/var/lib/hound/exports" {
+		t.Errorf("expected ExportDir /var/lib/hound/exports, got %s", cfg.ExportDir)
+	}
+}
+
+func TestLoad_ExportLinks(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("OPENAI_API_KEY", "sk-test-key")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("OPENAI_API_KEY")
+		os.Unsetenv("EXPORT_BASE_URL")
+		os.Unsetenv("EXPORT_HTTP_PORT")
+		os.Unsetenv("EXPORT_TTL")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportHTTPPort != "8081" || cfg.ExportBaseURL != "http://localhost:8081" || cfg.ExportTTL != 0 {
+		t.Errorf("unexpected defaults: %s %s %s", cfg.ExportHTTPPort, cfg.ExportBaseURL, cfg.ExportTTL)
+	}
+	if cfg.ExportSecret != "test-secret" {
+		t.Errorf("expected the export secret read, got %q", cfg.ExportSecret)
+	}
+
+	os.Setenv("EXPORT_BASE_URL", "https://hound.example.com")
+	os.Setenv("EXPORT_TTL", "2h")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportBaseURL != "https://hound.example.com" || cfg.ExportTTL != 2*time.Hour {
+		t.Errorf("unexpected export settings: %s %s", cfg.ExportBaseURL, cfg.ExportTTL)
+	}
+
+	os.Setenv("EXPORT_TTL", "forever")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for an invalid EXPORT_TTL")
+	}
+}
+
+func TestLoad_AIProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"default openai needs key", map[string]string{}, true},
+		{"openai with key", map[string]string{"OPENAI_API_KEY": "sk-test"}, false},
+		{"compatible needs base url", map[string]string{"AI_PROVIDER": "openai_compatible"}, true},
+		{"compatible without key", map[string]string{"AI_PROVIDER": "openai_compatible", "AI_BASE_URL": "http://ollama:11434/v1"}, false},
+		{"anthropic needs key", map[string]string{"AI_PROVIDER": "anthropic", "OPENAI_API_KEY": "sk-test"}, true},
+		{"anthropic with key", map[string]string{"AI_PROVIDER": "anthropic", "ANTHROPIC_API_KEY": "sk-ant-test"}, false},
+		{"fake needs nothing", map[string]string{"AI_PROVIDER": "fake"}, false},
+		{"unknown provider", map[string]string{"AI_PROVIDER": "carrier-pigeon", "OPENAI_API_KEY": "sk-test"}, true},
+	}
+
+	keys := []string{"AI_PROVIDER", "AI_BASE_URL", "OPENAI_API_KEY", "ANTHROPIC_API_KEY"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+			for _, k := range keys {
+				os.Unsetenv(k)
+			}
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			defer func() {
+				os.Unsetenv("RABBITMQ_URL")
+				for _, k := range keys {
+					os.Unsetenv(k)
+				}
+			}()
+
+			cfg, err := Load()
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && tt.env["AI_PROVIDER"] == "" && cfg.AIProvider != "openai" {
+				t.Errorf("expected default provider openai, got %s", cfg.AIProvider)
+			}
+		})
+	}
+}
+
+func TestLoad_ConfirmThresholds(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	os.Setenv("CONFIRM_THRESHOLDS", "delete=0.95, edit=always,create=never")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("CONFIRM_THRESHOLDS")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]float64{"delete": 0.95, "edit": 2.0, "create": 0}
+	if len(cfg.ConfirmThresholds) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, cfg.ConfirmThresholds)
+	}
+	for action, threshold := range expected {
+		if cfg.ConfirmThresholds[action] != threshold {
+			t.Errorf("%s: expected %v, got %v", action, threshold, cfg.ConfirmThresholds[action])
+		}
+	}
+}
+
+func TestLoad_InvalidConfirmThresholds(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("CONFIRM_THRESHOLDS")
+	}()
+
+	for _, spec := range []string{"delete", "delete=2", "=0.5", "delete=maybe"} {
+		os.Setenv("CONFIRM_THRESHOLDS", spec)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for CONFIRM_THRESHOLDS=%q", spec)
+		}
+	}
+}
+
+func TestLoad_Timezones(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("DEFAULT_TIMEZONE")
+		os.Unsetenv("USER_TIMEZONES")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Timezone.String() != "UTC" || len(cfg.UserTimezones) != 0 {
+		t.Errorf("expected UTC and no user timezones by default, got %s and %v", cfg.Timezone, cfg.UserTimezones)
+	}
+
+	os.Setenv("DEFAULT_TIMEZONE", "America/New_York")
+	os.Setenv("USER_TIMEZONES", "+15551234567=America/Chicago, +447700900123=Europe/London")
+	cfg, err = Load()
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	if cfg.Timezone.String() != "America/New_York" {
+		t.Errorf("expected America/New_York, got %s", cfg.Timezone)
+	}
+	if loc := cfg.UserTimezones["+447700900123"]; loc == nil || loc.String() != "Europe/London" {
+		t.Errorf("expected Europe/London for +447700900123, got %v", loc)
+	}
+
+	for _, spec := range []string{"+15551234567", "+15551234567=Mars/Olympus_Mons"} {
+		os.Setenv("USER_TIMEZONES", spec)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for USER_TIMEZONES=%q", spec)
+		}
+	}
+}
+
+func TestLoad_AIMode(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("AI_MODE")
+		os.Unsetenv("AGENT_MAX_STEPS")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AIMode != "command" || cfg.AgentMaxSteps != 0 {
+		t.Errorf("expected command mode with default steps, got %s and %d", cfg.AIMode, cfg.AgentMaxSteps)
+	}
+
+	os.Setenv("AI_MODE", "agent")
+	os.Setenv("AGENT_MAX_STEPS", "4")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AIMode != "agent" || cfg.AgentMaxSteps != 4 {
+		t.Errorf("expected agent mode with 4 steps, got %s and %d", cfg.AIMode, cfg.AgentMaxSteps)
+	}
+
+	os.Setenv("AGENT_MAX_STEPS", "0")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for AGENT_MAX_STEPS=0")
+	}
+
+	os.Unsetenv("AGENT_MAX_STEPS")
+	os.Setenv("AI_MODE", "chat")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for AI_MODE=chat")
+	}
+}
+
+func TestLoad_AIReliability(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("AI_MAX_ATTEMPTS")
+		os.Unsetenv("AI_BREAKER_THRESHOLD")
+		os.Unsetenv("AI_BREAKER_COOLDOWN")
+	}()
+
+	os.Setenv("AI_MAX_ATTEMPTS", "4")
+	os.Setenv("AI_BREAKER_THRESHOLD", "10")
+	os.Setenv("AI_BREAKER_COOLDOWN", "1m")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AIMaxAttempts != 4 || cfg.AIBreakerThreshold != 10 || cfg.AIBreakerCooldown != time.Minute {
+		t.Errorf("unexpected settings: %d, %d, %s", cfg.AIMaxAttempts, cfg.AIBreakerThreshold, cfg.AIBreakerCooldown)
+	}
+
+	for key, value := range map[string]string{"AI_MAX_ATTEMPTS": "-1", "AI_BREAKER_THRESHOLD": "lots", "AI_BREAKER_COOLDOWN": "30"} {
+		os.Setenv(key, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %s=%s", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_LLMBudget(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("LLM_PRICES")
+		os.Unsetenv("LLM_DAILY_BUDGET")
+		os.Unsetenv("LLM_MONTHLY_BUDGET")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLMBudget.Daily != 0 || cfg.LLMBudget.Monthly != 0 || len(cfg.LLMPrices) == 0 {
+		t.Errorf("expected no budgets and the default prices, got %+v and %v", cfg.LLMBudget, cfg.LLMPrices)
+	}
+
+	os.Setenv("LLM_PRICES", "llama3=0:0")
+	os.Setenv("LLM_DAILY_BUDGET", "0.05")
+	os.Setenv("LLM_MONTHLY_BUDGET", "$1")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cfg.LLMPrices["llama3"]; !ok {
+		t.Error("expected llama3 to be priced")
+	}
+	if cfg.LLMBudget.Daily != 50000 || cfg.LLMBudget.Monthly != 1000000 {
+		t.Errorf("unexpected budgets: %+v", cfg.LLMBudget)
+	}
+
+	for key, value := range map[string]string{"LLM_PRICES": "llama3", "LLM_DAILY_BUDGET": "-1", "LLM_MONTHLY_BUDGET": "plenty"} {
+		os.Setenv(key, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %s=%s", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_LLMBudgetNeedsAPrice(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "openai_compatible")
+	os.Setenv("AI_BASE_URL", "http://ollama:11434/v1")
+	os.Setenv("OPENAI_MODEL", "llama3")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("AI_BASE_URL")
+		os.Unsetenv("OPENAI_MODEL")
+		os.Unsetenv("LLM_PRICES")
+		os.Unsetenv("LLM_DAILY_BUDGET")
+	}()
+
+	// Without a budget an unpriced model is only logged
+	if _, err := Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Setenv("LLM_DAILY_BUDGET", "0.05")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "llama3") {
+		t.Errorf("expected an error naming the unpriced model, got %v", err)
+	}
+
+	os.Setenv("LLM_PRICES", "llama3=0:0")
+	if _, err := Load(); err != nil {
+		t.Errorf("unexpected error once priced: %v", err)
+	}
+}
+
+func TestLoad_AICache(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("AI_CACHE")
+		os.Unsetenv("AI_CACHE_SIZE")
+		os.Unsetenv("AI_CACHE_TTL")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.AICache || cfg.AICacheSize != 0 || cfg.AICacheTTL != 0 {
+		t.Errorf("expected the cache on with default bounds, got %v %d %s", cfg.AICache, cfg.AICacheSize, cfg.AICacheTTL)
+	}
+
+	os.Setenv("AI_CACHE", "off")
+	os.Setenv("AI_CACHE_SIZE", "500")
+	os.Setenv("AI_CACHE_TTL", "6h")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AICache || cfg.AICacheSize != 500 || cfg.AICacheTTL != 6*time.Hour {
+		t.Errorf("unexpected settings: %v %d %s", cfg.AICache, cfg.AICacheSize, cfg.AICacheTTL)
+	}
+
+	for key, value := range map[string]string{"AI_CACHE": "maybe", "AI_CACHE_SIZE": "0", "AI_CACHE_TTL": "forever"} {
+		os.Setenv(key, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %s=%s", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_DuplicateCheck(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("DUPLICATE_CHECK")
+		os.Unsetenv("DUPLICATE_THRESHOLD")
+		os.Unsetenv("DUPLICATE_MERGE_WINDOW")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.DuplicateCheck || cfg.DuplicateThreshold != 0 || cfg.DuplicateMergeWindow != 0 {
+		t.Errorf("expected the check on with defaults, got %v %g %s", cfg.DuplicateCheck, cfg.DuplicateThreshold, cfg.DuplicateMergeWindow)
+	}
+
+	os.Setenv("DUPLICATE_CHECK", "off")
+	os.Setenv("DUPLICATE_THRESHOLD", "0.9")
+	os.Setenv("DUPLICATE_MERGE_WINDOW", "2m")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DuplicateCheck || cfg.DuplicateThreshold != 0.9 || cfg.DuplicateMergeWindow != 2*time.Minute {
+		t.Errorf("unexpected settings: %v %g %s", cfg.DuplicateCheck, cfg.DuplicateThreshold, cfg.DuplicateMergeWindow)
+	}
+
+	for key, value := range map[string]string{"DUPLICATE_CHECK": "sometimes", "DUPLICATE_THRESHOLD": "1.5", "DUPLICATE_MERGE_WINDOW": "soon"} {
+		os.Setenv(key, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %s=%s", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_Categories(t *testing.T) {
+	os.Setenv("RABBITMQ_URL", "amqp://localhost:5672/")
+	os.Setenv("AI_PROVIDER", "fake")
+	defer func() {
+		os.Unsetenv("RABBITMQ_URL")
+		os.Unsetenv("AI_PROVIDER")
+		os.Unsetenv("TODO_CATEGORIES")
+	}()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(cfg.Categories.Names(), ","); got != "errands,calls,home,work,health" {
+		t.Errorf("expected the default categories, got %s", got)
+	}
+
+	os.Setenv("TODO_CATEGORIES", "garden=mow|weed")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Categories.Classify("weed the beds"); got != "garden" {
+		t.Errorf("expected the configured category used, got %q", got)
+	}
+
+	os.Setenv("TODO_CATEGORIES", "garden")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for a category without keywords")
+	}
+}
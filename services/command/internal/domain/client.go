@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"google.golang.org/grpc"
@@ -10,6 +11,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/match"
 )
 
 // ListTodosFilter contains optional filters for listing todos
@@ -17,6 +19,15 @@ type ListTodosFilter struct {
 	Status          todov1.TodoStatus
 	CompletedAfter  *time.Time
 	CompletedBefore *time.Time
+	Category        string // Empty for every category
+}
+
+// LLMUsageFilter selects the LLM usage to total
+type LLMUsageFilter struct {
+	UserID   string // All users if empty
+	Since    *time.Time
+	Until    *time.Time
+	Timezone string // IANA name used for day boundaries, UTC if empty
 }
 
 // Client wraps the gRPC client for todo-domain-svc
@@ -44,13 +55,29 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-// CreateTodo creates a new todo
-func (c *Client) CreateTodo(ctx context.Context, userID, title, description, idempotencyKey string) (*todov1.Todo, error) {
+// CreateTodo creates a new todo in a category, "" for none
+func (c *Client) CreateTodo(ctx context.Context, userID, title, description, category, idempotencyKey string) (*todov1.Todo, error) {
 	resp, err := c.client.CreateTodo(ctx, &todov1.CreateTodoRequest{
 		UserId:         userID,
 		Title:          title,
 		Description:    description,
+		Category:       category,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Todo, nil
+}
+
+// CreateStep creates a todo as a step of another, as when a todo is broken down
+func (c *Client) CreateStep(ctx context.Context, userID string, parentID int64, title, category, idempotencyKey string) (*todov1.Todo, error) {
+	resp, err := c.client.CreateTodo(ctx, &todov1.CreateTodoRequest{
+		UserId:         userID,
+		Title:          title,
+		Category:       category,
 		IdempotencyKey: idempotencyKey,
+		ParentId:       parentID,
 	})
 	if err != nil {
 		return nil, err
@@ -74,8 +101,9 @@ func (c *Client) CompleteTodo(ctx context.Context, todoID int64, userID, idempot
 // ListTodos retrieves todos for a user with optional filters
 func (c *Client) ListTodos(ctx context.Context, userID string, filter ListTodosFilter) ([]*todov1.Todo, error) {
 	req := &todov1.ListTodosRequest{
-		UserId: userID,
-		Status: filter.Status,
+		UserId:   userID,
+		Status:   filter.Status,
+		Category: filter.Category,
 	}
 
 	if filter.CompletedAfter != nil {
@@ -120,48 +148,167 @@ func (c *Client) EditTodo(ctx context.Context, todoID int64, userID, title, desc
 	return resp.Todo, nil
 }
 
-// FindTodoByTitle searches for a todo by partial title match
-// Returns the first matching active todo, or nil if not found
-func (c *Client) FindTodoByTitle(ctx context.Context, userID, titleHint string) (*todov1.Todo, error) {
-	todos, err := c.ListTodos(ctx, userID, ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+// RestoreTodo brings a todo back out of the trash
+func (c *Client) RestoreTodo(ctx context.Context, todoID int64, userID, idempotencyKey string) (*todov1.Todo, error) {
+	resp, err := c.client.RestoreTodo(ctx, &todov1.RestoreTodoRequest{
+		TodoId:         todoID,
+		UserId:         userID,
+		IdempotencyKey: idempotencyKey,
+	})
 	if err != nil {
 		return nil, err
 	}
+	return resp.Todo, nil
+}
 
-	// Simple substring match - could be improved with fuzzy matching
-	for _, todo := range todos {
-		if containsIgnoreCase(todo.Title, titleHint) {
-			return todo, nil
-		}
+// EmptyTrash permanently deletes all todos in the user's trash
+// Returns the number of todos deleted
+func (c *Client) EmptyTrash(ctx context.Context, userID, idempotencyKey string) (int64, error) {
+	resp, err := c.client.EmptyTrash(ctx, &todov1.EmptyTrashRequest{
+		UserId:         userID,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return 0, err
 	}
+	return resp.TodosDeleted, nil
+}
 
-	return nil, nil // Not found
+// GetStats retrieves completion statistics for a user, with days starting
+// at midnight in the given IANA timezone
+func (c *Client) GetStats(ctx context.Context, userID, timezone string) (*todov1.GetStatsResponse, error) {
+	return c.client.GetStats(ctx, &todov1.GetStatsRequest{
+		UserId:   userID,
+		Timezone: timezone,
+	})
 }
 
-// containsIgnoreCase checks if s contains substr (case-insensitive)
-func containsIgnoreCase(s, substr string) bool {
-	sLower := toLower(s)
-	substrLower := toLower(substr)
-	return contains(sLower, substrLower)
+// RecordLLMUsage stores the LLM usage for one message. A redelivered message
+// with the same idempotency key isn't counted twice.
+func (c *Client) RecordLLMUsage(ctx context.Context, usage *todov1.LLMUsage, idempotencyKey string) error {
+	_, err := c.client.RecordLLMUsage(ctx, &todov1.RecordLLMUsageRequest{
+		UserId:           usage.UserId,
+		Model:            usage.Model,
+		Calls:            usage.Calls,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		CostMicros:       usage.CostMicros,
+		IdempotencyKey:   idempotencyKey,
+	})
+	return err
 }
 
-func toLower(s string) string {
-	b := make([]byte, len(s))
-	for i := range s {
-		c := s[i]
-		if c >= 'A' && c <= 'Z' {
-			c += 'a' - 'A'
+// GetLLMUsage totals LLM usage per user, day and model
+func (c *Client) GetLLMUsage(ctx context.Context, filter LLMUsageFilter) ([]*todov1.LLMUsage, error) {
+	req := &todov1.GetLLMUsageRequest{
+		UserId:   filter.UserID,
+		Timezone: filter.Timezone,
+	}
+	if filter.Since != nil {
+		req.Since = timestamppb.New(*filter.Since)
+	}
+	if filter.Until != nil {
+		req.Until = timestamppb.New(*filter.Until)
+	}
+
+	resp, err := c.client.GetLLMUsage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Usage, nil
+}
+
+// ExportUserData downloads a user's data export bundle
+// Returns the suggested filename and the full bundle contents
+func (c *Client) ExportUserData(ctx context.Context, userID string, format todov1.ExportFormat) (string, []byte, error) {
+	stream, err := c.client.ExportUserData(ctx, &todov1.ExportUserDataRequest{
+		UserId: userID,
+		Format: format,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var filename string
+	var data []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if chunk.Filename != "" {
+			filename = chunk.Filename
 		}
-		b[i] = c
+		data = append(data, chunk.Data...)
 	}
-	return string(b)
+
+	return filename, data, nil
+}
+
+// EraseUserData erases all of a user's data
+func (c *Client) EraseUserData(ctx context.Context, userID string, mode todov1.EraseMode, idempotencyKey string) (*todov1.EraseUserDataResponse, error) {
+	return c.client.EraseUserData(ctx, &todov1.EraseUserDataRequest{
+		UserId:         userID,
+		Mode:           mode,
+		IdempotencyKey: idempotencyKey,
+	})
+}
+
+// TitleMatch is a todo found by a title hint and how well it matched, from
+// match.MinScore to 1 for the exact title
+type TitleMatch struct {
+	Todo  *todov1.Todo
+	Score float64
+}
+
+// FindTodosByTitle searches active todos by fuzzy title match
+// Returns every match, best first, or nil if none match
+func (c *Client) FindTodosByTitle(ctx context.Context, userID, titleHint string) ([]TitleMatch, error) {
+	return c.findByTitle(ctx, userID, titleHint, todov1.TodoStatus_TODO_STATUS_ACTIVE)
+}
+
+// FindDeletedTodosByTitle searches the user's trash by fuzzy title match
+// Returns every match, best first, or nil if none match
+func (c *Client) FindDeletedTodosByTitle(ctx context.Context, userID, titleHint string) ([]TitleMatch, error) {
+	return c.findByTitle(ctx, userID, titleHint, todov1.TodoStatus_TODO_STATUS_DELETED)
 }
 
-func contains(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+// FindTodoByID looks up one of the user's todos with the given status, or nil if there is none
+func (c *Client) FindTodoByID(ctx context.Context, userID string, todoID int64, status todov1.TodoStatus) (*todov1.Todo, error) {
+	todos, err := c.ListTodos(ctx, userID, ListTodosFilter{Status: status})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, todo := range todos {
+		if todo.Id == todoID {
+			return todo, nil
 		}
 	}
-	return false
+
+	return nil, nil // Not found
+}
+
+// findByTitle ranks the user's todos with the given status against a title hint.
+// Equal scores keep the list order, so the newest todo comes first.
+func (c *Client) findByTitle(ctx context.Context, userID, titleHint string, status todov1.TodoStatus) ([]TitleMatch, error) {
+	todos, err := c.ListTodos(ctx, userID, ListTodosFilter{Status: status})
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, len(todos))
+	for i, todo := range todos {
+		titles[i] = todo.Title
+	}
+
+	var matches []TitleMatch
+	for _, r := range match.Rank(titleHint, titles, match.MinScore) {
+		matches = append(matches, TitleMatch{Todo: todos[r.Index], Score: r.Score})
+	}
+
+	return matches, nil
 }
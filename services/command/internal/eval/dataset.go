@@ -0,0 +1,125 @@
+// Package eval scores the command parser against a golden set of SMS
+// messages, so prompt and model changes can be compared before they ship
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	_ "time/tzdata" // Datasets name their timezone, which may not be installed
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// Dataset is a versioned list of messages with the commands they should parse to.
+// Bump Version whenever a case is added, removed or its expectation changes,
+// since runs are only comparable on the same version.
+type Dataset struct {
+	Version  string    `json:"version"`
+	Now      time.Time `json:"now"`      // The time every message is sent at
+	Timezone string    `json:"timezone"` // The user's timezone, UTC if empty
+	Todos    []Todo    `json:"todos"`    // Active todos shown to the model, unless a case has its own
+	Cases    []Case    `json:"cases"`
+
+	location *time.Location
+}
+
+// Todo is an active todo the messages can refer to
+type Todo struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// Case is one message and what it should parse to
+type Case struct {
+	ID       string     `json:"id"`
+	Message  string     `json:"message"`
+	Todos    []Todo     `json:"todos,omitempty"`
+	Expected []Expected `json:"expected"`
+}
+
+// Expected is one command a message should parse to. Only the listed
+// parameters are checked; see matchParam for how values are compared.
+type Expected struct {
+	Action     string            `json:"action"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// LoadDataset reads and checks a dataset file
+func LoadDataset(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := ds.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ds, nil
+}
+
+func (ds *Dataset) validate() error {
+	if ds.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if ds.Now.IsZero() {
+		return fmt.Errorf("now is required so relative dates are reproducible")
+	}
+
+	ds.location = time.UTC
+	if ds.Timezone != "" {
+		loc, err := time.LoadLocation(ds.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+		ds.location = loc
+	}
+
+	known := make(map[string]bool)
+	for _, action := range ai.Actions() {
+		known[action] = true
+	}
+	seen := make(map[string]bool)
+	for i, c := range ds.Cases {
+		if c.ID == "" || c.Message == "" {
+			return fmt.Errorf("case %d: id and message are required", i)
+		}
+		if seen[c.ID] {
+			return fmt.Errorf("case %s: duplicate id", c.ID)
+		}
+		seen[c.ID] = true
+		if len(c.Expected) == 0 {
+			return fmt.Errorf("case %s: at least one expected command is required", c.ID)
+		}
+		for _, exp := range c.Expected {
+			if !known[exp.Action] {
+				return fmt.Errorf("case %s: unknown action %q", c.ID, exp.Action)
+			}
+		}
+	}
+	return nil
+}
+
+// Request builds the parser request for a case
+func (ds *Dataset) Request(c Case) *ai.Request {
+	todos := ds.Todos
+	if c.Todos != nil {
+		todos = c.Todos
+	}
+	refs := make([]ai.TodoRef, len(todos))
+	for i, todo := range todos {
+		refs[i] = ai.TodoRef{ID: todo.ID, Title: todo.Title}
+	}
+
+	return &ai.Request{
+		Message:  c.Message,
+		Now:      ds.Now,
+		Location: ds.location,
+		Todos:    refs,
+	}
+}
@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// Comparison is a candidate run measured against a baseline
+type Comparison struct {
+	Base, Head *Report
+	Regressed  []string // Cases exact in the baseline but not the candidate
+	Fixed      []string // Cases exact in the candidate but not the baseline
+	Threshold  float64
+	Pass       bool
+}
+
+// Compare diffs two reports on the same dataset. The candidate passes unless
+// its exact match rate is more than threshold below the baseline's.
+func Compare(base, head *Report, threshold float64) (*Comparison, error) {
+	if base.DatasetVersion != head.DatasetVersion {
+		return nil, fmt.Errorf("runs are on different datasets: %s and %s", base.DatasetVersion, head.DatasetVersion)
+	}
+
+	cmp := &Comparison{Base: base, Head: head, Threshold: threshold}
+	baseExact := make(map[string]bool, len(base.Cases))
+	for _, c := range base.Cases {
+		baseExact[c.ID] = c.Exact
+	}
+	for _, c := range head.Cases {
+		switch {
+		case baseExact[c.ID] && !c.Exact:
+			cmp.Regressed = append(cmp.Regressed, c.ID)
+		case !baseExact[c.ID] && c.Exact:
+			cmp.Fixed = append(cmp.Fixed, c.ID)
+		}
+	}
+
+	cmp.Pass = head.ExactRate() >= base.ExactRate()-threshold
+	return cmp, nil
+}
+
+// Write prints the change per action, the cases that moved and the verdict
+func (c *Comparison) Write(w io.Writer) {
+	fmt.Fprintf(w, "Base: %s\nHead: %s\n\n", c.Base.Label, c.Head.Label)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tBASE EXACT\tHEAD EXACT\tCHANGE")
+	for _, action := range ai.Actions() {
+		base, head := exactRate(c.Base.Actions[action]), exactRate(c.Head.Actions[action])
+		if c.Base.Actions[action] == nil && c.Head.Actions[action] == nil {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.1f%%\t%+.1f\n", action, 100*base, 100*head, 100*(head-base))
+	}
+	fmt.Fprintf(tw, "overall\t%.1f%%\t%.1f%%\t%+.1f\n", 100*c.Base.ExactRate(), 100*c.Head.ExactRate(),
+		100*(c.Head.ExactRate()-c.Base.ExactRate()))
+	tw.Flush()
+
+	if len(c.Regressed) > 0 {
+		fmt.Fprintf(w, "\nRegressed: %v\n", c.Regressed)
+	}
+	if len(c.Fixed) > 0 {
+		fmt.Fprintf(w, "\nFixed: %v\n", c.Fixed)
+	}
+
+	verdict := "PASS"
+	if !c.Pass {
+		verdict = "FAIL"
+	}
+	fmt.Fprintf(w, "\n%s (allowed drop %.1f points)\n", verdict, 100*c.Threshold)
+}
+
+func exactRate(s *ActionScore) float64 {
+	if s == nil || s.Commands == 0 {
+		return 0
+	}
+	return float64(s.Exact) / float64(s.Commands)
+}
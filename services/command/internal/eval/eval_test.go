@@ -0,0 +1,199 @@
+package eval
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// =============================================================================
+// Dataset Tests
+// =============================================================================
+
+func TestLoadDataset_Golden(t *testing.T) {
+	ds, err := LoadDataset("testdata/golden.json")
+	if err != nil {
+		t.Fatalf("golden dataset is invalid: %v", err)
+	}
+	if len(ds.Cases) == 0 {
+		t.Fatal("expected cases in the golden dataset")
+	}
+
+	// Every action should be covered so no prompt change goes unmeasured
+	covered := make(map[string]bool)
+	for _, c := range ds.Cases {
+		for _, exp := range c.Expected {
+			covered[exp.Action] = true
+		}
+	}
+	for _, action := range ai.Actions() {
+		if !covered[action] {
+			t.Errorf("no golden case for %s", action)
+		}
+	}
+
+	req := ds.Request(ds.Cases[0])
+	if req.Location.String() != "America/New_York" || len(req.Todos) != len(ds.Todos) {
+		t.Errorf("expected the dataset's timezone and todos, got %s and %d todos", req.Location, len(req.Todos))
+	}
+}
+
+func TestDataset_Validate(t *testing.T) {
+	good := func() *Dataset {
+		ds, _ := LoadDataset("testdata/golden.json")
+		return ds
+	}
+
+	tests := []struct {
+		name   string
+		modify func(ds *Dataset)
+	}{
+		{"no version", func(ds *Dataset) { ds.Version = "" }},
+		{"duplicate id", func(ds *Dataset) { ds.Cases[1].ID = ds.Cases[0].ID }},
+		{"unknown action", func(ds *Dataset) { ds.Cases[0].Expected[0].Action = "fly" }},
+		{"nothing expected", func(ds *Dataset) { ds.Cases[0].Expected = nil }},
+		{"bad timezone", func(ds *Dataset) { ds.Timezone = "Mars/Olympus_Mons" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := good()
+			tt.modify(ds)
+			if err := ds.validate(); err == nil {
+				t.Error("expected a validation error")
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Scoring Tests
+// =============================================================================
+
+func TestMatchParam(t *testing.T) {
+	tests := []struct {
+		name, param, want, got string
+		ok                     bool
+	}{
+		{"any value", "suggested_action", "*", "open the doc", true},
+		{"any value missing", "suggested_action", "*", "", false},
+		{"expect none", "todo_id", "", "", true},
+		{"id", "todo_id", "3", "3", true},
+		{"wrong id", "todo_id", "3", "4", false},
+		{"enum ignores case", "filter", "active", "Active", true},
+		{"title wording", "title", "water the plants", "Water plants", true},
+		{"title contained", "title", "buy bread", "bread", true},
+		{"different title", "title", "milk", "eggs", false},
+		{"same instant", "completed_after", "2026-01-19T00:00:00-05:00", "2026-01-19T05:00:00Z", true},
+		{"different day", "completed_after", "2026-01-19T00:00:00-05:00", "2026-01-18T00:00:00-05:00", false},
+		{"not a date", "completed_after", "2026-01-19T00:00:00-05:00", "yesterday", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchParam(tt.param, tt.want, tt.got); got != tt.ok {
+				t.Errorf("matchParam(%s, %q, %q) = %v, want %v", tt.param, tt.want, tt.got, got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScoreCase(t *testing.T) {
+	c := Case{ID: "mixed", Expected: []Expected{
+		{Action: "create", Parameters: map[string]string{"title": "milk"}},
+		{Action: "complete", Parameters: map[string]string{"todo_id": "2"}},
+	}}
+	create := &ai.Command{Action: "create", Parameters: map[string]string{"title": "milk"}}
+	complete := &ai.Command{Action: "complete", Parameters: map[string]string{"todo_id": "2"}}
+
+	tests := []struct {
+		name  string
+		resp  Response
+		exact bool
+		score float64
+	}{
+		{"exact", Response{Commands: []*ai.Command{create, complete}}, true, 1},
+		{"wrong id", Response{Commands: []*ai.Command{create, {Action: "complete", Parameters: map[string]string{"todo_id": "3"}}}}, false, 0.75},
+		{"missing command", Response{Commands: []*ai.Command{create}}, false, 0.5},
+		{"wrong order", Response{Commands: []*ai.Command{complete, create}}, false, 0},
+		{"extra command", Response{Commands: []*ai.Command{create, complete, create}}, false, 0.8},
+		{"error", Response{Error: "timeout"}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreCase(c, tt.resp, make(map[string]*ActionScore))
+			if got.Exact != tt.exact || got.Score != tt.score {
+				t.Errorf("expected exact=%v score=%.2f, got %+v", tt.exact, tt.score, got)
+			}
+		})
+	}
+}
+
+func TestScore_DatasetVersionMismatch(t *testing.T) {
+	ds := &Dataset{Version: "v2"}
+	if _, err := Score(ds, &Run{DatasetVersion: "v1"}); err == nil {
+		t.Error("expected an error scoring a run from another dataset version")
+	}
+}
+
+// =============================================================================
+// Record and Diff Tests
+// =============================================================================
+
+func TestRecordScoreAndDiff(t *testing.T) {
+	ds := &Dataset{Version: "test", Cases: []Case{
+		{ID: "list", Message: "list", Expected: []Expected{{Action: "list", Parameters: map[string]string{"filter": "active"}}}},
+		{ID: "stats", Message: "how am I doing", Expected: []Expected{{Action: "stats"}}},
+	}}
+
+	good := ai.NewFake()
+	good.Set("list", &ai.Command{Action: "list", Parameters: map[string]string{"filter": "active"}, Confidence: 1})
+	good.Set("how am I doing", &ai.Command{Action: "stats", Confidence: 1})
+	base, err := Record(context.Background(), good, ds, "fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A recorded run scores the same after a round trip through a file
+	path := filepath.Join(t.TempDir(), "base.json")
+	if err := base.Save(path); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+	loaded, err := LoadRun(path)
+	if err != nil {
+		t.Fatalf("failed to load run: %v", err)
+	}
+	baseReport, err := Score(ds, loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if baseReport.ExactRate() != 1 || loaded.PromptVersion != ai.PromptVersion() {
+		t.Errorf("expected a perfect run on the current prompt, got %.2f on %s", baseReport.ExactRate(), loaded.PromptVersion)
+	}
+
+	worse := ai.NewFake()
+	worse.Set("list", &ai.Command{Action: "list", Parameters: map[string]string{"filter": "all"}, Confidence: 1})
+	worse.Set("how am I doing", &ai.Command{Action: "stats", Confidence: 1})
+	head, _ := Record(context.Background(), worse, ds, "fake", "worse")
+	headReport, _ := Score(ds, head)
+
+	cmp, err := Compare(baseReport, headReport, 0.1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmp.Pass || len(cmp.Regressed) != 1 || cmp.Regressed[0] != "list" {
+		t.Errorf("expected list to regress and the diff to fail, got %+v", cmp)
+	}
+
+	cmp, _ = Compare(baseReport, headReport, 0.5)
+	if !cmp.Pass {
+		t.Error("expected a 50 point threshold to allow a one case drop")
+	}
+
+	var out strings.Builder
+	cmp.Write(&out)
+	if !strings.Contains(out.String(), "PASS") || !strings.Contains(out.String(), "Regressed: [list]") {
+		t.Errorf("unexpected diff output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// Run is what a parser returned for every case in a dataset. Saved to a file
+// it is the recording later scores and diffs are worked out from, without
+// calling the provider again.
+type Run struct {
+	DatasetVersion string     `json:"dataset_version"`
+	PromptVersion  string     `json:"prompt_version"`
+	Provider       string     `json:"provider"`
+	Model          string     `json:"model,omitempty"`
+	StartedAt      time.Time  `json:"started_at"`
+	Responses      []Response `json:"responses"`
+}
+
+// Response is the parser's output for one case
+type Response struct {
+	CaseID   string        `json:"case_id"`
+	Commands []*ai.Command `json:"commands,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Label names the run in reports
+func (r *Run) Label() string {
+	model := r.Model
+	if model == "" {
+		model = "default"
+	}
+	return fmt.Sprintf("%s/%s prompt %s", r.Provider, model, r.PromptVersion)
+}
+
+// Record sends every case through the parser. A failing case is recorded
+// with its error and scores zero; it doesn't stop the run.
+func Record(ctx context.Context, parser ai.Parser, ds *Dataset, provider, model string) (*Run, error) {
+	run := &Run{
+		DatasetVersion: ds.Version,
+		PromptVersion:  ai.PromptVersion(),
+		Provider:       provider,
+		Model:          model,
+		StartedAt:      time.Now().UTC(),
+	}
+
+	for _, c := range ds.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resp := Response{CaseID: c.ID}
+		cmds, err := parser.ParseCommands(ctx, ds.Request(c))
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Commands = cmds
+		}
+		run.Responses = append(run.Responses, resp)
+	}
+	return run, nil
+}
+
+// LoadRun reads a recorded run
+func LoadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &run, nil
+}
+
+// Save writes the run so it can be scored again later
+func (r *Run) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/match"
+)
+
+// Free-text parameters are compared loosely, since "buy milk" and "get milk"
+// are both fine titles. Either value may contain the other.
+const fuzzyMinScore = 0.8
+
+var (
+	fuzzyParams = map[string]bool{
+		"title": true, "description": true, "title_hint": true, "new_title": true,
+		"new_description": true, "task_context": true, "suggested_action": true, "reason": true,
+	}
+	dateParams = map[string]bool{"completed_after": true, "completed_before": true}
+)
+
+// Report is how a run scored against its dataset
+type Report struct {
+	Label          string
+	DatasetVersion string
+	PromptVersion  string
+	Cases          []CaseScore
+	Actions        map[string]*ActionScore // By expected action
+}
+
+// CaseScore is how one message scored. Score is the share of the expected
+// action and parameters the parser got right, 1 for an exact match.
+type CaseScore struct {
+	ID     string
+	Exact  bool
+	Score  float64
+	Misses []string // What was wrong, for the report
+}
+
+// ActionScore totals the expected commands of one action
+type ActionScore struct {
+	Commands int
+	Exact    int
+	Score    float64 // Sum of partial scores, divide by Commands for the mean
+}
+
+// ExactRate is the share of cases parsed exactly right
+func (r *Report) ExactRate() float64 {
+	if len(r.Cases) == 0 {
+		return 0
+	}
+	exact := 0
+	for _, c := range r.Cases {
+		if c.Exact {
+			exact++
+		}
+	}
+	return float64(exact) / float64(len(r.Cases))
+}
+
+// PartialRate is the mean partial score over all cases
+func (r *Report) PartialRate() float64 {
+	if len(r.Cases) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, c := range r.Cases {
+		total += c.Score
+	}
+	return total / float64(len(r.Cases))
+}
+
+// Score compares a run's responses with the dataset's expectations
+func Score(ds *Dataset, run *Run) (*Report, error) {
+	if run.DatasetVersion != ds.Version {
+		return nil, fmt.Errorf("run is for dataset %s, not %s", run.DatasetVersion, ds.Version)
+	}
+
+	responses := make(map[string]Response, len(run.Responses))
+	for _, resp := range run.Responses {
+		responses[resp.CaseID] = resp
+	}
+
+	report := &Report{
+		Label:          run.Label(),
+		DatasetVersion: ds.Version,
+		PromptVersion:  run.PromptVersion,
+		Actions:        make(map[string]*ActionScore),
+	}
+	for _, c := range ds.Cases {
+		resp, ok := responses[c.ID]
+		if !ok {
+			resp = Response{CaseID: c.ID, Error: "not in the run"}
+		}
+		report.Cases = append(report.Cases, scoreCase(c, resp, report.Actions))
+	}
+	return report, nil
+}
+
+// scoreCase gives a point for each expected command's action and one for each
+// of its parameters, in order. Extra commands cost a point each.
+func scoreCase(c Case, resp Response, actions map[string]*ActionScore) CaseScore {
+	result := CaseScore{ID: c.ID}
+	total, earned := 0, 0
+
+	for i, exp := range c.Expected {
+		stats := actions[exp.Action]
+		if stats == nil {
+			stats = &ActionScore{}
+			actions[exp.Action] = stats
+		}
+		stats.Commands++
+
+		points := 1 + len(exp.Parameters)
+		total += points
+		got := 0
+
+		switch {
+		case resp.Error != "":
+			if i == 0 {
+				result.Misses = append(result.Misses, "error: "+resp.Error)
+			}
+		case i >= len(resp.Commands):
+			result.Misses = append(result.Misses, fmt.Sprintf("command %d: missing %s", i+1, exp.Action))
+		case resp.Commands[i].Action != exp.Action:
+			result.Misses = append(result.Misses, fmt.Sprintf("command %d: expected %s, got %s", i+1, exp.Action, resp.Commands[i].Action))
+		default:
+			got = 1
+			cmd := resp.Commands[i]
+			for _, name := range sortedKeys(exp.Parameters) {
+				want, have := exp.Parameters[name], cmd.Parameters[name]
+				if matchParam(name, want, have) {
+					got++
+				} else {
+					result.Misses = append(result.Misses, fmt.Sprintf("command %d: %s expected %q, got %q", i+1, name, want, have))
+				}
+			}
+		}
+
+		earned += got
+		stats.Score += float64(got) / float64(points)
+		if got == points {
+			stats.Exact++
+		}
+	}
+
+	if extra := len(resp.Commands) - len(c.Expected); extra > 0 && resp.Error == "" {
+		total += extra
+		result.Misses = append(result.Misses, fmt.Sprintf("%d extra command(s)", extra))
+	}
+
+	result.Exact = earned == total
+	result.Score = float64(earned) / float64(total)
+	return result
+}
+
+// matchParam compares a parameter with its expected value. "*" expects any
+// value and "" expects none. Dates must be the same instant, free text must
+// be close, and anything else equal ignoring case.
+func matchParam(name, want, got string) bool {
+	want, got = strings.TrimSpace(want), strings.TrimSpace(got)
+	switch {
+	case want == "*":
+		return got != ""
+	case want == "" || got == "":
+		return want == got
+	case dateParams[name]:
+		w, err1 := time.Parse(time.RFC3339, want)
+		g, err2 := time.Parse(time.RFC3339, got)
+		return err1 == nil && err2 == nil && w.Equal(g)
+	case fuzzyParams[name]:
+		return match.Score(want, got) >= fuzzyMinScore || match.Score(got, want) >= fuzzyMinScore
+	default:
+		return strings.EqualFold(want, got)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write prints the per-action table and every case that wasn't exact
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "%s (dataset %s)\n", r.Label, r.DatasetVersion)
+	fmt.Fprintf(w, "Exact: %.1f%%  Partial: %.1f%%  Cases: %d\n\n", 100*r.ExactRate(), 100*r.PartialRate(), len(r.Cases))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tCOMMANDS\tEXACT\tPARTIAL")
+	for _, action := range ai.Actions() {
+		stats, ok := r.Actions[action]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%.1f%%\n", action, stats.Commands,
+			100*float64(stats.Exact)/float64(stats.Commands), 100*stats.Score/float64(stats.Commands))
+	}
+	tw.Flush()
+
+	for _, c := range r.Cases {
+		if c.Exact {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%.0f%%)\n", c.ID, 100*c.Score)
+		for _, miss := range c.Misses {
+			fmt.Fprintf(w, "  - %s\n", miss)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package exports
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is how long an export can be downloaded before it is deleted
+const DefaultTTL = 24 * time.Hour
+
+// Store keeps user data exports on disk and serves them over HTTP at
+// unguessable links. Each file is named after an HMAC of its user, so all of a
+// user's exports can be found and deleted when they erase their account
+// without phone numbers being recoverable from the names, and a random token,
+// so one link can't be derived from another. Exports older than the TTL are
+// neither served nor kept.
+type Store struct {
+	dir     string
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// New creates a Store writing to dir, linking to files under baseURL, naming
+// them with secret, and keeping each for ttl (0 for DefaultTTL). Without a
+// secret a random one is used, so exports from before a restart can't be
+// found for a user and are only removed by Purge.
+func New(dir, baseURL string, secret []byte, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		rand.Read(secret)
+	}
+	return &Store{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  secret,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// TTL returns how long an export stays available
+func (s *Store) TTL() time.Duration {
+	return s.ttl
+}
+
+// Save writes a user's export and returns the link it can be downloaded from
+func (s *Store) Save(userID, filename string, data []byte) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export dir: %w", err)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate export token: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s", s.userPrefix(userID), hex.EncodeToString(token), filepath.Base(filename))
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return s.baseURL + "/exports/" + name, nil
+}
+
+// DeleteUser removes every export of a user, returning how many there were
+func (s *Store) DeleteUser(userID string) (int, error) {
+	return s.remove(func(name string, _ os.FileInfo) bool {
+		return strings.HasPrefix(name, s.userPrefix(userID)+"-")
+	})
+}
+
+// Purge removes exports older than the TTL, returning how many there were
+func (s *Store) Purge() (int, error) {
+	return s.remove(func(_ string, info os.FileInfo) bool {
+		return s.expired(info)
+	})
+}
+
+// ServeHTTP serves GET /exports/<name> while the export hasn't expired
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/exports/")
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	path := filepath.Join(s.dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || s.expired(info) {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportName(name)))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeFile(w, r, path)
+}
+
+func (s *Store) expired(info os.FileInfo) bool {
+	return s.now().Sub(info.ModTime()) > s.ttl
+}
+
+// remove deletes the exports match selects
+func (s *Store) remove(match func(name string, info os.FileInfo) bool) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	removed := 0
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || info.IsDir() || !match(e.Name(), info) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, e.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove export: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// userPrefix names a user's exports without putting their phone number on
+// disk. Keyed, because a plain hash of a phone number is easily reversed.
+func (s *Store) userPrefix(userID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// exportName is the filename todo-domain gave an export, without the prefix
+// and token Save added
+func exportName(name string) string {
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) < 3 {
+		return name
+	}
+	return parts[2]
+}
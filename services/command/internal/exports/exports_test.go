@@ -0,0 +1,131 @@
+package exports
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Store Tests
+// =============================================================================
+
+func TestStore_SaveAndServe(t *testing.T) {
+	s := New(t.TempDir(), "https://hound.example.com/", []byte("secret"), time.Hour)
+
+	link, err := s.Save("+15550001111", "hound-export-20261018.zip", []byte("data"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://hound.example.com/exports/") || strings.Contains(link, "555") {
+		t.Fatalf("unexpected link %s", link)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.TrimPrefix(link, "https://hound.example.com"), nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "data" {
+		t.Fatalf("expected the export served, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="hound-export-20261018.zip"` {
+		t.Errorf("unexpected Content-Disposition %s", got)
+	}
+}
+
+func TestStore_LinksAreUnique(t *testing.T) {
+	s := New(t.TempDir(), "http://localhost:8081", []byte("secret"), time.Hour)
+
+	a, _ := s.Save("+15550001111", "export.zip", []byte("a"))
+	b, _ := s.Save("+15550001111", "export.zip", []byte("b"))
+
+	if a == b {
+		t.Errorf("expected a new link per export, got %s twice", a)
+	}
+}
+
+func TestStore_ServeRejectsUnknownAndTraversal(t *testing.T) {
+	dir := t.TempDir()
+	s := New(filepath.Join(dir, "exports"), "http://localhost:8081", []byte("secret"), time.Hour)
+	os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0o600)
+
+	for _, path := range []string{"/exports/", "/exports/nope.zip", "/exports/../secret", "/exports/%2e%2e%2fsecret"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestStore_ExpiredExportsAreNotServedAndArePurged(t *testing.T) {
+	s := New(t.TempDir(), "http://localhost:8081", []byte("secret"), time.Hour)
+	link, _ := s.Save("+15550001111", "export.zip", []byte("old"))
+	s.Save("+15552223333", "export.zip", []byte("new"))
+
+	// Age the first export past the TTL
+	old := filepath.Join(s.dir, strings.TrimPrefix(link, "http://localhost:8081/exports/"))
+	os.Chtimes(old, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/exports/"+filepath.Base(old), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected an expired export not to be served, got %d", rec.Code)
+	}
+
+	n, err := s.Purge()
+	if err != nil || n != 1 {
+		t.Fatalf("expected one export purged, got %d (%v)", n, err)
+	}
+	if files, _ := os.ReadDir(s.dir); len(files) != 1 {
+		t.Errorf("expected the fresh export kept, got %v", files)
+	}
+}
+
+func TestStore_DeleteUser(t *testing.T) {
+	s := New(t.TempDir(), "http://localhost:8081", []byte("secret"), time.Hour)
+	s.Save("+15550001111", "export.zip", []byte("a"))
+	s.Save("+15550001111", "export.zip", []byte("b"))
+	s.Save("+15552223333", "export.zip", []byte("c"))
+
+	n, err := s.DeleteUser("+15550001111")
+	if err != nil || n != 2 {
+		t.Fatalf("expected both of the user's exports deleted, got %d (%v)", n, err)
+	}
+	if files, _ := os.ReadDir(s.dir); len(files) != 1 {
+		t.Errorf("expected the other user's export kept, got %v", files)
+	}
+}
+
+func TestStore_NamesDependOnTheSecret(t *testing.T) {
+	dir := t.TempDir()
+	a := New(dir, "http://localhost:8081", []byte("one"), time.Hour)
+	b := New(dir, "http://localhost:8081", []byte("two"), time.Hour)
+
+	if a.userPrefix("+15550001111") == b.userPrefix("+15550001111") {
+		t.Fatal("expected a different name for each secret")
+	}
+
+	// Only the store with the secret the export was saved under finds it
+	a.Save("+15550001111", "export.zip", []byte("a"))
+	if n, _ := b.DeleteUser("+15550001111"); n != 0 {
+		t.Errorf("expected another secret to find nothing, deleted %d", n)
+	}
+	if n, _ := a.DeleteUser("+15550001111"); n != 1 {
+		t.Errorf("expected the export found with its secret, deleted %d", n)
+	}
+}
+
+func TestStore_MissingDir(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "never-written"), "http://localhost:8081", []byte("secret"), time.Hour)
+
+	if n, err := s.Purge(); err != nil || n != 0 {
+		t.Errorf("expected nothing to purge, got %d (%v)", n, err)
+	}
+	if n, err := s.DeleteUser("+15550001111"); err != nil || n != 0 {
+		t.Errorf("expected nothing to delete, got %d (%v)", n, err)
+	}
+}
@@ -0,0 +1,107 @@
+package guard
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// MaxMessageLength is the most characters of a message that are acted on.
+// Ten SMS segments; anything longer is cut rather than sent to the LLM whole.
+const MaxMessageLength = 1600
+
+// injectionPatterns recognize text written to steer the model rather than to
+// manage todos. A match doesn't stop the message, it only makes every change
+// it asks for wait for a YES.
+var injectionPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b.{0,20}\b(?:previous|prior|above|earlier|preceding|your|system|any|all)\s+(?:\w+\s+)?(?:instructions?|rules|prompts?|directions|guidelines)\b`)},
+	{"role_override", regexp.MustCompile(`(?i)\b(?:you are now|you're now|act as (?:an? |the )?(?:ai|assistant|system|admin|administrator|root|developer)|pretend (?:to be|you are)|from now on,? you|developer mode|jailbreak|dan mode)\b`)},
+	{"prompt_leak", regexp.MustCompile(`(?i)\b(?:reveal|print|repeat|output|show me|tell me)\b.{0,30}\b(?:system prompt|your (?:instructions|prompt|rules)|initial prompt)`)},
+	{"fake_markup", regexp.MustCompile(`(?im)(?:</?\s*(?:system|assistant|sms|instructions?)\s*>|\[/?inst\]|<\|im_(?:start|end)\|>|^\s*(?:system|assistant)\s*:|#{2,}\s*(?:system|instructions?|new rules)\b)`)},
+	{"tool_call", regexp.MustCompile(`(?i)\b(?:invoke|execute)\s+(?:the\s+)?(?:tools?|functions?)\b|"action"\s*:|\b(?:delete_todo|complete_todo|erase_account|empty_trash)\b`)},
+}
+
+// bulkPattern recognizes asking for many todos at once, "delete everything"
+var bulkPattern = regexp.MustCompile(`(?i)\b(?:delete|remove|erase|wipe|clear|cancel|complete|finish|mark|check off)\b.{0,25}\b(?:all|every|everything|each|whole list)\b`)
+
+// Input is a message after sanitizing and classification
+type Input struct {
+	Text      string   // What the rest of the handler sees
+	Stripped  int      // Control and invisible characters removed
+	Truncated bool     // Cut to MaxMessageLength
+	Flags     []string // Names of the injection patterns matched
+	Bulk      bool     // Asks for many todos at once
+}
+
+// Suspicious reports whether the message looks like an attempt to instruct
+// the model
+func (in *Input) Suspicious() bool {
+	return len(in.Flags) > 0
+}
+
+// Inspect sanitizes a message and classifies what's left
+func Inspect(text string) *Input {
+	clean, stripped := ai.Sanitize(text)
+	in := &Input{Text: clean, Stripped: stripped}
+
+	if utf8.RuneCountInString(clean) > MaxMessageLength {
+		in.Text = string([]rune(clean)[:MaxMessageLength])
+		in.Truncated = true
+	}
+
+	// Classified before truncating, so padding can't push a pattern out of view
+	for _, p := range injectionPatterns {
+		if p.pattern.MatchString(clean) {
+			in.Flags = append(in.Flags, p.name)
+		}
+	}
+	in.Bulk = bulkPattern.MatchString(clean)
+	return in
+}
+
+// BulkDelete is why a delete is confirmed when the message asks for more than one
+const BulkDelete = "bulk delete"
+
+// Review applies the confirmation policy the model can't talk its way past.
+// It returns, per command, why it must be confirmed whatever its confidence,
+// or "" if the usual thresholds apply:
+//   - every change asked for by a message flagged as suspicious
+//   - every delete when a message asks for more than one
+//   - every delete and complete when a message asks for "all" or "everything"
+//
+// Emptying the trash and erasing the account always ask already.
+func Review(in *Input, cmds []*ai.Command) []string {
+	deletes := 0
+	for _, cmd := range cmds {
+		if cmd.Action == "delete" {
+			deletes++
+		}
+	}
+
+	reasons := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		switch {
+		case in.Suspicious() && mutates(cmd.Action):
+			reasons[i] = "suspicious message (" + strings.Join(in.Flags, ", ") + ")"
+		case cmd.Action == "delete" && deletes > 1:
+			reasons[i] = BulkDelete
+		case in.Bulk && (cmd.Action == "delete" || cmd.Action == "complete"):
+			reasons[i] = "bulk request"
+		}
+	}
+	return reasons
+}
+
+// mutates reports whether an action changes the user's todos
+func mutates(action string) bool {
+	switch action {
+	case "create", "complete", "edit", "delete", "restore":
+		return true
+	}
+	return false
+}
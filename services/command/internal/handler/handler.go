@@ -2,75 +2,524 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/command/internal/agent"
 	"hound-todo/services/command/internal/ai"
+	"hound-todo/services/command/internal/budget"
+	"hound-todo/services/command/internal/cache"
+	"hound-todo/services/command/internal/category"
 	"hound-todo/services/command/internal/consumer"
 	"hound-todo/services/command/internal/domain"
-	"hound-todo/services/command/internal/publisher"
+	"hound-todo/services/command/internal/exports"
+	"hound-todo/services/command/internal/guard"
+	"hound-todo/services/command/internal/match"
+	"hound-todo/services/command/internal/memory"
+	"hound-todo/services/command/internal/nudge"
+	"hound-todo/services/command/internal/pending"
+	"hound-todo/services/command/internal/rules"
+	"hound-todo/shared/idempotency"
 	"hound-todo/shared/logging"
 )
 
 const (
 	// Confidence threshold below which we include AI explanation in reply
 	lowConfidenceThreshold = 0.85
+
+	// How long a confirmation prompt stays valid
+	pendingTTL = 10 * time.Minute
+
+	// How long a conversation is remembered without new messages
+	conversationTTL = 30 * time.Minute
+
+	// Most todos offered when a title hint matches several
+	maxChoices = 5
+
+	// How many suggested todos are skipped when picking what to nudge next,
+	// and for how long, so asking again offers something else
+	recentNudges   = 3
+	nudgeMemoryTTL = 24 * time.Hour
+
+	// How far back completed todos are looked at to learn what a user gets done
+	nudgePatternWindow = 90 * 24 * time.Hour
+
+	// A title match is acted on without asking when it scores at least
+	// clearMatchScore and beats the next best by clearMatchMargin, so "call"
+	// picks the todo titled "call" but asks between "call mom" and "call dentist"
+	clearMatchScore  = 0.85
+	clearMatchMargin = 0.08
+
+	// Sent instead of requeueing when the LLM provider is down or rate limited
+	unavailableReply = "I'm having trouble understanding messages right now, try again shortly. " +
+		"Simple commands still work: \"add milk\", \"done 3\", \"delete 3\" or \"list\"."
+
+	// Sent instead of requeueing when the LLM provider refuses the request,
+	// since it would refuse every redelivery too
+	rejectedReply = "Something went wrong on my end reading that message, so I've dropped it. " +
+		"Simple commands still work: \"add milk\", \"done 3\", \"delete 3\" or \"list\"."
+
+	// Sent instead of calling the LLM once a user's budget is spent
+	budgetReply = "You've reached %s limit for messages I need to think about, so until %s " +
+		"I can only follow simple commands: \"add milk\", \"done 3\", \"delete 3\" or \"list\"."
+)
+
+// Replier sends a reply to the user. *publisher.Publisher in production.
+type Replier interface {
+	PublishReply(ctx context.Context, userID, message string) error
+}
+
+// Defaults for the check for todos already on the list, see SetDuplicateCheck
+const (
+	DefaultDuplicateThreshold = 0.85
+	DefaultMergeWindow        = 5 * time.Minute
 )
 
 // Handler processes text commands using AI and executes them via gRPC
 type Handler struct {
-	ai        *ai.Client
-	domain    *domain.Client
-	publisher *publisher.Publisher
-	logger    *logging.Logger
+	ai         ai.Parser
+	rules      *rules.Parser
+	domain     *domain.Client
+	publisher  Replier
+	pending    *pending.Store
+	confirm    pending.Policy
+	memory     *memory.Store
+	nudges     *nudge.Recent
+	exports    *exports.Store
+	timezone   *time.Location
+	timezones  map[string]*time.Location // Per-user overrides of timezone
+	agent      *agent.Agent              // Answers messages rules can't when set, instead of ai
+	budget     *budget.Tracker           // Records LLM costs and enforces budgets when set
+	cache      *cache.Cache              // Answers repeated context-free messages when set
+	categories category.Categories       // New todos are sorted into these
+	duplicate  float64                   // Title similarity that asks before adding a todo, 0 for no check
+	merge      time.Duration             // A duplicate of a todo added this recently isn't added at all
+	logger     *logging.Logger
 }
 
 // New creates a new command handler
-func New(aiClient *ai.Client, domainClient *domain.Client, pub *publisher.Publisher, logger *logging.Logger) *Handler {
+func New(aiParser ai.Parser, domainClient *domain.Client, pub Replier, logger *logging.Logger) *Handler {
 	return &Handler{
-		ai:        aiClient,
-		domain:    domainClient,
-		publisher: pub,
-		logger:    logger,
+		ai:         aiParser,
+		rules:      rules.New(),
+		domain:     domainClient,
+		publisher:  pub,
+		pending:    pending.New(pendingTTL),
+		confirm:    pending.DefaultPolicy(),
+		memory:     memory.New(conversationTTL),
+		nudges:     nudge.NewRecent(recentNudges, nudgeMemoryTTL),
+		categories: category.Defaults(),
+		exports:    exports.New("exports", "http://localhost:8081", nil, 0),
+		timezone:   time.UTC,
+		logger:     logger,
 	}
 }
 
+// SetExports sets where user data exports are written and linked from
+func (h *Handler) SetExports(s *exports.Store) {
+	h.exports = s
+}
+
+// SetAgent switches messages the rule parser can't handle from one-shot
+// command parsing to the tool-calling agent
+func (h *Handler) SetAgent(a *agent.Agent) {
+	a.SetGate(h.holdAgentChange)
+	h.agent = a
+}
+
+// SetBudget records what each message's LLM calls cost and stops calling the
+// LLM for users who have spent their budget
+func (h *Handler) SetBudget(t *budget.Tracker) {
+	h.budget = t
+}
+
+// SetCache lets repeated context-free messages skip the LLM
+func (h *Handler) SetCache(c *cache.Cache) {
+	h.cache = c
+}
+
+// SetCategories sets the categories new todos are sorted into and that
+// lists can be filtered by
+func (h *Handler) SetCategories(c category.Categories) {
+	h.categories = c
+}
+
+// SetDuplicateCheck asks before adding a todo whose title is at least
+// threshold similar to an active one, and drops it without asking when that
+// one was added within mergeWindow - a voice memo and a text saying the same
+// thing. Zero values use DefaultDuplicateThreshold and DefaultMergeWindow.
+func (h *Handler) SetDuplicateCheck(threshold float64, mergeWindow time.Duration) {
+	if threshold == 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	if mergeWindow == 0 {
+		mergeWindow = DefaultMergeWindow
+	}
+	h.duplicate = threshold
+	h.merge = mergeWindow
+}
+
+// CacheStats reports how often the LLM cache answered, zero without one
+func (h *Handler) CacheStats() cache.Stats {
+	if h.cache == nil {
+		return cache.Stats{}
+	}
+	return h.cache.Stats()
+}
+
+// SetTimezones sets the timezone dates are worked out in, with overrides for
+// users known to live elsewhere
+func (h *Handler) SetTimezones(defaultTZ *time.Location, perUser map[string]*time.Location) {
+	h.timezone = defaultTZ
+	h.timezones = perUser
+}
+
+// location returns the user's timezone
+func (h *Handler) location(userID string) *time.Location {
+	if loc, ok := h.timezones[userID]; ok {
+		return loc
+	}
+	return h.timezone
+}
+
+// SetConfirmThresholds overrides the confidence below which an action is
+// confirmed before it runs. Actions not listed keep their defaults.
+func (h *Handler) SetConfirmThresholds(thresholds map[string]float64) error {
+	known := ai.Actions()
+	for action, threshold := range thresholds {
+		i := sort.SearchStrings(known, action)
+		if i == len(known) || known[i] != action {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		h.confirm[action] = threshold
+	}
+	return nil
+}
+
+// ConfirmPolicy returns the confirmation thresholds in effect
+func (h *Handler) ConfirmPolicy() pending.Policy {
+	return h.confirm
+}
+
+// RuleStats reports how many messages were parsed without calling the LLM
+func (h *Handler) RuleStats() rules.Stats {
+	return h.rules.Stats()
+}
+
 // Handle processes a single text message
 func (h *Handler) Handle(ctx context.Context, msg *consumer.TextMessage) error {
-	// Parse the command using AI
-	cmd, err := h.ai.ParseCommand(ctx, msg.CommandText)
+	// Everything below, the LLM included, sees the sanitized text
+	in := guard.Inspect(msg.CommandText)
+	if in.Stripped > 0 || in.Truncated || in.Suspicious() {
+		h.logger.Info("Sanitized message from %s: stripped=%d truncated=%t flags=%v",
+			msg.UserID, in.Stripped, in.Truncated, in.Flags)
+	}
+	msg.CommandText = in.Text
+
+	// A YES/NO reply to a confirmation prompt, or a number picked from a
+	// shortlist, resolves it instead of being parsed
+	if action, expired := h.pending.Take(msg.UserID); action != nil {
+		reply := pending.ParseReply(msg.CommandText)
+		choice, chosen := 0, false
+		if len(action.Choices) > 0 {
+			choice, chosen = pending.ParseChoice(msg.CommandText, len(action.Choices))
+			if reply == pending.ReplyYes {
+				// A shortlist needs a number - "yes" doesn't say which
+				reply = pending.ReplyNone
+			}
+		}
+		var picks []int
+		picked := false
+		if len(action.Steps) > 0 && reply == pending.ReplyNone {
+			picks, picked = pending.ParseSelection(msg.CommandText, len(action.Steps))
+		}
+
+		if chosen || picked || reply != pending.ReplyNone {
+			var result string
+			var err error
+			switch {
+			case expired:
+				h.logger.Info("Pending %s for %s expired before the reply", action.Command.Action, msg.UserID)
+				result = "That request timed out, so I didn't change anything. Send it again if you still want it."
+			case chosen:
+				result, err = h.resolveChoice(ctx, msg.UserID, action, choice)
+			case picked:
+				result, err = h.addSteps(ctx, msg.UserID, action, picks)
+			default:
+				result, err = h.resolvePending(ctx, msg.UserID, action, reply)
+			}
+			if err != nil {
+				// Keep the action so the redelivered reply can retry it
+				h.pending.Restore(msg.UserID, action)
+				h.logger.Error("Confirmed command failed: %v", err)
+				return fmt.Errorf("confirmed command failed: %w", err)
+			}
+			if !expired && reply == pending.ReplyYes && action.Command.Action == "erase_account" {
+				// Nothing about the user is kept after an erase, not even this exchange
+				h.memory.Forget(msg.UserID)
+				h.nudges.Forget(msg.UserID)
+			} else {
+				h.memory.AddTurn(msg.UserID, msg.CommandText, result)
+			}
+			return h.reply(ctx, msg.UserID, result)
+		}
+		h.logger.Info("Dropping pending %s for %s, got a new message instead", action.Command.Action, msg.UserID)
+	}
+
+	history := h.memory.History(msg.UserID)
+
+	// Tokens spent below are priced and recorded once the message is handled
+	ctx, usage := ai.WithUsage(ctx)
+	defer h.recordUsage(ctx, msg, usage)
+
+	// Strict forms like "done #3" skip the LLM entirely
+	cmds := []*ai.Command{}
+	source := "rules"
+	if cmd, ok := h.rules.Parse(msg.CommandText); ok {
+		cmds = append(cmds, cmd)
+	} else if reply := h.overBudget(ctx, msg.UserID); reply != "" {
+		return h.reply(ctx, msg.UserID, reply)
+	} else {
+		// A suspicious or bulk message gets one parse, whose changes wait for
+		// a YES, rather than a model free to call tools on its behalf
+		if h.agent != nil && !in.Suspicious() && !in.Bulk {
+			if handled, err := h.runAgent(ctx, msg, history); handled || err != nil {
+				return err
+			}
+			// The agent has no tool for this, like stats or export, so it's parsed instead
+		}
+
+		req := &ai.Request{
+			Message:  msg.CommandText,
+			History:  history,
+			Now:      time.Now(),
+			Location: h.location(msg.UserID),
+			Todos:    h.activeTodos(ctx, msg.UserID),
+		}
+		if cached, ok := h.cachedCommands(req); ok {
+			cmds, source = cached, "cache"
+		} else {
+			var err error
+			cmds, err = h.ai.ParseCommands(ctx, req)
+			source = "llm"
+			if ai.IsInvalidReply(err) {
+				// Not cached, so rephrasing it can do better
+				h.logger.Error("AI reply failed validation, asking the user to rephrase: %v", err)
+				cmds, err = []*ai.Command{{Action: "unclear"}}, nil
+			} else if err == nil && h.cache != nil {
+				h.cache.Put(req, cmds)
+			}
+			if err != nil && ai.IsUnavailable(err) {
+				// Requeueing would only hammer a struggling provider; the strict
+				// forms the rule parser knows still work in the meantime
+				h.logger.Error("AI unavailable, sending the fallback reply: %v", err)
+				return h.reply(ctx, msg.UserID, unavailableReply)
+			}
+			if err != nil && ai.IsRejected(err) {
+				h.logger.Error("AI rejected the request, sending the error reply: %v", err)
+				return h.reply(ctx, msg.UserID, rejectedReply)
+			}
+			if err != nil {
+				h.logger.Error("AI parsing failed: %v", err)
+				return fmt.Errorf("AI parsing failed: %w", err)
+			}
+		}
+	}
+
+	// Run the commands in order. A failure leaves the message to be redelivered;
+	// commands that already ran are deduplicated by their own idempotency keys.
+	results := make([]string, 0, len(cmds))
+	reasons := guard.Review(in, cmds)
+	for i, cmd := range cmds {
+		h.logger.Info("Parsed command %d/%d: source=%s action=%s confidence=%.2f explanation=%s (rule parser %s)",
+			i+1, len(cmds), source, cmd.Action, cmd.Confidence, cmd.Explanation, h.rules.Stats())
+
+		// "done with it" means the todo the conversation was just about.
+		// Earlier commands in this message count, as in "add X and mark it done".
+		if memory.Resolve(cmd, h.memory.History(msg.UserID)) {
+			h.logger.Info("Resolved reference to #%s from conversation", cmd.Parameters["todo_id"])
+		}
+
+		key := commandKey(msg.IdempotencyKey, i, len(cmds))
+
+		// Destructive or uncertain commands wait for a YES, as do those the
+		// guard won't take on the model's word. Only one question, or
+		// shortlist, can be open at a time, so later ones in the same message
+		// are skipped - see ask.
+		if reasons[i] != "" {
+			h.logger.Info("Command %d/%d needs confirmation: %s", i+1, len(cmds), reasons[i])
+		}
+		if reasons[i] != "" || h.confirm.NeedsConfirmation(cmd) {
+			prompt, err := h.confirmCommand(ctx, msg.UserID, key, cmd)
+			if err != nil {
+				h.logger.Error("Command %d/%d confirmation failed: %v", i+1, len(cmds), err)
+				return fmt.Errorf("command confirmation failed: %w", err)
+			}
+			if prompt != "" {
+				h.logger.Info("Asking %s to confirm %s (confidence %.2f)", msg.UserID, cmd.Action, cmd.Confidence)
+				results = append(results, prompt)
+				continue
+			}
+		}
+
+		result, err := h.executeCommand(ctx, msg.UserID, key, cmd)
+		if err != nil {
+			h.logger.Error("Command %d/%d execution failed: %v", i+1, len(cmds), err)
+			return fmt.Errorf("command execution failed: %w", err)
+		}
+
+		// If confidence is low, append explanation to help user understand limitations
+		if cmd.Confidence < lowConfidenceThreshold && cmd.Explanation != "" {
+			result = fmt.Sprintf("%s\n\n(Note: %s)", result, cmd.Explanation)
+		}
+		results = append(results, result)
+	}
+
+	result := strings.Join(results, "\n")
+	h.memory.AddTurn(msg.UserID, msg.CommandText, result)
+	return h.reply(ctx, msg.UserID, result)
+}
+
+// runAgent lets the model answer a message by calling todo operations itself.
+// Returns false, having replied nothing, when the model hands the message off.
+func (h *Handler) runAgent(ctx context.Context, msg *consumer.TextMessage, history *ai.History) (bool, error) {
+	result, err := h.agent.Run(ctx, &agent.Request{
+		UserID:         msg.UserID,
+		Message:        msg.CommandText,
+		IdempotencyKey: msg.IdempotencyKey,
+		Now:            time.Now(),
+		Location:       h.location(msg.UserID),
+		History:        history,
+	})
+	if err != nil && ai.IsUnavailable(err) && len(result.Transcript) == 0 {
+		h.logger.Error("AI unavailable, sending the fallback reply: %v", err)
+		return true, h.reply(ctx, msg.UserID, unavailableReply)
+	}
+	if err != nil && ai.IsRejected(err) {
+		// Tool calls already made are kept; the rest would be refused again
+		h.logger.Error("AI rejected the agent's request after %d tool calls, sending the error reply: %v", len(result.Transcript), err)
+		return true, h.reply(ctx, msg.UserID, rejectedReply)
+	}
 	if err != nil {
-		h.logger.Error("AI parsing failed: %v", err)
-		return fmt.Errorf("AI parsing failed: %w", err)
+		h.logger.Error("Agent failed after %d tool calls: %v", len(result.Transcript), err)
+		return true, fmt.Errorf("agent failed: %w", err)
 	}
+	if result.HandedOff {
+		h.logger.Info("Agent handed the message from %s off to the parser", msg.UserID)
+		return false, nil
+	}
+
+	reply := strings.Join(append([]string{result.Reply}, result.Held...), "\n")
+	h.memory.AddTurn(msg.UserID, msg.CommandText, reply)
+	return true, h.reply(ctx, msg.UserID, reply)
+}
 
-	h.logger.Info("Parsed command: action=%s confidence=%.2f explanation=%s",
-		cmd.Action, cmd.Confidence, cmd.Explanation)
+// holdAgentChange holds back the agent's changes a parsed command would wait
+// on too: those the guard or the confirmation policy want confirmed, and todos
+// like one already on the list. It returns what to send the user instead, or
+// "" to go ahead.
+func (h *Handler) holdAgentChange(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command, reason string) (string, error) {
+	if reason != "" || h.confirm.NeedsConfirmation(cmd) {
+		prompt, err := h.confirmCommand(ctx, userID, idempotencyKey, cmd)
+		if err != nil || prompt != "" {
+			h.logger.Info("Asking %s to confirm the agent's %s (confidence %.2f, reason %q)", userID, cmd.Action, cmd.Confidence, reason)
+			return prompt, err
+		}
+	}
+	if cmd.Action == "create" {
+		return h.checkDuplicate(ctx, userID, idempotencyKey, cmd)
+	}
+	return "", nil
+}
 
-	// Debug: log full parameters
-	if paramsJSON, err := json.Marshal(cmd.Parameters); err == nil {
-		fmt.Printf("[DEBUG] Parameters: %s\n", string(paramsJSON))
+// cachedCommands returns what the same context-free message parsed to before
+func (h *Handler) cachedCommands(req *ai.Request) ([]*ai.Command, bool) {
+	if h.cache == nil {
+		return nil, false
+	}
+	cmds, ok := h.cache.Get(req)
+	if ok {
+		h.logger.Info("LLM cache hit for %q (%s)", req.Message, h.cache.Stats())
 	}
+	return cmds, ok
+}
 
-	// Execute the command
-	result, err := h.executeCommand(ctx, msg.UserID, msg.IdempotencyKey, cmd)
+// overBudget returns the reply for a user who has spent their LLM budget, or
+// "" if they haven't. A failed lookup lets the message through.
+func (h *Handler) overBudget(ctx context.Context, userID string) string {
+	if h.budget == nil {
+		return ""
+	}
+	period, err := h.budget.Exceeded(ctx, userID, h.location(userID))
 	if err != nil {
-		h.logger.Error("Command execution failed: %v", err)
-		return fmt.Errorf("command execution failed: %w", err)
+		h.logger.Error("Failed to check LLM budget, allowing the call: %v", err)
+		return ""
+	}
+	switch period {
+	case budget.PeriodDay:
+		h.logger.Info("Daily LLM budget spent for %s, using rules only", userID)
+		return fmt.Sprintf(budgetReply, "today's", "tomorrow")
+	case budget.PeriodMonth:
+		h.logger.Info("Monthly LLM budget spent for %s, using rules only", userID)
+		return fmt.Sprintf(budgetReply, "this month's", "next month")
 	}
+	return ""
+}
 
-	// If confidence is low, append explanation to help user understand limitations
-	if cmd.Confidence < lowConfidenceThreshold && cmd.Explanation != "" {
-		result = fmt.Sprintf("%s\n\n(Note: %s)", result, cmd.Explanation)
+// recordUsage stores what a message's LLM calls cost. Accounting is best
+// effort, so a failure is logged rather than failing the message. A
+// redelivered message is recorded once, under its idempotency key.
+func (h *Handler) recordUsage(ctx context.Context, msg *consumer.TextMessage, usage *ai.Usage) {
+	if h.budget == nil || usage.Calls() == 0 {
+		return
 	}
+	cost, err := h.budget.Record(ctx, msg.UserID, msg.IdempotencyKey, usage)
+	if errors.Is(err, budget.ErrNoPrice) {
+		h.logger.Error("LLM usage recorded at no cost: %v", err)
+	} else if err != nil {
+		h.logger.Error("Failed to record LLM usage: %v", err)
+		return
+	}
+	promptTokens, completionTokens := usage.Tokens()
+	h.logger.Info("LLM usage for %s: %d calls to %s, %d+%d tokens, %s",
+		msg.UserID, usage.Calls(), usage.Model(), promptTokens, completionTokens, budget.FormatDollars(cost))
+}
+
+// activeTodos lists the user's active todos for the LLM prompt. The prompt
+// works without them, so a failure is logged rather than returned.
+func (h *Handler) activeTodos(ctx context.Context, userID string) []ai.TodoRef {
+	todos, err := h.domain.ListTodos(ctx, userID, domain.ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+	if err != nil {
+		h.logger.Error("Failed to list todos for the prompt: %v", err)
+		return nil
+	}
+
+	refs := make([]ai.TodoRef, len(todos))
+	for i, todo := range todos {
+		refs[i] = ai.TodoRef{ID: todo.Id, Title: todo.Title}
+	}
+	return refs
+}
 
+// commandKey returns the idempotency key for the i-th of n commands in one message.
+// A single command keeps the message key so replays of older messages still match.
+func commandKey(messageKey string, i, n int) string {
+	if n == 1 || messageKey == "" {
+		return messageKey
+	}
+	return idempotency.DeriveKey(messageKey, i)
+}
+
+// reply publishes a result to be sent to the user via SMS
+func (h *Handler) reply(ctx context.Context, userID, result string) error {
 	h.logger.Info("Command result: %s", result)
 
-	// Publish the result to be sent via SMS
-	if err := h.publisher.PublishReply(ctx, msg.UserID, result); err != nil {
+	if err := h.publisher.PublishReply(ctx, userID, result); err != nil {
 		h.logger.Error("Failed to publish reply: %v", err)
 		return fmt.Errorf("failed to publish reply: %w", err)
 	}
@@ -78,6 +527,214 @@ func (h *Handler) Handle(ctx context.Context, msg *consumer.TextMessage) error {
 	return nil
 }
 
+// requestConfirmation stores a command until the user replies YES or NO
+func (h *Handler) requestConfirmation(userID, idempotencyKey string, cmd *ai.Command, prompt string) (string, error) {
+	return h.confirmAction(userID, &pending.Action{Command: cmd, IdempotencyKey: idempotencyKey}, prompt), nil
+}
+
+// confirmAction asks a YES/NO question about an action
+func (h *Handler) confirmAction(userID string, action *pending.Action, prompt string) string {
+	return h.ask(userID, action, prompt+" Reply YES to confirm or NO to cancel.")
+}
+
+// ask stores an action until the user answers it and returns the question.
+// Only one can be open at a time, so a command asking while an earlier one
+// in the same message waits is skipped, to be sent again.
+func (h *Handler) ask(userID string, action *pending.Action, question string) string {
+	if !h.pending.Offer(userID, action) {
+		return fmt.Sprintf("(Skipped %s - answer the question above first, then send it again.)", action.Command.Action)
+	}
+	return question
+}
+
+// resolvePending executes or cancels a command the user was asked to confirm
+func (h *Handler) resolvePending(ctx context.Context, userID string, action *pending.Action, reply pending.Reply) (string, error) {
+	if reply == pending.ReplyNo {
+		return "OK, cancelled.", nil
+	}
+	if len(action.Steps) > 0 {
+		return h.addSteps(ctx, userID, action, nil)
+	}
+	if action.Duplicate != 0 {
+		// The user saw the todo it duplicates and wants it anyway
+		return h.addTodo(ctx, userID, action.IdempotencyKey, action.Command)
+	}
+
+	switch action.Command.Action {
+	case "erase_account":
+		return h.eraseAccount(ctx, userID, action.IdempotencyKey)
+	case "empty_trash":
+		return h.emptyTrash(ctx, userID, action.IdempotencyKey)
+	default:
+		// A command held back by the confirmation policy
+		return h.executeCommand(ctx, userID, action.IdempotencyKey, action.Command)
+	}
+}
+
+// resolveChoice runs a command on the todo the user picked from a shortlist.
+// Picking is an explicit choice, so it isn't confirmed again.
+func (h *Handler) resolveChoice(ctx context.Context, userID string, action *pending.Action, choice int) (string, error) {
+	cmd := pinCommand(action.Command, action.Choices[choice-1])
+	return h.executeCommand(ctx, userID, action.IdempotencyKey, cmd)
+}
+
+// matchTitle finds the todo a title hint refers to, or nil if none matches.
+// Unless one todo is a clear match the user is asked instead - to pick from
+// a numbered shortlist, or to confirm a single loose match - and the command
+// waits for their answer. The question is returned as the reply.
+func (h *Handler) matchTitle(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command, hint string) (*todov1.Todo, string, error) {
+	var matches []domain.TitleMatch
+	var err error
+	if cmd.Action == "restore" {
+		matches, err = h.domain.FindDeletedTodosByTitle(ctx, userID, hint)
+	} else {
+		matches, err = h.domain.FindTodosByTitle(ctx, userID, hint)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, "", nil
+	case isClearMatch(matches):
+		return matches[0].Todo, "", nil
+	case len(matches) == 1:
+		todo := matches[0].Todo
+		question, err := h.requestConfirmation(userID, idempotencyKey, pinCommand(cmd, todo.Id),
+			fmt.Sprintf("Did you mean #%d '%s'?", todo.Id, todo.Title))
+		return nil, question, err
+	}
+
+	shown := matches
+	if len(shown) > maxChoices {
+		shown = shown[:maxChoices]
+	}
+	todos := make([]*todov1.Todo, len(shown))
+	choices := make([]int64, len(shown))
+	for i, m := range shown {
+		todos[i] = m.Todo
+		choices[i] = m.Todo.Id
+	}
+	action := &pending.Action{Command: cmd, IdempotencyKey: idempotencyKey, Choices: choices}
+	return nil, h.ask(userID, action, formatShortlist(hint, todos, len(matches))), nil
+}
+
+// isClearMatch reports whether the best match is good enough to act on
+// without asking: it scores well and clearly beats the runner-up
+func isClearMatch(matches []domain.TitleMatch) bool {
+	if len(matches) == 0 || matches[0].Score < clearMatchScore {
+		return false
+	}
+	return len(matches) == 1 || matches[0].Score-matches[1].Score >= clearMatchMargin
+}
+
+// formatShortlist lists the todos a hint matched for the user to pick from
+func formatShortlist(hint string, shown []*todov1.Todo, total int) string {
+	result := fmt.Sprintf("Which one did you mean by '%s'?\n", hint)
+	for i, todo := range shown {
+		result += fmt.Sprintf("%d) %s (#%d)\n", i+1, todo.Title, todo.Id)
+	}
+	if total > len(shown) {
+		result += fmt.Sprintf("...and %d more. ", total-len(shown))
+	}
+	result += "Reply with a number, or NO to cancel."
+
+	return result
+}
+
+// pinCommand copies a command, pointing it at one todo instead of a title hint
+func pinCommand(cmd *ai.Command, todoID int64) *ai.Command {
+	pinned := &ai.Command{
+		Action:      cmd.Action,
+		Parameters:  make(map[string]string, len(cmd.Parameters)+1),
+		Confidence:  cmd.Confidence,
+		Explanation: cmd.Explanation,
+	}
+	for k, v := range cmd.Parameters {
+		pinned.Parameters[k] = v
+	}
+	pinned.Parameters["todo_id"] = strconv.FormatInt(todoID, 10)
+	delete(pinned.Parameters, "title_hint")
+
+	return pinned
+}
+
+// confirmCommand asks the user to confirm a command before it runs, naming
+// the exact todo it will change. The command is pinned to that todo so a YES
+// acts on what the user saw. Returns "" when there is nothing to confirm,
+// e.g. no todo matches, and the command should run as usual.
+func (h *Handler) confirmCommand(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	if cmd.Action == "create" {
+		title := cmd.Parameters["title"]
+		if title == "" {
+			return "", nil
+		}
+		return h.requestConfirmation(userID, idempotencyKey, cmd, fmt.Sprintf("Add '%s'?", title))
+	}
+
+	todo, question, err := h.findTarget(ctx, userID, idempotencyKey, cmd)
+	if err != nil || question != "" {
+		// Answering which todo was meant is confirmation enough
+		return question, err
+	}
+	if todo == nil {
+		// An unconfirmed command must not run on an id nobody has seen
+		if idStr := cmd.Parameters["todo_id"]; idStr != "" {
+			return fmt.Sprintf("I couldn't find #%s.", idStr), nil
+		}
+		return "", nil
+	}
+
+	var prompt string
+	switch cmd.Action {
+	case "complete":
+		prompt = fmt.Sprintf("Mark #%d '%s' as done?", todo.Id, todo.Title)
+	case "delete":
+		prompt = fmt.Sprintf("Delete #%d '%s'?", todo.Id, todo.Title)
+	case "restore":
+		prompt = fmt.Sprintf("Restore #%d '%s'?", todo.Id, todo.Title)
+	case "edit":
+		newTitle := cmd.Parameters["new_title"]
+		if newTitle == "" {
+			prompt = fmt.Sprintf("Update the notes on #%d '%s'?", todo.Id, todo.Title)
+		} else {
+			prompt = fmt.Sprintf("Rename #%d '%s' to '%s'?", todo.Id, todo.Title, newTitle)
+		}
+	default:
+		return "", nil
+	}
+
+	h.memory.Touch(userID, todo.Id)
+	return h.requestConfirmation(userID, idempotencyKey, pinCommand(cmd, todo.Id), prompt)
+}
+
+// findTarget looks up the todo a command refers to by id or title hint, or nil
+// if none matches. A question is returned instead when the hint isn't a clear match.
+// Ids are looked up among the todos the action works on: the trash for a
+// restore, active todos to complete or break down, and any other todo not deleted.
+func (h *Handler) findTarget(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (*todov1.Todo, string, error) {
+	if idStr := cmd.Parameters["todo_id"]; idStr != "" {
+		if todoID, err := strconv.ParseInt(idStr, 10, 64); err == nil {
+			status := todov1.TodoStatus_TODO_STATUS_UNSPECIFIED
+			switch cmd.Action {
+			case "restore":
+				status = todov1.TodoStatus_TODO_STATUS_DELETED
+			case "complete", "breakdown":
+				status = todov1.TodoStatus_TODO_STATUS_ACTIVE
+			}
+			todo, err := h.domain.FindTodoByID(ctx, userID, todoID, status)
+			return todo, "", err
+		}
+	}
+
+	if hint := cmd.Parameters["title_hint"]; hint != "" {
+		return h.matchTitle(ctx, userID, idempotencyKey, cmd, hint)
+	}
+
+	return nil, "", nil
+}
+
 // executeCommand runs the appropriate action based on the parsed command
 func (h *Handler) executeCommand(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
 	switch cmd.Action {
@@ -89,10 +746,23 @@ func (h *Handler) executeCommand(ctx context.Context, userID, idempotencyKey str
 		return h.handleList(ctx, userID, cmd)
 	case "delete":
 		return h.handleDelete(ctx, userID, idempotencyKey, cmd)
+	case "restore":
+		return h.handleRestore(ctx, userID, idempotencyKey, cmd)
+	case "empty_trash":
+		return h.handleEmptyTrash(ctx, userID, idempotencyKey, cmd)
 	case "edit":
 		return h.handleEdit(ctx, userID, idempotencyKey, cmd)
 	case "nudge":
 		return h.handleNudge(ctx, userID, cmd)
+	case "breakdown":
+		return h.handleBreakdown(ctx, userID, idempotencyKey, cmd)
+	case "stats":
+		return h.handleStats(ctx, userID)
+	case "export_data":
+		return h.handleExport(ctx, userID)
+	case "erase_account":
+		return h.requestConfirmation(userID, idempotencyKey, cmd,
+			"This will permanently delete all your todos, history, voice memos and data exports.")
 	case "unclear":
 		return h.handleUnclear(cmd)
 	default:
@@ -101,21 +771,70 @@ func (h *Handler) executeCommand(ctx context.Context, userID, idempotencyKey str
 }
 
 func (h *Handler) handleCreate(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
-	title := cmd.Parameters["title"]
-	description := cmd.Parameters["description"]
-
-	if title == "" {
+	if cmd.Parameters["title"] == "" {
 		return "I couldn't figure out what to add. What would you like me to remember?", nil
 	}
 
-	todo, err := h.domain.CreateTodo(ctx, userID, title, description, idempotencyKey)
+	if reply, err := h.checkDuplicate(ctx, userID, idempotencyKey, cmd); err != nil || reply != "" {
+		return reply, err
+	}
+	return h.addTodo(ctx, userID, idempotencyKey, cmd)
+}
+
+// addTodo creates the todo a create asks for, sorted into its category
+func (h *Handler) addTodo(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	title := cmd.Parameters["title"]
+	todo, err := h.domain.CreateTodo(ctx, userID, title, cmd.Parameters["description"], h.categories.Classify(title), idempotencyKey)
 	if err != nil {
 		return "", err
 	}
 
+	h.memory.Touch(userID, todo.Id)
 	return fmt.Sprintf("Added #%d: %s", todo.Id, todo.Title), nil
 }
 
+// checkDuplicate looks for a todo like the one a create would add. One added
+// moments ago is kept instead of adding another, and an older one is asked
+// about. Returns what to reply instead of adding it, or "" to go ahead.
+func (h *Handler) checkDuplicate(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	title := cmd.Parameters["title"]
+	if h.duplicate == 0 || title == "" {
+		return "", nil
+	}
+
+	existing, err := h.findDuplicate(ctx, userID, title)
+	if err != nil || existing == nil {
+		return "", err
+	}
+
+	h.memory.Touch(userID, existing.Id)
+	if time.Since(existing.CreatedAt.AsTime()) < h.merge {
+		h.logger.Info("Merged %q into #%d for %s, added %s ago", title, existing.Id, userID,
+			time.Since(existing.CreatedAt.AsTime()).Round(time.Second))
+		return fmt.Sprintf("#%d %s is already on your list, you added it a moment ago.", existing.Id, existing.Title), nil
+	}
+	action := &pending.Action{Command: cmd, IdempotencyKey: idempotencyKey, Duplicate: existing.Id}
+	return h.confirmAction(userID, action, fmt.Sprintf("You already have #%d %s — add anyway?", existing.Id, existing.Title)), nil
+}
+
+// findDuplicate returns the active todo most like a new title, or nil if
+// none is similar enough to be the same thing
+func (h *Handler) findDuplicate(ctx context.Context, userID, title string) (*todov1.Todo, error) {
+	todos, err := h.domain.ListTodos(ctx, userID, domain.ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+	if err != nil {
+		return nil, err
+	}
+
+	var best *todov1.Todo
+	bestScore := h.duplicate
+	for _, todo := range todos {
+		if score := match.Similarity(title, todo.Title); score >= bestScore {
+			best, bestScore = todo, score
+		}
+	}
+	return best, nil
+}
+
 func (h *Handler) handleComplete(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
 	// Try to get todo by ID first
 	if idStr := cmd.Parameters["todo_id"]; idStr != "" {
@@ -125,15 +844,16 @@ func (h *Handler) handleComplete(ctx context.Context, userID, idempotencyKey str
 			if err != nil {
 				return "", err
 			}
+			h.memory.Touch(userID, todo.Id)
 			return fmt.Sprintf("Completed #%d: %s ✓", todo.Id, todo.Title), nil
 		}
 	}
 
 	// Try to find by title hint
 	if hint := cmd.Parameters["title_hint"]; hint != "" {
-		todo, err := h.domain.FindTodoByTitle(ctx, userID, hint)
-		if err != nil {
-			return "", err
+		todo, question, err := h.matchTitle(ctx, userID, idempotencyKey, cmd, hint)
+		if err != nil || question != "" {
+			return question, err
 		}
 		if todo == nil {
 			return fmt.Sprintf("I couldn't find a todo matching '%s'", hint), nil
@@ -143,6 +863,7 @@ func (h *Handler) handleComplete(ctx context.Context, userID, idempotencyKey str
 		if err != nil {
 			return "", err
 		}
+		h.memory.Touch(userID, completed.Id)
 		return fmt.Sprintf("Completed #%d: %s ✓", completed.Id, completed.Title), nil
 	}
 
@@ -160,6 +881,8 @@ func (h *Handler) handleList(ctx context.Context, userID string, cmd *ai.Command
 		filter.Status = todov1.TodoStatus_TODO_STATUS_COMPLETED
 	case "all":
 		filter.Status = todov1.TodoStatus_TODO_STATUS_UNSPECIFIED
+	case "trash", "deleted":
+		filter.Status = todov1.TodoStatus_TODO_STATUS_DELETED
 	default:
 		filter.Status = todov1.TodoStatus_TODO_STATUS_ACTIVE
 	}
@@ -176,19 +899,42 @@ func (h *Handler) handleList(ctx context.Context, userID string, cmd *ai.Command
 		}
 	}
 
+	if name := cmd.Parameters["category"]; name != "" {
+		found, ok := h.categories.Lookup(name)
+		if !ok {
+			return fmt.Sprintf("I don't sort todos into %s. Try one of: %s.", name, strings.Join(h.categories.Names(), ", ")), nil
+		}
+		filter.Category = found
+	}
+
 	todos, err := h.domain.ListTodos(ctx, userID, filter)
 	if err != nil {
 		return "", err
 	}
 
+	if filter.Status == todov1.TodoStatus_TODO_STATUS_DELETED {
+		return formatTrash(todos, time.Now()), nil
+	}
+
 	if len(todos) == 0 {
 		if filter.CompletedAfter != nil || filter.CompletedBefore != nil {
 			return "No completed todos found in that time range.", nil
 		}
+		if filter.Category != "" {
+			return fmt.Sprintf("Nothing in %s right now.", filter.Category), nil
+		}
 		return "Your list is empty! Text me something to remember.", nil
 	}
 
+	// With a single todo listed, "done with it" can only mean that one
+	if len(todos) == 1 {
+		h.memory.Touch(userID, todos[0].Id)
+	}
+
 	result := "Your todos:\n"
+	if filter.Category != "" {
+		result = fmt.Sprintf("Your %s:\n", filter.Category)
+	}
 	for _, todo := range todos {
 		statusMark := ""
 		if todo.Status == todov1.TodoStatus_TODO_STATUS_COMPLETED {
@@ -209,15 +955,16 @@ func (h *Handler) handleDelete(ctx context.Context, userID, idempotencyKey strin
 			if err != nil {
 				return "", err
 			}
+			h.memory.Touch(userID, todo.Id)
 			return fmt.Sprintf("Deleted #%d: %s", todo.Id, todo.Title), nil
 		}
 	}
 
 	// Try to find by title hint
 	if hint := cmd.Parameters["title_hint"]; hint != "" {
-		todo, err := h.domain.FindTodoByTitle(ctx, userID, hint)
-		if err != nil {
-			return "", err
+		todo, question, err := h.matchTitle(ctx, userID, idempotencyKey, cmd, hint)
+		if err != nil || question != "" {
+			return question, err
 		}
 		if todo == nil {
 			return fmt.Sprintf("I couldn't find a todo matching '%s'", hint), nil
@@ -227,12 +974,89 @@ func (h *Handler) handleDelete(ctx context.Context, userID, idempotencyKey strin
 		if err != nil {
 			return "", err
 		}
+		h.memory.Touch(userID, deleted.Id)
 		return fmt.Sprintf("Deleted #%d: %s", deleted.Id, deleted.Title), nil
 	}
 
 	return "Which todo do you want to delete? Give me a number or describe it.", nil
 }
 
+// formatTrash lists deleted todos with how long ago each was deleted
+func formatTrash(todos []*todov1.Todo, now time.Time) string {
+	if len(todos) == 0 {
+		return "Your trash is empty."
+	}
+
+	result := "Trash:\n"
+	for _, todo := range todos {
+		deleted := ""
+		if todo.DeletedAt != nil {
+			deleted = fmt.Sprintf(" (deleted %s ago)", formatDuration(now.Sub(todo.DeletedAt.AsTime())))
+		}
+		result += fmt.Sprintf("#%d: %s%s\n", todo.Id, todo.Title, deleted)
+	}
+	result += "Reply 'restore #N' to bring one back."
+
+	return result
+}
+
+func (h *Handler) handleRestore(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	// Try to get todo by ID first
+	if idStr := cmd.Parameters["todo_id"]; idStr != "" {
+		todoID, err := strconv.ParseInt(idStr, 10, 64)
+		if err == nil {
+			todo, err := h.domain.RestoreTodo(ctx, todoID, userID, idempotencyKey)
+			if err != nil {
+				return "", err
+			}
+			h.memory.Touch(userID, todo.Id)
+			return fmt.Sprintf("Restored #%d: %s", todo.Id, todo.Title), nil
+		}
+	}
+
+	// Try to find by title hint
+	if hint := cmd.Parameters["title_hint"]; hint != "" {
+		todo, question, err := h.matchTitle(ctx, userID, idempotencyKey, cmd, hint)
+		if err != nil || question != "" {
+			return question, err
+		}
+		if todo == nil {
+			return fmt.Sprintf("I couldn't find anything in your trash matching '%s'", hint), nil
+		}
+
+		restored, err := h.domain.RestoreTodo(ctx, todo.Id, userID, idempotencyKey)
+		if err != nil {
+			return "", err
+		}
+		h.memory.Touch(userID, restored.Id)
+		return fmt.Sprintf("Restored #%d: %s", restored.Id, restored.Title), nil
+	}
+
+	return "Which todo do you want to restore? Text 'show trash' to see deleted todos.", nil
+}
+
+func (h *Handler) handleEmptyTrash(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	trash, err := h.domain.ListTodos(ctx, userID, domain.ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_DELETED})
+	if err != nil {
+		return "", err
+	}
+	if len(trash) == 0 {
+		return "Your trash is already empty.", nil
+	}
+
+	return h.requestConfirmation(userID, idempotencyKey, cmd,
+		fmt.Sprintf("This will permanently delete %s in your trash.", pluralize(len(trash), "todo")))
+}
+
+func (h *Handler) emptyTrash(ctx context.Context, userID, idempotencyKey string) (string, error) {
+	deleted, err := h.domain.EmptyTrash(ctx, userID, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Trash emptied. %s permanently deleted.", pluralize(int(deleted), "todo")), nil
+}
+
 func (h *Handler) handleEdit(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
 	newTitle := cmd.Parameters["new_title"]
 	newDescription := cmd.Parameters["new_description"]
@@ -245,19 +1069,37 @@ func (h *Handler) handleEdit(ctx context.Context, userID, idempotencyKey string,
 	if idStr := cmd.Parameters["todo_id"]; idStr != "" {
 		todoID, err := strconv.ParseInt(idStr, 10, 64)
 		if err == nil {
+			// The domain sets both fields, so an omitted one is sent as it is now
+			if newTitle == "" || newDescription == "" {
+				todo, err := h.domain.FindTodoByID(ctx, userID, todoID, todov1.TodoStatus_TODO_STATUS_UNSPECIFIED)
+				if err != nil {
+					return "", err
+				}
+				if todo == nil {
+					return fmt.Sprintf("I couldn't find #%s.", idStr), nil
+				}
+				if newTitle == "" {
+					newTitle = todo.Title
+				}
+				if newDescription == "" {
+					newDescription = todo.Description
+				}
+			}
+
 			todo, err := h.domain.EditTodo(ctx, todoID, userID, newTitle, newDescription, idempotencyKey)
 			if err != nil {
 				return "", err
 			}
+			h.memory.Touch(userID, todo.Id)
 			return fmt.Sprintf("Updated #%d: %s", todo.Id, todo.Title), nil
 		}
 	}
 
 	// Try to find by title hint
 	if hint := cmd.Parameters["title_hint"]; hint != "" {
-		todo, err := h.domain.FindTodoByTitle(ctx, userID, hint)
-		if err != nil {
-			return "", err
+		todo, question, err := h.matchTitle(ctx, userID, idempotencyKey, cmd, hint)
+		if err != nil || question != "" {
+			return question, err
 		}
 		if todo == nil {
 			return fmt.Sprintf("I couldn't find a todo matching '%s'", hint), nil
@@ -275,6 +1117,7 @@ func (h *Handler) handleEdit(ctx context.Context, userID, idempotencyKey string,
 		if err != nil {
 			return "", err
 		}
+		h.memory.Touch(userID, edited.Id)
 		return fmt.Sprintf("Updated #%d: %s", edited.Id, edited.Title), nil
 	}
 
@@ -285,9 +1128,17 @@ func (h *Handler) handleNudge(ctx context.Context, userID string, cmd *ai.Comman
 	suggestedAction := cmd.Parameters["suggested_action"]
 	taskContext := cmd.Parameters["task_context"]
 
+	// "I can't choose what to do" names no task - pick one from their list
+	if cmd.Parameters["todo_id"] == "" && cmd.Parameters["title_hint"] == "" && taskContext == "" {
+		return h.pickNudge(ctx, userID)
+	}
+
 	if suggestedAction == "" {
 		return "I'm not sure how to help with that. What are you trying to start?", nil
 	}
+	if todoID, err := strconv.ParseInt(cmd.Parameters["todo_id"], 10, 64); err == nil {
+		h.nudges.Add(userID, todoID)
+	}
 
 	// The LLM already generated the nudge - just return it
 	response := suggestedAction
@@ -300,6 +1151,214 @@ func (h *Handler) handleNudge(ctx context.Context, userID string, cmd *ai.Comman
 	return response, nil
 }
 
+// pickNudge chooses the active todo most worth starting - overdue, high
+// priority, long open, small, or like ones the user usually finishes - and
+// suggests a first step for it. Todos suggested recently are passed over.
+func (h *Handler) pickNudge(ctx context.Context, userID string) (string, error) {
+	todos, err := h.domain.ListTodos(ctx, userID, domain.ListTodosFilter{Status: todov1.TodoStatus_TODO_STATUS_ACTIVE})
+	if err != nil {
+		return "", err
+	}
+	if len(todos) == 0 {
+		return "Your list is empty - nothing to get stuck on! Text me something to remember.", nil
+	}
+
+	now := time.Now().In(h.location(userID))
+	since := now.Add(-nudgePatternWindow)
+	completed, err := h.domain.ListTodos(ctx, userID, domain.ListTodosFilter{
+		Status:         todov1.TodoStatus_TODO_STATUS_COMPLETED,
+		CompletedAfter: &since,
+	})
+	if err != nil {
+		// Ranking works without the history, just less personally
+		h.logger.Error("Failed to list completed todos for the nudge: %v", err)
+	}
+
+	pick := nudge.Pick(todos, nudge.Learn(completed), h.nudges.IDs(userID), now)
+	h.nudges.Add(userID, pick.Todo.Id)
+	h.memory.Touch(userID, pick.Todo.Id)
+	h.logger.Info("Nudging %s toward #%d (score %.2f, reason %q)", userID, pick.Todo.Id, pick.Score, pick.Reason)
+
+	step := h.firstStep(ctx, userID, pick.Todo, now)
+	if pick.Reason != "" {
+		return fmt.Sprintf("Try #%d %s (%s). First step: %s", pick.Todo.Id, pick.Todo.Title, pick.Reason, step), nil
+	}
+	return fmt.Sprintf("Try #%d %s. First step: %s", pick.Todo.Id, pick.Todo.Title, step), nil
+}
+
+// firstStep asks the LLM for a tiny first step toward a todo, the way it
+// answers "nudge me on #3". A generic step is used if it can't give one.
+func (h *Handler) firstStep(ctx context.Context, userID string, todo *todov1.Todo, now time.Time) string {
+	cmds, err := h.ai.ParseCommands(ctx, &ai.Request{
+		Message:  fmt.Sprintf("nudge me on #%d", todo.Id),
+		Now:      now,
+		Location: now.Location(),
+		Todos:    []ai.TodoRef{{ID: todo.Id, Title: todo.Title}},
+	})
+	if err != nil {
+		h.logger.Error("Failed to generate a first step for #%d: %v", todo.Id, err)
+		return nudge.FallbackStep
+	}
+	for _, cmd := range cmds {
+		if cmd.Action == "nudge" && cmd.Parameters["suggested_action"] != "" {
+			return cmd.Parameters["suggested_action"]
+		}
+	}
+	return nudge.FallbackStep
+}
+
+// handleBreakdown shows the steps a todo was split into and waits for the user
+// to say which to add. The steps are kept with the pending action until then.
+func (h *Handler) handleBreakdown(ctx context.Context, userID, idempotencyKey string, cmd *ai.Command) (string, error) {
+	steps := ai.SplitSteps(cmd.Parameters["steps"])
+	if len(steps) == 0 {
+		return "I couldn't come up with steps for that. Which todo should I break down?", nil
+	}
+
+	todo, question, err := h.findTarget(ctx, userID, idempotencyKey, cmd)
+	if err != nil || question != "" {
+		return question, err
+	}
+	if todo == nil {
+		return "I couldn't find that todo. Which one should I break down? Give me a number or describe it.", nil
+	}
+
+	h.memory.Touch(userID, todo.Id)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Steps for #%d %s:\n", todo.Id, todo.Title)
+	for i, step := range steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	b.WriteString("Reply YES to add them all, NO to skip, or say which to keep, like \"keep 1, 2 and 4\".")
+	action := &pending.Action{Command: pinCommand(cmd, todo.Id), IdempotencyKey: idempotencyKey, Steps: steps}
+	return h.ask(userID, action, b.String()), nil
+}
+
+// addSteps creates the picked steps of a breakdown as todos under the one
+// broken down, every step if picks is empty. Each step has its own key, so
+// a redelivered reply doesn't add any twice.
+func (h *Handler) addSteps(ctx context.Context, userID string, action *pending.Action, picks []int) (string, error) {
+	steps := action.Steps
+	parentID, err := strconv.ParseInt(action.Command.Parameters["todo_id"], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("breakdown without a todo: %w", err)
+	}
+	if len(picks) == 0 {
+		for i := range steps {
+			picks = append(picks, i+1)
+		}
+	}
+
+	lines := make([]string, 0, len(picks))
+	for _, pick := range picks {
+		key := commandKey(action.IdempotencyKey, pick-1, len(steps))
+		todo, err := h.domain.CreateStep(ctx, userID, parentID, steps[pick-1], h.categories.Classify(steps[pick-1]), key)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("#%d: %s", todo.Id, todo.Title))
+	}
+
+	h.memory.Touch(userID, parentID)
+	return fmt.Sprintf("Added %s to #%d:\n%s", pluralize(len(picks), "step"), parentID, strings.Join(lines, "\n")), nil
+}
+
+func (h *Handler) handleStats(ctx context.Context, userID string) (string, error) {
+	stats, err := h.domain.GetStats(ctx, userID, h.location(userID).String())
+	if err != nil {
+		return "", err
+	}
+
+	return formatStats(stats, time.Now()), nil
+}
+
+// formatStats builds a short SMS summary of a user's completion stats
+func formatStats(stats *todov1.GetStatsResponse, now time.Time) string {
+	if stats.TotalCompleted == 0 && stats.TotalOpen == 0 {
+		return "No stats yet! Add a todo and finish it to get started."
+	}
+
+	var today, thisWeek int32
+	if n := len(stats.Daily); n > 0 {
+		today = stats.Daily[n-1].Completed
+	}
+	if n := len(stats.Weekly); n > 0 {
+		thisWeek = stats.Weekly[n-1].Completed
+	}
+
+	result := "Your stats:\n"
+	result += fmt.Sprintf("Done today: %d, this week: %d\n", today, thisWeek)
+	result += fmt.Sprintf("Streak: %s (best: %s)\n", pluralize(int(stats.CurrentStreak), "day"), pluralize(int(stats.LongestStreak), "day"))
+
+	if stats.AverageCompletionSeconds > 0 {
+		avg := time.Duration(stats.AverageCompletionSeconds) * time.Second
+		result += fmt.Sprintf("Avg time to finish: %s\n", formatDuration(avg))
+	}
+
+	if len(stats.OldestOpen) > 0 {
+		result += "Oldest open:\n"
+		for _, todo := range stats.OldestOpen {
+			age := now.Sub(todo.CreatedAt.AsTime())
+			result += fmt.Sprintf("#%d: %s (%s)\n", todo.Id, todo.Title, formatDuration(age))
+		}
+	}
+
+	return result
+}
+
+// formatDuration renders a duration in the largest sensible unit for SMS
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return pluralize(int(d.Minutes()), "min")
+	case d < 48*time.Hour:
+		return pluralize(int(d.Hours()), "hour")
+	default:
+		return pluralize(int(d.Hours()/24), "day")
+	}
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func (h *Handler) handleExport(ctx context.Context, userID string) (string, error) {
+	filename, data, err := h.domain.ExportUserData(ctx, userID, todov1.ExportFormat_EXPORT_FORMAT_ZIP)
+	if err != nil {
+		return "", err
+	}
+
+	// Too big for a text, so the reply links to it instead. The link is new
+	// on every delivery, so a redelivered message just sends a second one.
+	link, err := h.exports.Save(userID, filename, data)
+	if err != nil {
+		return "", err
+	}
+
+	h.logger.Info("Wrote data export (%d bytes)", len(data))
+	return fmt.Sprintf("Your data export is ready (%d KB). Download it in the next %s: %s",
+		(len(data)+1023)/1024, formatDuration(h.exports.TTL()), link), nil
+}
+
+func (h *Handler) eraseAccount(ctx context.Context, userID, idempotencyKey string) (string, error) {
+	// Exports are copies of everything being erased, so they go first
+	if _, err := h.exports.DeleteUser(userID); err != nil {
+		return "", err
+	}
+
+	resp, err := h.domain.EraseUserData(ctx, userID, todov1.EraseMode_ERASE_MODE_HARD_DELETE, idempotencyKey)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Done. I've deleted %d todos and %d voice memos, and forgotten your history. Text me anytime to start fresh.",
+		resp.TodosErased, resp.TranscriptsErased), nil
+}
+
 func (h *Handler) handleUnclear(cmd *ai.Command) (string, error) {
 	reason := cmd.Parameters["reason"]
 	if reason != "" {
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	todov1 "hound-todo/api/todo/v1"
 	"hound-todo/services/command/internal/ai"
 	"hound-todo/services/command/internal/consumer"
 	"hound-todo/services/command/internal/domain"
+	"hound-todo/services/command/internal/pending"
+	"hound-todo/shared/logging"
 )
 
 // =============================================================================
@@ -79,7 +85,7 @@ func TestCommandStruct(t *testing.T) {
 }
 
 func TestCommandActions(t *testing.T) {
-	validActions := []string{"create", "complete", "list", "delete", "edit", "nudge", "unclear"}
+	validActions := []string{"create", "complete", "list", "delete", "restore", "empty_trash", "edit", "nudge", "stats", "export_data", "erase_account", "unclear"}
 
 	for _, action := range validActions {
 		cmd := &ai.Command{Action: action}
@@ -101,6 +107,7 @@ func TestListTodosFilter(t *testing.T) {
 		{"active", todov1.TodoStatus_TODO_STATUS_ACTIVE},
 		{"completed", todov1.TodoStatus_TODO_STATUS_COMPLETED},
 		{"unspecified", todov1.TodoStatus_TODO_STATUS_UNSPECIFIED},
+		{"deleted", todov1.TodoStatus_TODO_STATUS_DELETED},
 	}
 
 	for _, tt := range tests {
@@ -641,3 +648,282 @@ func TestUnclearResponseFormatting(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Stats Formatting Tests
+// =============================================================================
+
+func TestFormatStats(t *testing.T) {
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	stats := &todov1.GetStatsResponse{
+		Daily:                    []*todov1.DayCount{{Date: "2026-01-20", Completed: 4}, {Date: "2026-01-21", Completed: 2}},
+		Weekly:                   []*todov1.WeekCount{{WeekStart: "2026-01-19", Completed: 6}},
+		CurrentStreak:            3,
+		LongestStreak:            1,
+		AverageCompletionSeconds: 36 * 60 * 60,
+		OldestOpen: []*todov1.Todo{
+			{Id: 4, Title: "call mom", CreatedAt: timestamppb.New(now.Add(-12 * 24 * time.Hour))},
+		},
+		TotalCompleted: 6,
+		TotalOpen:      1,
+	}
+
+	result := formatStats(stats, now)
+
+	expected := "Your stats:\n" +
+		"Done today: 2, this week: 6\n" +
+		"Streak: 3 days (best: 1 day)\n" +
+		"Avg time to finish: 36 hours\n" +
+		"Oldest open:\n" +
+		"#4: call mom (12 days)\n"
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatStats_Empty(t *testing.T) {
+	result := formatStats(&todov1.GetStatsResponse{}, time.Now())
+
+	if result != "No stats yet! Add a todo and finish it to get started." {
+		t.Errorf("unexpected empty stats message: %s", result)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{1 * time.Minute, "1 min"},
+		{45 * time.Minute, "45 mins"},
+		{1 * time.Hour, "1 hour"},
+		{47 * time.Hour, "47 hours"},
+		{72 * time.Hour, "3 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := formatDuration(tt.duration); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Confirmation Tests
+// =============================================================================
+
+func TestRequestConfirmation_StoresPendingAction(t *testing.T) {
+	h := New(nil, nil, nil, logging.New("test"))
+	cmd := &ai.Command{Action: "erase_account"}
+
+	result, err := h.requestConfirmation("+15551234567", "idem_abc", cmd, "This will delete everything.")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "This will delete everything. Reply YES to confirm or NO to cancel." {
+		t.Errorf("unexpected prompt: %s", result)
+	}
+	action, _ := h.pending.Take("+15551234567")
+	if action == nil || action.Command != cmd || action.IdempotencyKey != "idem_abc" {
+		t.Errorf("expected pending action to be stored, got %+v", action)
+	}
+}
+
+func TestResolvePending_No(t *testing.T) {
+	h := New(nil, nil, nil, logging.New("test"))
+	action := &pending.Action{Command: &ai.Command{Action: "erase_account"}}
+
+	result, err := h.resolvePending(context.Background(), "+15551234567", action, pending.ReplyNo)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "OK, cancelled." {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestResolvePending_UnknownAction(t *testing.T) {
+	h := New(nil, nil, nil, logging.New("test"))
+	action := &pending.Action{Command: &ai.Command{Action: "launch_rockets"}}
+
+	_, err := h.resolvePending(context.Background(), "+15551234567", action, pending.ReplyYes)
+
+	if err == nil {
+		t.Error("expected error for unknown pending action")
+	}
+}
+
+func TestSetConfirmThresholds(t *testing.T) {
+	h := New(nil, nil, nil, logging.New("test"))
+
+	if err := h.SetConfirmThresholds(map[string]float64{"delete": pending.Always, "create": 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !h.confirm.NeedsConfirmation(&ai.Command{Action: "delete", Confidence: 1.0}) {
+		t.Error("expected delete to always need confirmation")
+	}
+	if h.confirm.NeedsConfirmation(&ai.Command{Action: "create", Confidence: 0.1}) {
+		t.Error("expected create to never need confirmation")
+	}
+	if !h.confirm.NeedsConfirmation(&ai.Command{Action: "complete", Confidence: 0.5}) {
+		t.Error("expected unlisted actions to keep their default thresholds")
+	}
+
+	if err := h.SetConfirmThresholds(map[string]float64{"launch_rockets": 0.5}); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestConfirmCommand_Create(t *testing.T) {
+	h := New(nil, nil, nil, logging.New("test"))
+	cmd := &ai.Command{Action: "create", Parameters: map[string]string{"title": "call mom"}, Confidence: 0.4}
+
+	prompt, err := h.confirmCommand(context.Background(), "+15551234567", "idem_abc", cmd)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prompt != "Add 'call mom'? Reply YES to confirm or NO to cancel." {
+		t.Errorf("unexpected prompt: %s", prompt)
+	}
+	action, expired := h.pending.Take("+15551234567")
+	if action == nil || expired || action.Command != cmd || action.IdempotencyKey != "idem_abc" {
+		t.Errorf("expected pending create to be stored, got %+v", action)
+	}
+}
+
+// =============================================================================
+// Disambiguation Tests
+// =============================================================================
+
+func TestFormatShortlist(t *testing.T) {
+	todos := []*todov1.Todo{
+		{Id: 4, Title: "call mom"},
+		{Id: 9, Title: "call dentist"},
+	}
+
+	result := formatShortlist("call", todos, 2)
+
+	expected := "Which one did you mean by 'call'?\n" +
+		"1) call mom (#4)\n" +
+		"2) call dentist (#9)\n" +
+		"Reply with a number, or NO to cancel."
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	if result := formatShortlist("call", todos, 7); !strings.Contains(result, "...and 5 more.") {
+		t.Errorf("expected a note about the todos not shown, got:\n%s", result)
+	}
+}
+
+func TestIsClearMatch(t *testing.T) {
+	match := func(scores ...float64) []domain.TitleMatch {
+		matches := make([]domain.TitleMatch, len(scores))
+		for i, score := range scores {
+			matches[i] = domain.TitleMatch{Todo: &todov1.Todo{Id: int64(i + 1)}, Score: score}
+		}
+		return matches
+	}
+
+	tests := []struct {
+		name     string
+		matches  []domain.TitleMatch
+		expected bool
+	}{
+		{"none", nil, false},
+		{"single strong", match(0.9), true},
+		{"single loose", match(0.7), false},
+		{"exact beats phrase", match(1.0, 0.9), true},
+		{"two phrases", match(0.9, 0.9), false},
+		{"close runner-up", match(0.89, 0.85), false},
+		{"strong over weak", match(0.9, 0.65), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isClearMatch(tt.matches); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPinCommand(t *testing.T) {
+	cmd := &ai.Command{
+		Action:     "edit",
+		Parameters: map[string]string{"title_hint": "call", "new_title": "call mom tonight"},
+		Confidence: 0.8,
+	}
+
+	pinned := pinCommand(cmd, 4)
+
+	if pinned.Parameters["todo_id"] != "4" || pinned.Parameters["new_title"] != "call mom tonight" {
+		t.Errorf("unexpected parameters: %v", pinned.Parameters)
+	}
+	if _, ok := pinned.Parameters["title_hint"]; ok {
+		t.Error("expected title_hint to be dropped")
+	}
+	if cmd.Parameters["title_hint"] != "call" {
+		t.Error("expected the original command to be left alone")
+	}
+}
+
+// =============================================================================
+// Trash Formatting Tests
+// =============================================================================
+
+func TestFormatTrash(t *testing.T) {
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	todos := []*todov1.Todo{
+		{Id: 5, Title: "call mom", DeletedAt: timestamppb.New(now.Add(-3 * time.Hour))},
+		{Id: 2, Title: "old task", DeletedAt: timestamppb.New(now.Add(-4 * 24 * time.Hour))},
+		{Id: 1, Title: "no timestamp"},
+	}
+
+	result := formatTrash(todos, now)
+
+	expected := "Trash:\n" +
+		"#5: call mom (deleted 3 hours ago)\n" +
+		"#2: old task (deleted 4 days ago)\n" +
+		"#1: no timestamp\n" +
+		"Reply 'restore #N' to bring one back."
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatTrash_Empty(t *testing.T) {
+	if result := formatTrash(nil, time.Now()); result != "Your trash is empty." {
+		t.Errorf("unexpected empty trash message: %s", result)
+	}
+}
+
+// =============================================================================
+// Multiple Command Tests
+// =============================================================================
+
+func TestCommandKey(t *testing.T) {
+	if key := commandKey("idem_abc", 0, 1); key != "idem_abc" {
+		t.Errorf("expected a single command to keep the message key, got %s", key)
+	}
+	if key := commandKey("", 1, 3); key != "" {
+		t.Errorf("expected no key without a message key, got %s", key)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		key := commandKey("idem_abc", i, 3)
+		if key == "idem_abc" || seen[key] {
+			t.Errorf("command %d: expected a distinct derived key, got %s", i, key)
+		}
+		if key != commandKey("idem_abc", i, 3) {
+			t.Errorf("command %d: expected derived key to be stable", i)
+		}
+		seen[key] = true
+	}
+}
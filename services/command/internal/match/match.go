@@ -0,0 +1,201 @@
+package match
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinScore is the lowest score worth showing the user at all
+	MinScore = 0.6
+
+	// Scores for a hint found as a whole inside a title, and for the exact title
+	phraseScore = 0.9
+	exactScore  = 1.0
+
+	// Score for a hint word that starts a title word, e.g. "groc" in "groceries"
+	prefixScore = 0.9
+
+	// Shortest word that is matched by prefix or tolerates a typo
+	minFuzzyLen = 3
+)
+
+// stopWords carry no meaning in a title hint: "the call" is just "call"
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "my": true, "to": true, "of": true,
+	"for": true, "with": true, "on": true, "about": true, "that": true, "this": true,
+}
+
+var folder = cases.Fold()
+
+// Result is one candidate title and how well it matched
+type Result struct {
+	Index int     // Position of the title in the slice given to Rank
+	Score float64 // 0 to 1, 1 being the exact title
+}
+
+// Rank scores every title against the hint and returns those scoring at
+// least minScore, best first. Titles that score the same keep their order.
+func Rank(hint string, titles []string, minScore float64) []Result {
+	q := newQuery(hint)
+
+	var results []Result
+	for i, title := range titles {
+		if score := q.score(title); score >= minScore {
+			results = append(results, Result{Index: i, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// Score rates how well a title matches a hint, from 0 to 1
+func Score(hint, title string) float64 {
+	return newQuery(hint).score(title)
+}
+
+// Similarity rates how alike two titles are, from 0 to 1. Unlike Score it
+// is the same either way round: "milk" is in "buy milk" but not like it.
+func Similarity(a, b string) float64 {
+	return min(Score(a, b), Score(b, a))
+}
+
+// query is a hint prepared once for scoring against many titles
+type query struct {
+	phrase string
+	tokens []string
+}
+
+func newQuery(hint string) query {
+	tokens := tokenize(hint)
+	tokens = dropStopWords(tokens)
+	return query{phrase: strings.Join(tokens, " "), tokens: tokens}
+}
+
+func (q query) score(title string) float64 {
+	if len(q.tokens) == 0 {
+		return 0
+	}
+
+	titleTokens := dropStopWords(tokenize(title))
+	phrase := strings.Join(titleTokens, " ")
+	switch {
+	case phrase == q.phrase:
+		return exactScore
+	case strings.Contains(" "+phrase+" ", " "+q.phrase+" "):
+		return phraseScore
+	}
+
+	// Order doesn't matter: every hint word is scored against its best title word
+	total := 0.0
+	for _, qt := range q.tokens {
+		best := 0.0
+		for _, tt := range titleTokens {
+			best = max(best, tokenSimilarity(qt, tt))
+		}
+		total += best
+	}
+	// Never outrank a title that contains the hint as written
+	return min(total/float64(len(q.tokens)), phraseScore-0.01)
+}
+
+// tokenSimilarity scores two words from 0 to 1, tolerating a typo or two
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if len(a) >= minFuzzyLen && strings.HasPrefix(b, a) {
+		return prefixScore
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if min(len(ra), len(rb)) < minFuzzyLen {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein counts the single-rune edits that turn a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// tokenize folds case, strips accents and splits into stemmed words, so
+// "Café GROCERIES!" becomes [cafe grocery]
+func tokenize(s string) []string {
+	s = folder.String(norm.NFD.String(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining accent left over from decomposing, e.g. the ´ of é
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// "mom's" is "moms", not "mom s"
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	for i, w := range words {
+		words[i] = stem(w)
+	}
+	return words
+}
+
+// stem reduces simple English plurals to their singular
+func stem(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return strings.TrimSuffix(w, "ies") + "y"
+	case len(w) > 4 && (strings.HasSuffix(w, "sses") || strings.HasSuffix(w, "xes") ||
+		strings.HasSuffix(w, "zes") || strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes")):
+		return strings.TrimSuffix(w, "es")
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") &&
+		!strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
+
+// dropStopWords removes filler words unless nothing else is left
+func dropStopWords(tokens []string) []string {
+	var kept []string
+	for _, t := range tokens {
+		if !stopWords[t] {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		return tokens
+	}
+	return kept
+}
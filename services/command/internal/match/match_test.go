@@ -0,0 +1,147 @@
+package match
+
+import (
+	"testing"
+)
+
+// =============================================================================
+// Tokenize Tests
+// =============================================================================
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Buy Groceries", "buy grocery"},
+		{"grocerys", "grocery"},
+		{"Café au lait", "cafe au lait"},
+		{"CRÈME BRÛLÉE", "creme brulee"},
+		{"call mom's dentist!", "call mom dentist"},
+		{"wash glasses, boxes & dishes", "wash glass box dish"},
+		{"STRASSE straße", "strasse strasse"},
+		{"bus", "bus"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ""
+			for i, w := range tokenize(tt.input) {
+				if i > 0 {
+					got += " "
+				}
+				got += w
+			}
+			if got != tt.expected {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"milk", "milk", 0},
+		{"milk", "mlk", 1},
+		{"dentist", "dentsit", 2},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+// =============================================================================
+// Score Tests
+// =============================================================================
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		hint  string
+		title string
+		min   float64
+		max   float64
+	}{
+		{"exact", "buy milk", "Buy milk", 1, 1},
+		{"exact ignoring stop words", "the plumber", "call the plumber", 0.9, 0.9},
+		{"phrase inside title", "milk", "buy milk", 0.9, 0.9},
+		{"plural typo", "grocerys", "buy groceries", 0.9, 0.9},
+		{"accents", "cafe", "Café with Sam", 0.9, 0.9},
+		{"word order", "mom call", "call mom", 0.85, 0.89},
+		{"prefix", "groc", "groceries", 0.85, 0.9},
+		{"typo", "dentsit", "call dentist", 0.65, 0.8},
+		{"unrelated", "taxes", "call mom", 0, 0.3},
+		{"partial words", "call plumber", "call mom", 0.5, 0.7},
+		{"short words need to be exact", "ca", "cat food", 0, 0},
+		{"empty hint", "", "call mom", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.hint, tt.title)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Score(%q, %q) = %.3f, want between %.2f and %.2f", tt.hint, tt.title, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"buy milk", "Buy milk!", 1, 1},
+		{"buy groceries", "buy grocery", 1, 1},
+		{"call the dentist", "call dentist", 1, 1},
+		{"milk", "buy milk", 0.4, 0.6},
+		{"buy milk", "buy milk and eggs", 0.4, 0.6},
+		{"call mom", "call dentist", 0.4, 0.6},
+	}
+
+	for _, tt := range tests {
+		got, reversed := Similarity(tt.a, tt.b), Similarity(tt.b, tt.a)
+		if got != reversed {
+			t.Errorf("Similarity(%q, %q) = %.3f but %.3f reversed", tt.a, tt.b, got, reversed)
+		}
+		if got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %.3f, want between %.2f and %.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	titles := []string{"call dentist", "buy milk", "call mom", "call"}
+
+	results := Rank("call", titles, MinScore)
+
+	expected := []int{3, 0, 2} // Exact first, then the rest in their original order
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %+v", len(expected), results)
+	}
+	for i, idx := range expected {
+		if results[i].Index != idx {
+			t.Errorf("result %d: expected title %q, got %q", i, titles[idx], titles[results[i].Index])
+		}
+	}
+	if results[0].Score != 1 || results[1].Score != results[2].Score {
+		t.Errorf("unexpected scores: %+v", results)
+	}
+}
+
+func TestRank_NoMatch(t *testing.T) {
+	if results := Rank("taxes", []string{"call mom", "buy milk"}, MinScore); len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+const (
+	// How many exchanges are kept per user. Follow-ups almost always refer
+	// to the last one or two, and every turn costs prompt tokens.
+	maxTurns = 5
+
+	// How many recently referenced todos are kept per user
+	maxTodoIDs = 5
+)
+
+// conversation is what is remembered about one user
+type conversation struct {
+	turns    []ai.Turn
+	todoIDs  []int64 // Most recent first
+	lastSeen time.Time
+}
+
+// Store keeps a short conversation history per user so follow-up messages
+// can refer back to earlier ones. A conversation is forgotten after ttl
+// without messages. State is in memory only - a restart starts every
+// conversation afresh, which costs nothing but a clarifying question.
+type Store struct {
+	mu            sync.Mutex
+	ttl           time.Duration
+	conversations map[string]*conversation
+	now           func() time.Time
+}
+
+// New creates a Store whose conversations expire after ttl of inactivity
+func New(ttl time.Duration) *Store {
+	return &Store{
+		ttl:           ttl,
+		conversations: make(map[string]*conversation),
+		now:           time.Now,
+	}
+}
+
+// History returns a copy of the user's recent conversation, or nil if there is none or it expired
+func (s *Store) History(userID string) *ai.History {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.get(userID)
+	if c == nil {
+		return nil
+	}
+	return &ai.History{
+		Turns:   append([]ai.Turn(nil), c.turns...),
+		TodoIDs: append([]int64(nil), c.todoIDs...),
+	}
+}
+
+// AddTurn records a message and the reply sent for it
+func (s *Store) AddTurn(userID, message, reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getOrCreate(userID)
+	c.turns = append(c.turns, ai.Turn{Message: message, Reply: reply})
+	if len(c.turns) > maxTurns {
+		c.turns = c.turns[len(c.turns)-maxTurns:]
+	}
+}
+
+// Touch records that a todo was just referred to, making it what "it" means next
+func (s *Store) Touch(userID string, todoID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.getOrCreate(userID)
+	ids := []int64{todoID}
+	for _, id := range c.todoIDs {
+		if id != todoID && len(ids) < maxTodoIDs {
+			ids = append(ids, id)
+		}
+	}
+	c.todoIDs = ids
+}
+
+// Forget drops everything remembered about the user
+func (s *Store) Forget(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conversations, userID)
+}
+
+// get returns the user's conversation, dropping it if it expired. Callers hold the lock.
+func (s *Store) get(userID string) *conversation {
+	c, ok := s.conversations[userID]
+	if !ok {
+		return nil
+	}
+	if s.now().Sub(c.lastSeen) > s.ttl {
+		delete(s.conversations, userID)
+		return nil
+	}
+	return c
+}
+
+// getOrCreate returns the user's live conversation, starting one if needed,
+// and marks it active. Callers hold the lock.
+func (s *Store) getOrCreate(userID string) *conversation {
+	c := s.get(userID)
+	if c == nil {
+		c = &conversation{}
+		s.conversations[userID] = c
+	}
+	c.lastSeen = s.now()
+	return c
+}
+
+// references are title hints that point back at the conversation rather
+// than describing a todo
+var references = map[string]bool{
+	"it":            true,
+	"that":          true,
+	"this":          true,
+	"that one":      true,
+	"this one":      true,
+	"the one":       true,
+	"last one":      true,
+	"the last one":  true,
+	"same one":      true,
+	"the same one":  true,
+	"the last todo": true,
+	"the last task": true,
+}
+
+// targetActions are the actions that act on a single existing todo
+var targetActions = map[string]bool{
+	"complete": true,
+	"delete":   true,
+	"edit":     true,
+	"restore":  true,
+}
+
+// Resolve replaces a title hint such as "it" or "the last one" with the todo
+// the conversation referred to most recently. Returns true if it did.
+func Resolve(cmd *ai.Command, history *ai.History) bool {
+	if !targetActions[cmd.Action] || cmd.Parameters["todo_id"] != "" {
+		return false
+	}
+	if !IsReference(cmd.Parameters["title_hint"]) {
+		return false
+	}
+
+	id, ok := history.LastTodoID()
+	if !ok {
+		return false
+	}
+
+	cmd.Parameters["todo_id"] = strconv.FormatInt(id, 10)
+	delete(cmd.Parameters, "title_hint")
+	return true
+}
+
+// IsReference reports whether a title hint points back at the conversation,
+// e.g. "it" in "done with it"
+func IsReference(hint string) bool {
+	hint = strings.ToLower(strings.TrimSpace(hint))
+	hint = strings.TrimRight(hint, ".!?")
+	for _, prefix := range []string{"with ", "on ", "of "} {
+		hint = strings.TrimPrefix(hint, prefix)
+	}
+	return references[hint]
+}
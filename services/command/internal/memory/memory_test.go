@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// =============================================================================
+// Store Tests
+// =============================================================================
+
+func TestStore_History(t *testing.T) {
+	s := New(time.Minute)
+
+	if s.History("user1") != nil {
+		t.Fatal("expected no history for a new user")
+	}
+
+	s.AddTurn("user1", "add call the plumber", "Added #7: call the plumber")
+	s.Touch("user1", 7)
+
+	h := s.History("user1")
+	if h == nil || len(h.Turns) != 1 || h.Turns[0].Message != "add call the plumber" {
+		t.Fatalf("unexpected history: %+v", h)
+	}
+	if id, ok := h.LastTodoID(); !ok || id != 7 {
+		t.Errorf("expected #7 as the last todo, got %d", id)
+	}
+	if s.History("user2") != nil {
+		t.Error("expected histories to be per user")
+	}
+
+	// The copy must not share state with the store
+	h.Turns[0].Message = "changed"
+	if s.History("user1").Turns[0].Message != "add call the plumber" {
+		t.Error("expected History to return a copy")
+	}
+}
+
+func TestStore_KeepsRecentTurns(t *testing.T) {
+	s := New(time.Minute)
+	for i := 1; i <= maxTurns+2; i++ {
+		s.AddTurn("user1", fmt.Sprintf("message %d", i), "ok")
+	}
+
+	turns := s.History("user1").Turns
+	if len(turns) != maxTurns {
+		t.Fatalf("expected %d turns, got %d", maxTurns, len(turns))
+	}
+	if turns[0].Message != "message 3" || turns[maxTurns-1].Message != fmt.Sprintf("message %d", maxTurns+2) {
+		t.Errorf("expected the newest turns oldest first, got %+v", turns)
+	}
+}
+
+func TestStore_Touch(t *testing.T) {
+	s := New(time.Minute)
+	for _, id := range []int64{1, 2, 3, 2, 4, 5, 6} {
+		s.Touch("user1", id)
+	}
+
+	expected := []int64{6, 5, 4, 2, 3}
+	got := s.History("user1").TodoIDs
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestStore_Expiry(t *testing.T) {
+	s := New(time.Minute)
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.AddTurn("user1", "add milk", "Added #1: milk")
+
+	// Activity keeps the conversation alive
+	now = now.Add(50 * time.Second)
+	s.Touch("user1", 1)
+	now = now.Add(50 * time.Second)
+	if s.History("user1") == nil {
+		t.Fatal("expected conversation to survive while active")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if s.History("user1") != nil {
+		t.Error("expected conversation to expire after inactivity")
+	}
+
+	// A new message starts afresh
+	s.AddTurn("user1", "list", "Your list is empty!")
+	h := s.History("user1")
+	if len(h.Turns) != 1 || len(h.TodoIDs) != 0 {
+		t.Errorf("expected a fresh conversation, got %+v", h)
+	}
+}
+
+func TestStore_Forget(t *testing.T) {
+	s := New(time.Minute)
+	s.AddTurn("user1", "add milk", "Added #1: milk")
+	s.Forget("user1")
+
+	if s.History("user1") != nil {
+		t.Error("expected Forget to drop the conversation")
+	}
+}
+
+// =============================================================================
+// Reference Resolution Tests
+// =============================================================================
+
+func TestIsReference(t *testing.T) {
+	tests := []struct {
+		hint     string
+		expected bool
+	}{
+		{"it", true},
+		{"with it", true},
+		{"That!", true},
+		{"the last one", true},
+		{"  this one ", true},
+		{"plumber", false},
+		{"it guy", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReference(tt.hint); got != tt.expected {
+			t.Errorf("IsReference(%q) = %v, want %v", tt.hint, got, tt.expected)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	history := &ai.History{TodoIDs: []int64{7, 3}}
+
+	tests := []struct {
+		name     string
+		cmd      *ai.Command
+		history  *ai.History
+		resolved bool
+		todoID   string
+	}{
+		{"complete it", &ai.Command{Action: "complete", Parameters: map[string]string{"title_hint": "with it"}}, history, true, "7"},
+		{"delete the last one", &ai.Command{Action: "delete", Parameters: map[string]string{"title_hint": "the last one"}}, history, true, "7"},
+		{"edit that", &ai.Command{Action: "edit", Parameters: map[string]string{"title_hint": "that", "new_title": "call the plumber tomorrow"}}, history, true, "7"},
+		{"real title", &ai.Command{Action: "complete", Parameters: map[string]string{"title_hint": "plumber"}}, history, false, ""},
+		{"id given", &ai.Command{Action: "complete", Parameters: map[string]string{"todo_id": "3", "title_hint": "it"}}, history, false, "3"},
+		{"no history", &ai.Command{Action: "complete", Parameters: map[string]string{"title_hint": "it"}}, nil, false, ""},
+		{"not a target action", &ai.Command{Action: "create", Parameters: map[string]string{"title": "it"}}, history, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.cmd, tt.history); got != tt.resolved {
+				t.Fatalf("expected resolved=%v, got %v", tt.resolved, got)
+			}
+			if tt.cmd.Parameters["todo_id"] != tt.todoID {
+				t.Errorf("expected todo_id %q, got %q", tt.todoID, tt.cmd.Parameters["todo_id"])
+			}
+			if tt.resolved {
+				if _, ok := tt.cmd.Parameters["title_hint"]; ok {
+					t.Error("expected title_hint to be replaced")
+				}
+				if err := ai.Validate(tt.cmd); len(err) > 0 {
+					t.Errorf("expected resolved command to validate, got %v", err)
+				}
+			}
+		})
+	}
+}
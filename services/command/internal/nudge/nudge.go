@@ -0,0 +1,268 @@
+package nudge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	todov1 "hound-todo/api/todo/v1"
+)
+
+// FallbackStep is suggested when no first step could be generated for a todo
+const FallbackStep = "Set a timer for two minutes and do just the very first bit."
+
+// How much each signal can add to a todo's score. Due dates weigh most: an
+// overdue todo is the one worth unsticking. Recently suggested todos drop
+// below everything else, so the same one isn't offered twice in a row.
+const (
+	weightDue      = 3.0
+	weightPriority = 2.0
+	weightAge      = 2.0
+	weightPattern  = 1.5
+	weightSize     = 1.0
+	penaltyRecent  = 10.0
+
+	// A todo open this long counts as fully stale
+	staleAfter = 14 * 24 * time.Hour
+)
+
+// stopWords don't say what kind of todo something is
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "for": true, "and": true, "of": true,
+	"on": true, "in": true, "at": true, "my": true, "with": true, "about": true, "up": true,
+}
+
+// Pattern is what a user tends to get done, learned from the words in the
+// titles of todos they completed
+type Pattern struct {
+	words map[string]int
+}
+
+// Learn builds a pattern from completed todos
+func Learn(completed []*todov1.Todo) *Pattern {
+	p := &Pattern{words: make(map[string]int)}
+	for _, todo := range completed {
+		for _, word := range keywords(todo.Title) {
+			p.words[word]++
+		}
+	}
+	return p
+}
+
+// affinity returns the fraction of a title's keywords the user has completed
+// todos with before, 0 to 1
+func (p *Pattern) affinity(title string) float64 {
+	words := keywords(title)
+	if p == nil || len(words) == 0 {
+		return 0
+	}
+	seen := 0
+	for _, word := range words {
+		if p.words[word] > 0 {
+			seen++
+		}
+	}
+	return float64(seen) / float64(len(words))
+}
+
+// keywords returns the distinct, lower-cased words of a title that say what it's about
+func keywords(title string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(title)) {
+		word = strings.Trim(word, ".,!?'\"()#")
+		if len(word) < 2 || stopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words
+}
+
+// Candidate is an active todo with its score and the main reason for it
+type Candidate struct {
+	Todo   *todov1.Todo
+	Score  float64
+	Reason string // Shown to the user, "" if nothing stands out
+}
+
+// Rank scores active todos, best first. Ties go to the oldest todo.
+func Rank(todos []*todov1.Todo, pattern *Pattern, recent []int64, now time.Time) []*Candidate {
+	suggested := make(map[int64]bool, len(recent))
+	for _, id := range recent {
+		suggested[id] = true
+	}
+
+	candidates := make([]*Candidate, len(todos))
+	for i, todo := range todos {
+		c := score(todo, pattern, now)
+		if suggested[todo.Id] {
+			c.Score -= penaltyRecent
+		}
+		candidates[i] = c
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Todo.Id < candidates[j].Todo.Id
+	})
+	return candidates
+}
+
+// Pick returns the todo most worth nudging, or nil if there are none
+func Pick(todos []*todov1.Todo, pattern *Pattern, recent []int64, now time.Time) *Candidate {
+	ranked := Rank(todos, pattern, recent, now)
+	if len(ranked) == 0 {
+		return nil
+	}
+	return ranked[0]
+}
+
+// score weighs one todo. now should be in the user's timezone, so "today" is theirs.
+func score(todo *todov1.Todo, pattern *Pattern, now time.Time) *Candidate {
+	c := &Candidate{Todo: todo}
+	// The reason worth the most points is the one worth mentioning
+	best := 0.0
+	add := func(points float64, reason string) {
+		c.Score += points
+		if reason != "" && points > best {
+			best, c.Reason = points, reason
+		}
+	}
+
+	if todo.DueAt != nil {
+		due := todo.DueAt.AsTime().In(now.Location())
+		switch days := calendarDays(now, due); {
+		case due.Before(now):
+			add(weightDue, "overdue")
+		case days == 0:
+			add(weightDue*0.9, "due today")
+		case days == 1:
+			add(weightDue*0.75, "due tomorrow")
+		case days <= 7:
+			add(weightDue*(1-float64(days)/8), fmt.Sprintf("due in %d days", days))
+		}
+	}
+
+	if todo.Priority > 0 {
+		points := weightPriority * float64(10-todo.Priority) / 9
+		reason := ""
+		if todo.Priority <= 3 {
+			reason = "high priority"
+		}
+		add(points, reason)
+	}
+
+	if todo.CreatedAt != nil {
+		age := now.Sub(todo.CreatedAt.AsTime())
+		if age > 0 {
+			frac := float64(age) / float64(staleAfter)
+			if frac > 1 {
+				frac = 1
+			}
+			reason := ""
+			if days := int(age.Hours() / 24); days >= 7 {
+				reason = fmt.Sprintf("open for %d days", days)
+			}
+			add(weightAge*frac, reason)
+		}
+	}
+
+	add(weightPattern*pattern.affinity(todo.Title), "")
+
+	// Small todos are easier to start; a long title or notes suggest a big one
+	words := len(strings.Fields(todo.Title)) + len(strings.Fields(todo.Description))
+	if words <= 3 {
+		add(weightSize, "a quick one")
+	} else if words < 12 {
+		add(weightSize*float64(12-words)/9, "")
+	}
+	return c
+}
+
+// calendarDays counts the midnights between two times in the same location
+func calendarDays(from, to time.Time) int {
+	y, m, d := from.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, from.Location())
+	y, m, d = to.Date()
+	end := time.Date(y, m, d, 0, 0, 0, 0, to.Location())
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// suggestion is a todo offered to a user and when
+type suggestion struct {
+	todoID int64
+	at     time.Time
+}
+
+// Recent remembers the last few todos suggested to each user, so a user
+// asking again gets a different one. Like conversations it lives in memory
+// only; a restart just means a todo may be offered again.
+type Recent struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	users map[string][]suggestion // Most recent first
+	now   func() time.Time
+}
+
+// NewRecent remembers up to size suggestions per user, each for ttl
+func NewRecent(size int, ttl time.Duration) *Recent {
+	return &Recent{
+		size:  size,
+		ttl:   ttl,
+		users: make(map[string][]suggestion),
+		now:   time.Now,
+	}
+}
+
+// Add records that a todo was just suggested to the user
+func (r *Recent) Add(userID string, todoID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := []suggestion{{todoID: todoID, at: r.now()}}
+	for _, s := range r.live(userID) {
+		if s.todoID != todoID && len(kept) < r.size {
+			kept = append(kept, s)
+		}
+	}
+	r.users[userID] = kept
+}
+
+// IDs returns the todos recently suggested to the user, most recent first
+func (r *Recent) IDs(userID string) []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []int64
+	for _, s := range r.live(userID) {
+		ids = append(ids, s.todoID)
+	}
+	return ids
+}
+
+// Forget drops every suggestion remembered for the user
+func (r *Recent) Forget(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, userID)
+}
+
+// live returns the user's unexpired suggestions. Callers hold the lock.
+func (r *Recent) live(userID string) []suggestion {
+	var out []suggestion
+	for _, s := range r.users[userID] {
+		if r.now().Sub(s.at) <= r.ttl {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		delete(r.users, userID)
+	}
+	return out
+}
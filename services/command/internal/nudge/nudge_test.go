@@ -0,0 +1,142 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	todov1 "hound-todo/api/todo/v1"
+)
+
+var now = time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+
+func todo(id int64, title string) *todov1.Todo {
+	return &todov1.Todo{Id: id, Title: title, CreatedAt: timestamppb.New(now.Add(-time.Hour))}
+}
+
+// =============================================================================
+// Ranking Tests
+// =============================================================================
+
+func TestPick(t *testing.T) {
+	overdue := todo(1, "file the quarterly tax estimate with the accountant")
+	overdue.DueAt = timestamppb.New(now.Add(-2 * time.Hour))
+	urgent := todo(2, "reply to the landlord")
+	urgent.Priority = 1
+	stale := todo(3, "clean out the garage shelves")
+	stale.CreatedAt = timestamppb.New(now.Add(-30 * 24 * time.Hour))
+	quick := todo(4, "water plants")
+
+	tests := []struct {
+		name   string
+		todos  []*todov1.Todo
+		want   int64
+		reason string
+	}{
+		{"overdue first", []*todov1.Todo{quick, stale, urgent, overdue}, 1, "overdue"},
+		{"then high priority", []*todov1.Todo{quick, stale, urgent}, 2, "high priority"},
+		{"then long open", []*todov1.Todo{quick, stale}, 3, "open for 30 days"},
+		{"then small", []*todov1.Todo{todo(5, "plan the family reunion trip itinerary and budget"), quick}, 4, "a quick one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Pick(tt.todos, nil, nil, now)
+			if got.Todo.Id != tt.want || got.Reason != tt.reason {
+				t.Errorf("expected #%d (%s), got #%d (%s)", tt.want, tt.reason, got.Todo.Id, got.Reason)
+			}
+		})
+	}
+
+	if Pick(nil, nil, nil, now) != nil {
+		t.Error("expected nothing picked from an empty list")
+	}
+}
+
+func TestPick_DueInUserTimezone(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	// 15:00 on the 20th in UTC is already the 21st in Tokyo
+	due := todo(1, "send invoice")
+	due.DueAt = timestamppb.New(time.Date(2026, 1, 21, 23, 0, 0, 0, tokyo))
+
+	if got := Pick([]*todov1.Todo{due}, nil, nil, now.In(tokyo)); got.Reason != "due today" {
+		t.Errorf("expected due today in Tokyo, got %q", got.Reason)
+	}
+	if got := Pick([]*todov1.Todo{due}, nil, nil, now); got.Reason != "due tomorrow" {
+		t.Errorf("expected due tomorrow in UTC, got %q", got.Reason)
+	}
+}
+
+func TestPick_PrefersWhatUserFinishes(t *testing.T) {
+	pattern := Learn([]*todov1.Todo{{Title: "call the dentist"}, {Title: "call mom"}})
+	todos := []*todov1.Todo{todo(1, "write blog post"), todo(2, "call plumber")}
+
+	if got := Pick(todos, pattern, nil, now); got.Todo.Id != 2 {
+		t.Errorf("expected the kind of todo the user completes, got #%d", got.Todo.Id)
+	}
+	if got := Pick(todos, nil, nil, now); got.Todo.Id != 1 {
+		t.Errorf("expected the oldest todo on a tie without history, got #%d", got.Todo.Id)
+	}
+}
+
+func TestPick_SkipsRecentSuggestions(t *testing.T) {
+	overdue := todo(1, "renew passport")
+	overdue.DueAt = timestamppb.New(now.Add(-time.Hour))
+	todos := []*todov1.Todo{overdue, todo(2, "buy stamps"), todo(3, "book flights")}
+
+	if got := Pick(todos, nil, []int64{1}, now); got.Todo.Id != 2 {
+		t.Errorf("expected the next best todo, got #%d", got.Todo.Id)
+	}
+	if got := Pick(todos, nil, []int64{1, 2, 3}, now); got.Todo.Id != 1 {
+		t.Errorf("expected the best todo again once every one was suggested, got #%d", got.Todo.Id)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	got := keywords("Call the dentist, call #3 again!")
+	want := []string{"call", "dentist", "again"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+// =============================================================================
+// Recent Tests
+// =============================================================================
+
+func TestRecent(t *testing.T) {
+	clock := now
+	r := NewRecent(2, time.Hour)
+	r.now = func() time.Time { return clock }
+
+	r.Add("+15551234567", 1)
+	r.Add("+15551234567", 2)
+	r.Add("+15551234567", 1)
+	if ids := r.IDs("+15551234567"); len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("expected [1 2], got %v", ids)
+	}
+
+	r.Add("+15551234567", 3)
+	if ids := r.IDs("+15551234567"); len(ids) != 2 || ids[0] != 3 || ids[1] != 1 {
+		t.Errorf("expected the oldest suggestion dropped, got %v", ids)
+	}
+	if ids := r.IDs("+15559999999"); len(ids) != 0 {
+		t.Errorf("expected nothing for another user, got %v", ids)
+	}
+
+	clock = clock.Add(time.Hour + time.Second)
+	if ids := r.IDs("+15551234567"); len(ids) != 0 {
+		t.Errorf("expected suggestions to expire, got %v", ids)
+	}
+
+	r.Add("+15551234567", 4)
+	r.Forget("+15551234567")
+	if ids := r.IDs("+15551234567"); len(ids) != 0 {
+		t.Errorf("expected nothing after Forget, got %v", ids)
+	}
+}
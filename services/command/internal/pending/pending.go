@@ -0,0 +1,285 @@
+package pending
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// Action is a command waiting for the user to confirm it, to pick which
+// todo it applies to from a numbered shortlist, or to say which of the
+// steps a todo was broken into to keep
+type Action struct {
+	Command        *ai.Command
+	IdempotencyKey string   // Key of the message that asked for the action
+	Choices        []int64  // Todo ids offered in the shortlist, in order; empty for a YES/NO question
+	Steps          []string // Steps offered to add, in order; empty unless breaking a todo down
+	Duplicate      int64    // Todo a create was asked about as a duplicate; a YES adds it anyway
+	ExpiresAt      time.Time
+}
+
+// Store holds at most one pending action per user. An expired action is kept
+// until the user's next message so a late YES can be told it came too late.
+// State is in memory only - a restart forgets unconfirmed actions, which is the safe default.
+type Store struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	actions map[string]*Action
+	now     func() time.Time
+}
+
+// New creates a Store whose actions expire after ttl
+func New(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		actions: make(map[string]*Action),
+		now:     time.Now,
+	}
+}
+
+// Put stores an action for the user, replacing any existing one. With
+// choices the user answers with a number instead of YES or NO.
+func (s *Store) Put(userID string, cmd *ai.Command, idempotencyKey string, choices ...int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actions[userID] = &Action{
+		Command:        cmd,
+		IdempotencyKey: idempotencyKey,
+		Choices:        choices,
+		ExpiresAt:      s.now().Add(s.ttl),
+	}
+}
+
+// PutSteps stores a breakdown for the user, replacing any existing action.
+// The user answers YES for every step, NO, or with the numbers to keep.
+func (s *Store) PutSteps(userID string, cmd *ai.Command, idempotencyKey string, steps []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actions[userID] = &Action{
+		Command:        cmd,
+		IdempotencyKey: idempotencyKey,
+		Steps:          steps,
+		ExpiresAt:      s.now().Add(s.ttl),
+	}
+}
+
+// Restore puts back an action taken from the store, e.g. one whose answer
+// failed to run, with a fresh expiry
+func (s *Store) Restore(userID string, action *Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action.ExpiresAt = s.now().Add(s.ttl)
+	s.actions[userID] = action
+}
+
+// Offer stores an action for the user unless one is already waiting for an
+// answer, and reports whether it did
+func (s *Store) Offer(userID string, action *Action) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if open, ok := s.actions[userID]; ok && !s.now().After(open.ExpiresAt) {
+		return false
+	}
+	action.ExpiresAt = s.now().Add(s.ttl)
+	s.actions[userID] = action
+	return true
+}
+
+// Take removes and returns the user's pending action, or nil if there is none.
+// expired is true when the action timed out and must not be executed.
+func (s *Store) Take(userID string) (action *Action, expired bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action, ok := s.actions[userID]
+	if !ok {
+		return nil, false
+	}
+	delete(s.actions, userID)
+
+	return action, s.now().After(action.ExpiresAt)
+}
+
+// Has reports whether the user has an unexpired pending action
+func (s *Store) Has(userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action, ok := s.actions[userID]
+	return ok && !s.now().After(action.ExpiresAt)
+}
+
+// Reply is the user's answer to a confirmation prompt
+type Reply int
+
+const (
+	ReplyNone Reply = iota // Not a yes/no answer
+	ReplyYes
+	ReplyNo
+)
+
+var (
+	yesWords = map[string]bool{"yes": true, "y": true, "yep": true, "yeah": true, "confirm": true, "ok": true, "okay": true, "sure": true}
+	noWords  = map[string]bool{"no": true, "n": true, "nope": true, "cancel": true, "nevermind": true, "never mind": true}
+)
+
+// ParseReply interprets a message as a yes/no answer
+func ParseReply(text string) Reply {
+	normalized := strings.ToLower(strings.TrimSpace(text))
+	normalized = strings.TrimRight(normalized, ".!")
+
+	switch {
+	case yesWords[normalized]:
+		return ReplyYes
+	case noWords[normalized]:
+		return ReplyNo
+	default:
+		return ReplyNone
+	}
+}
+
+var (
+	choicePrefix = regexp.MustCompile(`^(?:number|no\.?|option|the)\s+`)
+	choiceWords  = map[string]int{
+		"one": 1, "first": 1, "1st": 1,
+		"two": 2, "second": 2, "2nd": 2,
+		"three": 3, "third": 3, "3rd": 3,
+		"four": 4, "fourth": 4, "4th": 4,
+		"five": 5, "fifth": 5, "5th": 5,
+	}
+)
+
+// ParseChoice interprets a message as picking an entry from a numbered
+// shortlist of n, e.g. "2", "2)" or "the second one". Returns the 1-based choice.
+func ParseChoice(text string, n int) (int, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(text))
+	normalized = strings.TrimRight(normalized, ".!)")
+	normalized = choicePrefix.ReplaceAllString(normalized, "")
+	normalized = strings.TrimSuffix(normalized, " one")
+
+	choice, ok := choiceWords[normalized]
+	if !ok {
+		var err error
+		if choice, err = strconv.Atoi(normalized); err != nil {
+			return 0, false
+		}
+	}
+
+	if choice < 1 || choice > n {
+		return 0, false
+	}
+	return choice, true
+}
+
+var (
+	selectionPrefix = regexp.MustCompile(`^(?:keep|only|just|add|use|create)\s+(?:steps?\s+|numbers?\s+)?`)
+	selectionSep    = regexp.MustCompile(`\s*(?:,|&|\band\b|\s)\s*`)
+)
+
+// ParseSelection interprets a message as picking some entries of a numbered
+// list of n, e.g. "keep 1, 2 and 4", "1 3" or "just #2". Returns the 1-based
+// picks in ascending order, without repeats.
+func ParseSelection(text string, n int) ([]int, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(text))
+	normalized = strings.TrimRight(normalized, ".!")
+	normalized = selectionPrefix.ReplaceAllString(normalized, "")
+	if normalized == "" {
+		return nil, false
+	}
+
+	seen := make(map[int]bool)
+	var picks []int
+	for _, part := range selectionSep.Split(normalized, -1) {
+		if part == "" {
+			continue
+		}
+		pick, err := strconv.Atoi(strings.TrimPrefix(part, "#"))
+		if err != nil || pick < 1 || pick > n {
+			return nil, false
+		}
+		if !seen[pick] {
+			seen[pick] = true
+			picks = append(picks, pick)
+		}
+	}
+	if len(picks) == 0 {
+		return nil, false
+	}
+	sort.Ints(picks)
+	return picks, true
+}
+
+// Always is a threshold above any confidence, so the action is confirmed every time
+const Always = 2.0
+
+// Policy maps an action to the confidence below which it is confirmed before
+// it runs. Actions not in the policy never need confirmation.
+type Policy map[string]float64
+
+// DefaultPolicy confirms changes the parser isn't sure about, and every
+// delete. Even a strict form like "delete #4" asks first, naming the todo,
+// since a mistyped id would otherwise send the wrong todo to the trash.
+func DefaultPolicy() Policy {
+	return Policy{
+		"create":   0.5,
+		"complete": 0.7,
+		"edit":     0.7,
+		"restore":  0.7,
+		"delete":   Always,
+	}
+}
+
+// NeedsConfirmation reports whether the command should wait for a YES
+func (p Policy) NeedsConfirmation(cmd *ai.Command) bool {
+	threshold, ok := p[cmd.Action]
+	return ok && cmd.Confidence < threshold
+}
+
+// ParseThreshold reads a confirmation threshold: a confidence from 0 to 1,
+// "always" or "never"
+func ParseThreshold(value string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "always":
+		return Always, nil
+	case "never":
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || f < 0 || f > 1 {
+		return 0, fmt.Errorf("invalid threshold %q: want a number from 0 to 1, always or never", value)
+	}
+	return f, nil
+}
+
+// String formats the policy for logging, e.g. "delete=0.90 edit=always"
+func (p Policy) String() string {
+	actions := make([]string, 0, len(p))
+	for action := range p {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	parts := make([]string, len(actions))
+	for i, action := range actions {
+		switch threshold := p[action]; {
+		case threshold >= Always:
+			parts[i] = action + "=always"
+		case threshold <= 0:
+			parts[i] = action + "=never"
+		default:
+			parts[i] = fmt.Sprintf("%s=%.2f", action, threshold)
+		}
+	}
+	return strings.Join(parts, " ")
+}
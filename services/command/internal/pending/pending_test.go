@@ -0,0 +1,288 @@
+package pending
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+// =============================================================================
+// Store Tests
+// =============================================================================
+
+func TestStore_PutTake(t *testing.T) {
+	s := New(time.Minute)
+	cmd := &ai.Command{Action: "erase_account"}
+
+	s.Put("user1", cmd, "idem_abc")
+
+	if !s.Has("user1") {
+		t.Fatal("expected pending action for user1")
+	}
+	if s.Has("user2") {
+		t.Error("expected no pending action for user2")
+	}
+
+	action, expired := s.Take("user1")
+	if action == nil || expired {
+		t.Fatal("expected live action from Take")
+	}
+	if action.Command != cmd || action.IdempotencyKey != "idem_abc" {
+		t.Errorf("unexpected action: %+v", action)
+	}
+	if action, _ := s.Take("user1"); action != nil {
+		t.Error("expected Take to remove the action")
+	}
+}
+
+func TestStore_Expiry(t *testing.T) {
+	s := New(time.Minute)
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Put("user1", &ai.Command{Action: "erase_account"}, "")
+	now = now.Add(2 * time.Minute)
+
+	if s.Has("user1") {
+		t.Error("expected expired action to be ignored by Has")
+	}
+	action, expired := s.Take("user1")
+	if action == nil || !expired {
+		t.Error("expected Take to report the action as expired")
+	}
+	if action, _ := s.Take("user1"); action != nil {
+		t.Error("expected Take to remove the expired action")
+	}
+}
+
+func TestStore_PutReplaces(t *testing.T) {
+	s := New(time.Minute)
+
+	s.Put("user1", &ai.Command{Action: "first"}, "")
+	s.Put("user1", &ai.Command{Action: "second"}, "")
+
+	if action, _ := s.Take("user1"); action == nil || action.Command.Action != "second" {
+		t.Errorf("expected latest action to win, got %+v", action)
+	}
+}
+
+func TestStore_OfferKeepsOpenAction(t *testing.T) {
+	s := New(time.Minute)
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	if !s.Offer("user1", &Action{Command: &ai.Command{Action: "first"}}) {
+		t.Fatal("expected the first action stored")
+	}
+	if s.Offer("user1", &Action{Command: &ai.Command{Action: "second"}}) {
+		t.Error("expected the second action refused while the first is open")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if !s.Offer("user1", &Action{Command: &ai.Command{Action: "third"}}) {
+		t.Error("expected an action stored once the open one expired")
+	}
+	if action, expired := s.Take("user1"); action == nil || expired || action.Command.Action != "third" {
+		t.Errorf("expected the live third action, got %+v", action)
+	}
+}
+
+func TestStore_PutChoices(t *testing.T) {
+	s := New(time.Minute)
+	s.Put("user1", &ai.Command{Action: "complete"}, "idem_abc", 4, 9)
+
+	action, _ := s.Take("user1")
+	if action == nil || len(action.Choices) != 2 || action.Choices[0] != 4 || action.Choices[1] != 9 {
+		t.Errorf("expected choices to be stored, got %+v", action)
+	}
+}
+
+func TestStore_Restore(t *testing.T) {
+	s := New(time.Minute)
+	now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.Offer("user1", &Action{Command: &ai.Command{Action: "create"}, IdempotencyKey: "idem_abc", Duplicate: 3})
+
+	action, _ := s.Take("user1")
+	now = now.Add(50 * time.Second)
+	s.Restore("user1", action)
+
+	// Put back whole, and good for another minute
+	now = now.Add(50 * time.Second)
+	restored, expired := s.Take("user1")
+	if restored == nil || expired || restored.Duplicate != 3 || restored.IdempotencyKey != "idem_abc" {
+		t.Errorf("expected the action restored as it was, got %+v (expired %v)", restored, expired)
+	}
+}
+
+// =============================================================================
+// ParseReply Tests
+// =============================================================================
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Reply
+	}{
+		{"YES", ReplyYes},
+		{"yes!", ReplyYes},
+		{" y ", ReplyYes},
+		{"Ok.", ReplyYes},
+		{"no", ReplyNo},
+		{"Cancel", ReplyNo},
+		{"never mind", ReplyNo},
+		{"yes please add milk", ReplyNone},
+		{"add buy milk", ReplyNone},
+		{"", ReplyNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseReply(tt.input); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseChoice(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		valid    bool
+	}{
+		{"2", 2, true},
+		{" 1. ", 1, true},
+		{"3)", 3, true},
+		{"number 2", 2, true},
+		{"the second one", 2, true},
+		{"First", 1, true},
+		{"0", 0, false},
+		{"4", 0, false},
+		{"#2", 0, false},
+		{"done 2", 0, false},
+		{"yes", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseChoice(tt.input, 3)
+			if ok != tt.valid || got != tt.expected {
+				t.Errorf("ParseChoice(%q) = %d, %v; want %d, %v", tt.input, got, ok, tt.expected, tt.valid)
+			}
+		})
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{"keep 1, 2 and 4", []int{1, 2, 4}},
+		{"Keep steps 4 & 2.", []int{2, 4}},
+		{"1 3", []int{1, 3}},
+		{"just #2", []int{2}},
+		{"only 5,5,1", []int{1, 5}},
+		{"3", []int{3}},
+		{"keep 1 and 6", nil},
+		{"keep 0", nil},
+		{"keep all", nil},
+		{"keep", nil},
+		{"yes", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseSelection(tt.input, 5)
+			if ok != (tt.expected != nil) || fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+				t.Errorf("ParseSelection(%q) = %v, %v; want %v", tt.input, got, ok, tt.expected)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Policy Tests
+// =============================================================================
+
+func TestPolicy_NeedsConfirmation(t *testing.T) {
+	p := Policy{"delete": 0.9, "edit": Always, "create": 0}
+
+	tests := []struct {
+		action     string
+		confidence float64
+		expected   bool
+	}{
+		{"delete", 0.5, true},
+		{"delete", 0.95, false},
+		{"delete", 1.0, false},
+		{"edit", 1.0, true},
+		{"create", 0.1, false},
+		{"list", 0.1, false},
+	}
+
+	for _, tt := range tests {
+		cmd := &ai.Command{Action: tt.action, Confidence: tt.confidence}
+		if got := p.NeedsConfirmation(cmd); got != tt.expected {
+			t.Errorf("%s at %.2f: expected %v, got %v", tt.action, tt.confidence, tt.expected, got)
+		}
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+
+	// The request from the original report: a guessed delete must be confirmed
+	if !p.NeedsConfirmation(&ai.Command{Action: "delete", Confidence: 0.5}) {
+		t.Error("expected low-confidence delete to need confirmation")
+	}
+	// Even strict forms parsed by rules, at confidence 1.0, delete only on a YES
+	if !p.NeedsConfirmation(&ai.Command{Action: "delete", Confidence: 1.0}) {
+		t.Error("expected a certain delete to need confirmation")
+	}
+	if p.NeedsConfirmation(&ai.Command{Action: "complete", Confidence: 1.0}) {
+		t.Error("expected a certain complete to run directly")
+	}
+	if p.NeedsConfirmation(&ai.Command{Action: "list", Confidence: 0.1}) {
+		t.Error("expected read-only actions to never need confirmation")
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		valid    bool
+	}{
+		{"0.8", 0.8, true},
+		{" 1 ", 1, true},
+		{"always", Always, true},
+		{"NEVER", 0, true},
+		{"1.5", 0, false},
+		{"-0.1", 0, false},
+		{"sometimes", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseThreshold(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseThreshold(%q): expected valid=%v, got error %v", tt.input, tt.valid, err)
+			continue
+		}
+		if tt.valid && got != tt.expected {
+			t.Errorf("ParseThreshold(%q) = %v, want %v", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestPolicy_String(t *testing.T) {
+	p := Policy{"edit": Always, "delete": 0.9, "create": 0}
+	if got := p.String(); got != "create=never delete=0.90 edit=always" {
+		t.Errorf("unexpected policy string: %s", got)
+	}
+}
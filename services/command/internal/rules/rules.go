@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+var (
+	addPattern    = regexp.MustCompile(`(?i)^add\s*:?\s+(.+?)(?:\s+to\s+(?:my\s+)?(?:list|todos))?$`)
+	donePattern   = regexp.MustCompile(`(?i)^(?:done|complete)\s+#?(\d+)$`)
+	deletePattern = regexp.MustCompile(`(?i)^(?:delete|remove)\s+#?(\d+)$`)
+	editPattern   = regexp.MustCompile(`(?i)^edit\s+#?(\d+)\s*:?\s+(?:to\s+)?(.+)$`)
+	listPattern   = regexp.MustCompile(`(?i)^list(?:\s+(completed|done|all|trash))?$`)
+
+	// An add title like this may hold more than one request ("milk and eggs,
+	// and mark #2 done"), which only the LLM can split
+	compoundTitle = regexp.MustCompile(`(?i),|\s(?:and|then)\s|#\d+`)
+)
+
+// Match recognizes the strict command forms that don't need the LLM:
+//
+//	add <title>, unless the title may hold several requests
+//	done #N
+//	delete #N
+//	edit #N <new title>
+//	list
+//	list completed
+//
+// Returns nil for anything else.
+func Match(text string) *ai.Command {
+	text = strings.TrimRight(strings.TrimSpace(text), ".!")
+
+	if m := addPattern.FindStringSubmatch(text); m != nil {
+		if compoundTitle.MatchString(m[1]) {
+			return nil
+		}
+		return command("create", "title", strings.TrimSpace(m[1]))
+	}
+	if m := donePattern.FindStringSubmatch(text); m != nil {
+		return command("complete", "todo_id", m[1])
+	}
+	if m := deletePattern.FindStringSubmatch(text); m != nil {
+		return command("delete", "todo_id", m[1])
+	}
+	if m := editPattern.FindStringSubmatch(text); m != nil {
+		cmd := command("edit", "todo_id", m[1])
+		cmd.Parameters["new_title"] = strings.TrimSpace(m[2])
+		return cmd
+	}
+	if m := listPattern.FindStringSubmatch(text); m != nil {
+		filter := strings.ToLower(m[1])
+		switch filter {
+		case "":
+			filter = "active"
+		case "done":
+			filter = "completed"
+		}
+		return command("list", "filter", filter)
+	}
+
+	return nil
+}
+
+func command(action, key, value string) *ai.Command {
+	return &ai.Command{
+		Action:      action,
+		Parameters:  map[string]string{key: value},
+		Confidence:  1.0,
+		Explanation: "Matched the " + action + " shortcut",
+	}
+}
+
+// Parser wraps Match and counts how often it saves an LLM call
+type Parser struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// New creates a rule-based parser
+func New() *Parser {
+	return &Parser{}
+}
+
+// Parse returns the command for a strict form, or false if the LLM is needed
+func (p *Parser) Parse(text string) (*ai.Command, bool) {
+	cmd := Match(text)
+	if cmd == nil {
+		p.misses.Add(1)
+		return nil, false
+	}
+	p.hits.Add(1)
+	return cmd, true
+}
+
+// Stats is a snapshot of the parser's hit counts
+type Stats struct {
+	Hits   int64 // Messages handled without the LLM
+	Misses int64 // Messages passed on to the LLM
+}
+
+// Stats returns the current hit counts
+func (p *Parser) Stats() Stats {
+	return Stats{Hits: p.hits.Load(), Misses: p.misses.Load()}
+}
+
+// HitRate returns the fraction of messages handled without the LLM
+func (s Stats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d hit_rate=%.1f%%", s.Hits, s.Misses, s.HitRate()*100)
+}
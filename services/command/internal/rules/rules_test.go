@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"testing"
+
+	"hound-todo/services/command/internal/ai"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		input      string
+		action     string
+		parameters map[string]string
+	}{
+		{"add buy milk", "create", map[string]string{"title": "buy milk"}},
+		{"Add: Call Mom!", "create", map[string]string{"title": "Call Mom"}},
+		{"add buy groceries to my list", "create", map[string]string{"title": "buy groceries"}},
+		{"done #3", "complete", map[string]string{"todo_id": "3"}},
+		{"Done 12.", "complete", map[string]string{"todo_id": "12"}},
+		{"complete #4", "complete", map[string]string{"todo_id": "4"}},
+		{"delete #2", "delete", map[string]string{"todo_id": "2"}},
+		{"remove 7", "delete", map[string]string{"todo_id": "7"}},
+		{"edit #1 call dad instead", "edit", map[string]string{"todo_id": "1", "new_title": "call dad instead"}},
+		{"edit #1 to call dad", "edit", map[string]string{"todo_id": "1", "new_title": "call dad"}},
+		{"list", "list", map[string]string{"filter": "active"}},
+		{"  LIST  ", "list", map[string]string{"filter": "active"}},
+		{"list completed", "list", map[string]string{"filter": "completed"}},
+		{"list done", "list", map[string]string{"filter": "completed"}},
+		{"list trash", "list", map[string]string{"filter": "trash"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cmd := Match(tt.input)
+			if cmd == nil {
+				t.Fatalf("expected %q to match", tt.input)
+			}
+			if cmd.Action != tt.action {
+				t.Errorf("expected action %s, got %s", tt.action, cmd.Action)
+			}
+			if cmd.Confidence != 1.0 {
+				t.Errorf("expected full confidence, got %.2f", cmd.Confidence)
+			}
+			if errs := ai.Validate(cmd); len(errs) > 0 {
+				t.Errorf("expected a valid command, got %v", errs)
+			}
+			if len(cmd.Parameters) != len(tt.parameters) {
+				t.Errorf("expected parameters %v, got %v", tt.parameters, cmd.Parameters)
+			}
+			for k, v := range tt.parameters {
+				if cmd.Parameters[k] != v {
+					t.Errorf("expected %s=%q, got %q", k, v, cmd.Parameters[k])
+				}
+			}
+		})
+	}
+}
+
+func TestMatch_FallsThrough(t *testing.T) {
+	inputs := []string{
+		"",
+		"remind me to call mom",
+		"done with the groceries",
+		"delete the dentist one",
+		"edit #3",
+		"list what I did yesterday",
+		"add",
+		"I'm stuck on the report",
+		"add milk and eggs, and mark #2 done",
+		"add milk, eggs",
+		"add call mom then email Sam",
+		"add follow up on #4",
+	}
+
+	for _, input := range inputs {
+		if cmd := Match(input); cmd != nil {
+			t.Errorf("expected %q to fall through to the LLM, got %s %v", input, cmd.Action, cmd.Parameters)
+		}
+	}
+}
+
+func TestParser_Stats(t *testing.T) {
+	p := New()
+
+	if rate := p.Stats().HitRate(); rate != 0 {
+		t.Errorf("expected 0 hit rate with no messages, got %f", rate)
+	}
+
+	p.Parse("list")
+	p.Parse("done #1")
+	p.Parse("what should I do next?")
+	p.Parse("add milk")
+
+	stats := p.Stats()
+	if stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("expected 3 hits and 1 miss, got %+v", stats)
+	}
+	if stats.HitRate() != 0.75 {
+		t.Errorf("expected hit rate 0.75, got %f", stats.HitRate())
+	}
+	if s := stats.String(); s != "hits=3 misses=1 hit_rate=75.0%" {
+		t.Errorf("unexpected String(): %s", s)
+	}
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
+	"context"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
 	todov1 "hound-todo/api/todo/v1"
 	"hound-todo/services/todo-domain/internal/config"
+	"hound-todo/services/todo-domain/internal/retention"
 	"hound-todo/services/todo-domain/internal/server"
 	"hound-todo/services/todo-domain/internal/store"
 	"hound-todo/shared/logging"
@@ -35,9 +38,54 @@ func main() {
 	defer db.Close()
 	logger.Info("Connected to PostgreSQL")
 
+	// Connect to the audit and transcription databases if configured
+	var auditStore *store.AuditStore
+	if cfg.AuditDatabaseURL != "" {
+		auditDB, err := store.Connect(cfg.AuditDatabaseURL)
+		if err != nil {
+			logger.Error("Failed to connect to audit database: %v", err)
+			os.Exit(1)
+		}
+		defer auditDB.Close()
+		auditStore = store.NewAuditStore(auditDB)
+		logger.Info("Connected to audit database")
+	}
+
+	var transcriptStore *store.TranscriptStore
+	if cfg.TranscriptionDatabaseURL != "" {
+		transcriptDB, err := store.Connect(cfg.TranscriptionDatabaseURL)
+		if err != nil {
+			logger.Error("Failed to connect to transcription database: %v", err)
+			os.Exit(1)
+		}
+		defer transcriptDB.Close()
+		transcriptStore = store.NewTranscriptStore(transcriptDB)
+		logger.Info("Connected to transcription database")
+	}
+
 	// Create the store and server
 	todoStore := store.New(db)
-	todoServer := server.New(todoStore, logger)
+	todoServer := server.New(todoStore, auditStore, transcriptStore, logger)
+
+	// Start the retention worker that purges old deleted todos and transcripts
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	retentionWorker := retention.New(todoStore, retention.Policy{
+		DeletedTodoAge: time.Duration(cfg.RetentionDeletedTodoDays) * 24 * time.Hour,
+		TranscriptAge:  time.Duration(cfg.RetentionTranscriptDays) * 24 * time.Hour,
+		BatchSize:      cfg.RetentionBatchSize,
+		Interval:       cfg.RetentionInterval,
+	}, logger)
+	if transcriptStore != nil {
+		retentionWorker.SetTranscripts(transcriptStore)
+	}
+	if auditStore != nil {
+		retentionWorker.SetAudit(auditStore)
+	}
+	go retentionWorker.Run(ctx)
+	logger.Info("Retention worker started: deleted todos %dd, transcripts %dd, every %s",
+		cfg.RetentionDeletedTodoDays, cfg.RetentionTranscriptDays, cfg.RetentionInterval)
 
 	// Create gRPC server
 	grpcServer := grpc.NewServer()
@@ -62,6 +110,7 @@ func main() {
 		<-sigChan
 
 		logger.Info("Shutting down gRPC server...")
+		cancel()
 		grpcServer.GracefulStop()
 	}()
 
@@ -3,25 +3,54 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds the configuration for todo-domain-svc
 type Config struct {
-	GRPCPort    string
-	DatabaseURL string
+	GRPCPort                 string
+	DatabaseURL              string
+	AuditDatabaseURL         string // Optional: enables audit log and user data export/erase
+	TranscriptionDatabaseURL string // Optional: enables user data export/erase
+
+	// Retention policy (0 days disables the purge)
+	RetentionDeletedTodoDays int           // Hard-delete soft-deleted todos after N days
+	RetentionTranscriptDays  int           // Delete raw transcripts after M days
+	RetentionBatchSize       int           // Rows deleted per statement
+	RetentionInterval        time.Duration // Time between purge runs
 }
 
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
-		GRPCPort:    getEnvOrDefault("GRPC_PORT", "50051"),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
+		GRPCPort:                 getEnvOrDefault("GRPC_PORT", "50051"),
+		DatabaseURL:              os.Getenv("DATABASE_URL"),
+		AuditDatabaseURL:         os.Getenv("AUDIT_DATABASE_URL"),
+		TranscriptionDatabaseURL: os.Getenv("TRANSCRIPTION_DATABASE_URL"),
 	}
 
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
 	}
 
+	var err error
+	if cfg.RetentionDeletedTodoDays, err = getEnvInt("RETENTION_DELETED_TODO_DAYS", 30); err != nil {
+		return nil, err
+	}
+	if cfg.RetentionTranscriptDays, err = getEnvInt("RETENTION_TRANSCRIPT_DAYS", 90); err != nil {
+		return nil, err
+	}
+	if cfg.RetentionBatchSize, err = getEnvInt("RETENTION_BATCH_SIZE", 500); err != nil {
+		return nil, err
+	}
+	if cfg.RetentionBatchSize <= 0 {
+		return nil, fmt.Errorf("RETENTION_BATCH_SIZE must be positive")
+	}
+	if cfg.RetentionInterval, err = getEnvDuration("RETENTION_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -31,3 +60,29 @@ func getEnvOrDefault(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// getEnvInt reads a non-negative integer, returning defaultVal if unset
+func getEnvInt(key string, defaultVal int) (int, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", key, val)
+	}
+	return n, nil
+}
+
+// getEnvDuration reads a positive Go duration such as "6h", returning defaultVal if unset
+func getEnvDuration(key string, defaultVal time.Duration) (time.Duration, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration like 24h, got %q", key, val)
+	}
+	return d, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad_Success(t *testing.T) {
@@ -104,3 +105,127 @@ func TestGetEnvOrDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_OptionalDatabaseURLs(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost:5432/todo_db")
+	os.Setenv("AUDIT_DATABASE_URL", "postgres://localhost:5432/audit_db")
+	os.Setenv("TRANSCRIPTION_DATABASE_URL", "postgres://localhost:5432/transcription_db")
+	defer func() {
+		os.Unsetenv("DATABASE_URL")
+		os.Unsetenv("AUDIT_DATABASE_URL")
+		os.Unsetenv("TRANSCRIPTION_DATABASE_URL")
+	}()
+
+	cfg, err := Load()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AuditDatabaseURL != "postgres://localhost:5432/audit_db" {
+		t.Errorf("expected AuditDatabaseURL, got %s", cfg.AuditDatabaseURL)
+	}
+	if cfg.TranscriptionDatabaseURL != "postgres://localhost:5432/transcription_db" {
+		t.Errorf("expected TranscriptionDatabaseURL, got %s", cfg.TranscriptionDatabaseURL)
+	}
+}
+
+func TestLoad_OptionalDatabaseURLsDefaultEmpty(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost:5432/todo_db")
+	os.Unsetenv("AUDIT_DATABASE_URL")
+	os.Unsetenv("TRANSCRIPTION_DATABASE_URL")
+	defer os.Unsetenv("DATABASE_URL")
+
+	cfg, err := Load()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AuditDatabaseURL != "" || cfg.TranscriptionDatabaseURL != "" {
+		t.Errorf("expected empty optional URLs, got %q and %q", cfg.AuditDatabaseURL, cfg.TranscriptionDatabaseURL)
+	}
+}
+
+func TestLoad_RetentionDefaults(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost:5432/todos")
+	defer os.Unsetenv("DATABASE_URL")
+
+	cfg, err := Load()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RetentionDeletedTodoDays != 30 {
+		t.Errorf("expected RetentionDeletedTodoDays 30, got %d", cfg.RetentionDeletedTodoDays)
+	}
+	if cfg.RetentionTranscriptDays != 90 {
+		t.Errorf("expected RetentionTranscriptDays 90, got %d", cfg.RetentionTranscriptDays)
+	}
+	if cfg.RetentionBatchSize != 500 {
+		t.Errorf("expected RetentionBatchSize 500, got %d", cfg.RetentionBatchSize)
+	}
+	if cfg.RetentionInterval != 24*time.Hour {
+		t.Errorf("expected RetentionInterval 24h, got %v", cfg.RetentionInterval)
+	}
+}
+
+func TestLoad_RetentionOverrides(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost:5432/todos")
+	os.Setenv("RETENTION_DELETED_TODO_DAYS", "7")
+	os.Setenv("RETENTION_TRANSCRIPT_DAYS", "0")
+	os.Setenv("RETENTION_BATCH_SIZE", "100")
+	os.Setenv("RETENTION_INTERVAL", "6h")
+	defer func() {
+		os.Unsetenv("DATABASE_URL")
+		os.Unsetenv("RETENTION_DELETED_TODO_DAYS")
+		os.Unsetenv("RETENTION_TRANSCRIPT_DAYS")
+		os.Unsetenv("RETENTION_BATCH_SIZE")
+		os.Unsetenv("RETENTION_INTERVAL")
+	}()
+
+	cfg, err := Load()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RetentionDeletedTodoDays != 7 || cfg.RetentionTranscriptDays != 0 {
+		t.Errorf("expected 7/0 days, got %d/%d", cfg.RetentionDeletedTodoDays, cfg.RetentionTranscriptDays)
+	}
+	if cfg.RetentionBatchSize != 100 {
+		t.Errorf("expected RetentionBatchSize 100, got %d", cfg.RetentionBatchSize)
+	}
+	if cfg.RetentionInterval != 6*time.Hour {
+		t.Errorf("expected RetentionInterval 6h, got %v", cfg.RetentionInterval)
+	}
+}
+
+func TestLoad_RetentionInvalid(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"RETENTION_DELETED_TODO_DAYS", "thirty"},
+		{"RETENTION_TRANSCRIPT_DAYS", "-1"},
+		{"RETENTION_BATCH_SIZE", "0"},
+		{"RETENTION_INTERVAL", "daily"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			os.Setenv("DATABASE_URL", "postgres://localhost:5432/todos")
+			os.Setenv(tt.key, tt.value)
+			defer func() {
+				os.Unsetenv("DATABASE_URL")
+				os.Unsetenv(tt.key)
+			}()
+
+			cfg, err := Load()
+
+			if err == nil {
+				t.Fatalf("expected error for %s=%s", tt.key, tt.value)
+			}
+			if cfg != nil {
+				t.Error("expected nil config when error occurs")
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+	"hound-todo/shared/logging"
+)
+
+const (
+	DefaultBatchSize = 500
+	DefaultInterval  = 24 * time.Hour
+)
+
+// Policy controls how long data is kept before it is purged
+type Policy struct {
+	DeletedTodoAge time.Duration // Soft-deleted todos older than this are hard-deleted (0 disables)
+	TranscriptAge  time.Duration // Raw transcripts older than this are deleted (0 disables)
+	BatchSize      int           // Rows deleted per statement, keeps locks short
+	Interval       time.Duration // Time between purge runs
+}
+
+// TodoPurger hard-deletes old soft-deleted todos
+type TodoPurger interface {
+	PurgeDeletedTodos(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+}
+
+// TranscriptPurger deletes old transcripts
+type TranscriptPurger interface {
+	PurgeTranscripts(ctx context.Context, createdBefore time.Time, limit int) (int64, error)
+}
+
+// AuditRecorder records purge runs in the audit log
+type AuditRecorder interface {
+	RecordEvent(ctx context.Context, eventType, entityType string, entityID int64, userID string, payload interface{}) (*store.AuditEvent, error)
+}
+
+// Result holds the outcome of a single purge run
+type Result struct {
+	TodosPurged       int64
+	TranscriptsPurged int64
+}
+
+// Worker periodically purges data that is past its retention period
+type Worker struct {
+	todos       TodoPurger
+	transcripts TranscriptPurger // nil if transcription_db is not configured
+	audit       AuditRecorder    // nil if audit_db is not configured
+	policy      Policy
+	logger      *logging.Logger
+	now         func() time.Time
+}
+
+// New creates a retention worker for the given policy
+func New(todos TodoPurger, policy Policy, logger *logging.Logger) *Worker {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = DefaultBatchSize
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = DefaultInterval
+	}
+
+	return &Worker{
+		todos:  todos,
+		policy: policy,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// SetTranscripts enables purging of raw transcripts
+func (w *Worker) SetTranscripts(transcripts TranscriptPurger) {
+	w.transcripts = transcripts
+}
+
+// SetAudit enables recording purge counts in the audit log
+func (w *Worker) SetAudit(audit AuditRecorder) {
+	w.audit = audit
+}
+
+// Run purges immediately and then on every interval until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Retention purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges everything currently past its retention period
+func (w *Worker) RunOnce(ctx context.Context) (*Result, error) {
+	now := w.now()
+	result := &Result{}
+	payload := map[string]interface{}{}
+
+	if w.policy.DeletedTodoAge > 0 {
+		cutoff := now.Add(-w.policy.DeletedTodoAge)
+		n, err := purgeInBatches(ctx, w.policy.BatchSize, func(limit int) (int64, error) {
+			return w.todos.PurgeDeletedTodos(ctx, cutoff, limit)
+		})
+		result.TodosPurged = n
+		payload["todos_purged"] = n
+		payload["deleted_before"] = cutoff
+		if err != nil {
+			w.record(ctx, result, payload)
+			return result, err
+		}
+	}
+
+	if w.policy.TranscriptAge > 0 && w.transcripts != nil {
+		cutoff := now.Add(-w.policy.TranscriptAge)
+		n, err := purgeInBatches(ctx, w.policy.BatchSize, func(limit int) (int64, error) {
+			return w.transcripts.PurgeTranscripts(ctx, cutoff, limit)
+		})
+		result.TranscriptsPurged = n
+		payload["transcripts_purged"] = n
+		payload["transcripts_before"] = cutoff
+		if err != nil {
+			w.record(ctx, result, payload)
+			return result, err
+		}
+	}
+
+	w.record(ctx, result, payload)
+	w.logger.Info("Retention purge complete: %d todos, %d transcripts", result.TodosPurged, result.TranscriptsPurged)
+	return result, nil
+}
+
+// record writes the purge counts to the audit log, skipping runs that removed nothing
+func (w *Worker) record(ctx context.Context, result *Result, payload map[string]interface{}) {
+	if w.audit == nil || result.TodosPurged+result.TranscriptsPurged == 0 {
+		return
+	}
+
+	if _, err := w.audit.RecordEvent(ctx, "retention_purge", "retention", 0, "system", payload); err != nil {
+		w.logger.Error("Failed to record retention purge: %v", err)
+	}
+}
+
+// purgeInBatches calls purge until a batch removes fewer rows than the batch size
+func purgeInBatches(ctx context.Context, batchSize int, purge func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := purge(batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+	"hound-todo/shared/logging"
+)
+
+// =============================================================================
+// Mocks
+// =============================================================================
+
+// mockPurger hands out rows from a fixed pool, at most limit per call
+type mockPurger struct {
+	remaining  int64
+	err        error
+	calls      int
+	lastCutoff time.Time
+}
+
+func (m *mockPurger) purge(cutoff time.Time, limit int) (int64, error) {
+	m.calls++
+	m.lastCutoff = cutoff
+	if m.err != nil {
+		return 0, m.err
+	}
+	n := min(m.remaining, int64(limit))
+	m.remaining -= n
+	return n, nil
+}
+
+func (m *mockPurger) PurgeDeletedTodos(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	return m.purge(deletedBefore, limit)
+}
+
+func (m *mockPurger) PurgeTranscripts(ctx context.Context, createdBefore time.Time, limit int) (int64, error) {
+	return m.purge(createdBefore, limit)
+}
+
+type mockAudit struct {
+	events []map[string]interface{}
+}
+
+func (m *mockAudit) RecordEvent(ctx context.Context, eventType, entityType string, entityID int64, userID string, payload interface{}) (*store.AuditEvent, error) {
+	m.events = append(m.events, payload.(map[string]interface{}))
+	return &store.AuditEvent{EventType: eventType}, nil
+}
+
+var testNow = time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+
+func newTestWorker(todos *mockPurger, policy Policy) *Worker {
+	w := New(todos, policy, logging.New("test"))
+	w.now = func() time.Time { return testNow }
+	return w
+}
+
+// =============================================================================
+// RunOnce Tests
+// =============================================================================
+
+func TestRunOnce_PurgesInBatches(t *testing.T) {
+	todos := &mockPurger{remaining: 25}
+	transcripts := &mockPurger{remaining: 3}
+	audit := &mockAudit{}
+
+	w := newTestWorker(todos, Policy{DeletedTodoAge: 30 * 24 * time.Hour, TranscriptAge: 90 * 24 * time.Hour, BatchSize: 10})
+	w.SetTranscripts(transcripts)
+	w.SetAudit(audit)
+
+	result, err := w.RunOnce(context.Background())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.TodosPurged != 25 || result.TranscriptsPurged != 3 {
+		t.Errorf("expected 25 todos and 3 transcripts, got %+v", result)
+	}
+	// 10 + 10 + 5 - the short batch ends the loop
+	if todos.calls != 3 {
+		t.Errorf("expected 3 todo batches, got %d", todos.calls)
+	}
+	if !todos.lastCutoff.Equal(testNow.AddDate(0, 0, -30)) {
+		t.Errorf("unexpected todo cutoff: %v", todos.lastCutoff)
+	}
+	if !transcripts.lastCutoff.Equal(testNow.AddDate(0, 0, -90)) {
+		t.Errorf("unexpected transcript cutoff: %v", transcripts.lastCutoff)
+	}
+	if len(audit.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(audit.events))
+	}
+	if audit.events[0]["todos_purged"] != int64(25) || audit.events[0]["transcripts_purged"] != int64(3) {
+		t.Errorf("unexpected audit payload: %v", audit.events[0])
+	}
+}
+
+func TestRunOnce_DisabledPolicies(t *testing.T) {
+	todos := &mockPurger{remaining: 5}
+	transcripts := &mockPurger{remaining: 5}
+
+	w := newTestWorker(todos, Policy{BatchSize: 10})
+	w.SetTranscripts(transcripts)
+
+	result, err := w.RunOnce(context.Background())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if todos.calls != 0 || transcripts.calls != 0 {
+		t.Errorf("expected no purges when ages are zero, got %d and %d calls", todos.calls, transcripts.calls)
+	}
+	if result.TodosPurged != 0 || result.TranscriptsPurged != 0 {
+		t.Errorf("expected nothing purged, got %+v", result)
+	}
+}
+
+func TestRunOnce_NothingToPurgeSkipsAudit(t *testing.T) {
+	audit := &mockAudit{}
+	w := newTestWorker(&mockPurger{}, Policy{DeletedTodoAge: time.Hour, BatchSize: 10})
+	w.SetAudit(audit)
+
+	if _, err := w.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(audit.events) != 0 {
+		t.Errorf("expected no audit event for an empty run, got %d", len(audit.events))
+	}
+}
+
+func TestRunOnce_ErrorStillRecordsProgress(t *testing.T) {
+	transcripts := &mockPurger{err: errors.New("connection reset")}
+	audit := &mockAudit{}
+
+	w := newTestWorker(&mockPurger{remaining: 4}, Policy{DeletedTodoAge: time.Hour, TranscriptAge: time.Hour, BatchSize: 10})
+	w.SetTranscripts(transcripts)
+	w.SetAudit(audit)
+
+	result, err := w.RunOnce(context.Background())
+
+	if err == nil {
+		t.Fatal("expected error from transcript purge")
+	}
+	if result.TodosPurged != 4 {
+		t.Errorf("expected 4 todos purged before the error, got %d", result.TodosPurged)
+	}
+	if len(audit.events) != 1 {
+		t.Errorf("expected partial progress to be audited, got %d events", len(audit.events))
+	}
+}
+
+func TestRunOnce_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	todos := &mockPurger{remaining: 5}
+	w := newTestWorker(todos, Policy{DeletedTodoAge: time.Hour, BatchSize: 10})
+
+	if _, err := w.RunOnce(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if todos.calls != 0 {
+		t.Errorf("expected no purge calls after cancellation, got %d", todos.calls)
+	}
+}
+
+// =============================================================================
+// New Tests
+// =============================================================================
+
+func TestNew_Defaults(t *testing.T) {
+	w := New(&mockPurger{}, Policy{}, logging.New("test"))
+
+	if w.policy.BatchSize != DefaultBatchSize {
+		t.Errorf("expected default batch size %d, got %d", DefaultBatchSize, w.policy.BatchSize)
+	}
+	if w.policy.Interval != DefaultInterval {
+		t.Errorf("expected default interval %v, got %v", DefaultInterval, w.policy.Interval)
+	}
+}
@@ -1,30 +1,50 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/todo-domain/internal/stats"
 	"hound-todo/services/todo-domain/internal/store"
+	"hound-todo/services/todo-domain/internal/transfer"
+	"hound-todo/services/todo-domain/internal/userdata"
 	"hound-todo/shared/logging"
 )
 
+const (
+	// exportChunkSize is the size of each streamed piece of a user data export
+	exportChunkSize = 32 * 1024
+
+	// maxImportRows bounds how many todos a single import can create
+	maxImportRows = 5000
+
+	// maxCategoryLength is the size of the category column
+	maxCategoryLength = 50
+)
+
 // Server implements the TodoDomain gRPC service
 type Server struct {
 	todov1.UnimplementedTodoDomainServer // Embed for forward compatibility
 	store                                *store.Store
+	audit                                *store.AuditStore      // nil if audit_db is not configured
+	transcripts                          *store.TranscriptStore // nil if transcription_db is not configured
 	logger                               *logging.Logger
 }
 
 // New creates a new gRPC server
-func New(store *store.Store, logger *logging.Logger) *Server {
+func New(store *store.Store, audit *store.AuditStore, transcripts *store.TranscriptStore, logger *logging.Logger) *Server {
 	return &Server{
-		store:  store,
-		logger: logger,
+		store:       store,
+		audit:       audit,
+		transcripts: transcripts,
+		logger:      logger,
 	}
 }
 
@@ -37,6 +57,9 @@ func (s *Server) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest)
 	if req.Title == "" {
 		return nil, status.Error(codes.InvalidArgument, "title is required")
 	}
+	if len(req.Category) > maxCategoryLength {
+		return nil, status.Errorf(codes.InvalidArgument, "category must be at most %d characters", maxCategoryLength)
+	}
 
 	// Check idempotency key
 	if req.IdempotencyKey != "" {
@@ -54,8 +77,23 @@ func (s *Server) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest)
 		}
 	}
 
+	// A step can only be added to a todo of the user's that isn't in the trash
+	if req.ParentId != 0 {
+		parent, err := s.store.GetTodo(ctx, req.ParentId)
+		if err == store.ErrNotFound || (err == nil && parent.UserID != req.UserId) {
+			return nil, status.Error(codes.NotFound, "parent todo not found")
+		}
+		if err != nil {
+			s.logger.Error("Failed to get parent todo: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if parent.Status == "deleted" {
+			return nil, status.Error(codes.FailedPrecondition, "parent todo is deleted")
+		}
+	}
+
 	// Create the todo
-	todo, err := s.store.CreateTodo(ctx, req.UserId, req.Title, req.Description)
+	todo, err := s.store.CreateTodo(ctx, req.UserId, req.Title, req.Description, req.Category, req.ParentId)
 	if err != nil {
 		s.logger.Error("Failed to create todo: %v", err)
 		return nil, status.Error(codes.Internal, "failed to create todo")
@@ -67,7 +105,7 @@ func (s *Server) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest)
 
 	// Store idempotency key
 	if req.IdempotencyKey != "" {
-		if err := s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, resp); err != nil {
+		if err := s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp); err != nil {
 			s.logger.Error("Failed to store idempotency key: %v", err)
 			// Don't fail the request, the todo was created
 		}
@@ -124,7 +162,7 @@ func (s *Server) CompleteTodo(ctx context.Context, req *todov1.CompleteTodoReque
 	}
 
 	if req.IdempotencyKey != "" {
-		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, resp)
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
 	}
 
 	s.logger.Info("Completed todo %d for user %s", req.TodoId, req.UserId)
@@ -160,6 +198,8 @@ func (s *Server) ListTodos(ctx context.Context, req *todov1.ListTodosRequest) (*
 		filter.CompletedBefore = &t
 	}
 
+	filter.Category = req.Category
+
 	todos, err := s.store.ListTodos(ctx, req.UserId, filter)
 	if err != nil {
 		s.logger.Error("Failed to list todos: %v", err)
@@ -217,7 +257,7 @@ func (s *Server) DeleteTodo(ctx context.Context, req *todov1.DeleteTodoRequest)
 	}
 
 	if req.IdempotencyKey != "" {
-		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, resp)
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
 	}
 
 	s.logger.Info("Deleted todo %d for user %s", req.TodoId, req.UserId)
@@ -265,13 +305,537 @@ func (s *Server) EditTodo(ctx context.Context, req *todov1.EditTodoRequest) (*to
 	}
 
 	if req.IdempotencyKey != "" {
-		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, resp)
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
 	}
 
 	s.logger.Info("Edited todo %d for user %s", req.TodoId, req.UserId)
 	return resp, nil
 }
 
+// RestoreTodo brings a soft-deleted todo back out of the trash
+func (s *Server) RestoreTodo(ctx context.Context, req *todov1.RestoreTodoRequest) (*todov1.RestoreTodoResponse, error) {
+	if req.TodoId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "todo_id is required")
+	}
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Check idempotency key
+	if req.IdempotencyKey != "" {
+		cached, err := s.store.CheckIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("Failed to check idempotency key: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if cached != nil {
+			var resp todov1.RestoreTodoResponse
+			if err := json.Unmarshal(cached, &resp); err == nil {
+				return &resp, nil
+			}
+		}
+	}
+
+	todo, err := s.store.RestoreTodo(ctx, req.TodoId, req.UserId)
+	if err == store.ErrNotFound {
+		return nil, status.Error(codes.NotFound, "todo not found")
+	}
+	if err == store.ErrNotOwner {
+		return nil, status.Error(codes.PermissionDenied, "you do not own this todo")
+	}
+	if err != nil {
+		s.logger.Error("Failed to restore todo: %v", err)
+		return nil, status.Error(codes.Internal, "failed to restore todo")
+	}
+
+	resp := &todov1.RestoreTodoResponse{
+		Todo: storeToProto(todo),
+	}
+
+	if req.IdempotencyKey != "" {
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
+	}
+
+	s.logger.Info("Restored todo %d for user %s", req.TodoId, req.UserId)
+	return resp, nil
+}
+
+// EmptyTrash hard-deletes all of a user's soft-deleted todos
+func (s *Server) EmptyTrash(ctx context.Context, req *todov1.EmptyTrashRequest) (*todov1.EmptyTrashResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Check idempotency key
+	if req.IdempotencyKey != "" {
+		cached, err := s.store.CheckIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("Failed to check idempotency key: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if cached != nil {
+			var resp todov1.EmptyTrashResponse
+			if err := json.Unmarshal(cached, &resp); err == nil {
+				return &resp, nil
+			}
+		}
+	}
+
+	deleted, err := s.store.EmptyTrash(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to empty trash: %v", err)
+		return nil, status.Error(codes.Internal, "failed to empty trash")
+	}
+
+	resp := &todov1.EmptyTrashResponse{
+		TodosDeleted: deleted,
+	}
+
+	if req.IdempotencyKey != "" {
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
+	}
+
+	s.logger.Info("Emptied trash for user %s: %d todos", req.UserId, deleted)
+	return resp, nil
+}
+
+// GetStats returns completion statistics and streaks for a user
+func (s *Server) GetStats(ctx context.Context, req *todov1.GetStatsRequest) (*todov1.GetStatsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	loc := time.UTC
+	if req.Timezone != "" {
+		l, err := time.LoadLocation(req.Timezone)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown timezone: %s", req.Timezone)
+		}
+		loc = l
+	}
+	if req.Days > stats.MaxDays || req.Weeks > stats.MaxWeeks || req.OldestLimit > stats.MaxOldestLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "days, weeks and oldest_limit must be at most %d, %d and %d",
+			stats.MaxDays, stats.MaxWeeks, stats.MaxOldestLimit)
+	}
+
+	completed, err := s.store.ListTodos(ctx, req.UserId, store.ListTodosFilter{Status: "completed"})
+	if err != nil {
+		s.logger.Error("Failed to list completed todos: %v", err)
+		return nil, status.Error(codes.Internal, "failed to compute stats")
+	}
+	active, err := s.store.ListTodos(ctx, req.UserId, store.ListTodosFilter{Status: "active"})
+	if err != nil {
+		s.logger.Error("Failed to list active todos: %v", err)
+		return nil, status.Error(codes.Internal, "failed to compute stats")
+	}
+
+	st := stats.Compute(completed, active, stats.Options{
+		Now:         time.Now(),
+		Location:    loc,
+		Days:        int(req.Days),
+		Weeks:       int(req.Weeks),
+		OldestLimit: int(req.OldestLimit),
+	})
+
+	return statsToProto(st), nil
+}
+
+// statsToProto converts computed stats to a protobuf response
+func statsToProto(st *stats.Stats) *todov1.GetStatsResponse {
+	resp := &todov1.GetStatsResponse{
+		CurrentStreak:            int32(st.CurrentStreak),
+		LongestStreak:            int32(st.LongestStreak),
+		AverageCompletionSeconds: int64(st.AverageCompletion.Seconds()),
+		TotalCompleted:           int32(st.TotalCompleted),
+		TotalOpen:                int32(st.TotalOpen),
+	}
+
+	for _, d := range st.Daily {
+		resp.Daily = append(resp.Daily, &todov1.DayCount{Date: d.Date, Completed: int32(d.Completed)})
+	}
+	for _, w := range st.Weekly {
+		resp.Weekly = append(resp.Weekly, &todov1.WeekCount{WeekStart: w.WeekStart, Completed: int32(w.Completed)})
+	}
+	for _, todo := range st.OldestOpen {
+		resp.OldestOpen = append(resp.OldestOpen, storeToProto(todo))
+	}
+
+	return resp
+}
+
+// ExportUserData streams a bundle of a user's todos, history and transcripts
+func (s *Server) ExportUserData(req *todov1.ExportUserDataRequest, stream todov1.TodoDomain_ExportUserDataServer) error {
+	if req.UserId == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if s.audit == nil || s.transcripts == nil {
+		return status.Error(codes.FailedPrecondition, "audit and transcription databases are not configured")
+	}
+
+	ctx := stream.Context()
+
+	todos, err := s.store.ListTodos(ctx, req.UserId, store.ListTodosFilter{IncludeDeleted: true})
+	if err != nil {
+		s.logger.Error("Failed to list todos for export: %v", err)
+		return status.Error(codes.Internal, "failed to export user data")
+	}
+	events, err := s.audit.ListEventsByUser(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to list audit events for export: %v", err)
+		return status.Error(codes.Internal, "failed to export user data")
+	}
+	transcripts, err := s.transcripts.ListTranscriptsByUser(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to list transcripts for export: %v", err)
+		return status.Error(codes.Internal, "failed to export user data")
+	}
+
+	now := time.Now()
+	bundle := userdata.NewBundle(req.UserId, todos, events, transcripts, now)
+	zipped := req.Format == todov1.ExportFormat_EXPORT_FORMAT_ZIP
+
+	var buf bytes.Buffer
+	if zipped {
+		err = userdata.WriteZip(&buf, bundle)
+	} else {
+		err = userdata.WriteJSON(&buf, bundle)
+	}
+	if err != nil {
+		s.logger.Error("Failed to encode export: %v", err)
+		return status.Error(codes.Internal, "failed to export user data")
+	}
+
+	filename := userdata.Filename(now, zipped)
+	data := buf.Bytes()
+	for first := true; first || len(data) > 0; first = false {
+		n := min(len(data), exportChunkSize)
+		chunk := &todov1.ExportUserDataChunk{Data: data[:n]}
+		if first {
+			chunk.Filename = filename
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+
+	s.logger.Info("Exported data for user %s: %d todos, %d events, %d transcripts (%d bytes)",
+		req.UserId, len(todos), len(events), len(transcripts), buf.Len())
+	return nil
+}
+
+// EraseUserData hard-deletes or anonymizes a user's data across all three databases
+// and leaves an anonymous tombstone in the audit log
+func (s *Server) EraseUserData(ctx context.Context, req *todov1.EraseUserDataRequest) (*todov1.EraseUserDataResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if s.audit == nil || s.transcripts == nil {
+		return nil, status.Error(codes.FailedPrecondition, "audit and transcription databases are not configured")
+	}
+
+	// Check idempotency key
+	if req.IdempotencyKey != "" {
+		cached, err := s.store.CheckIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("Failed to check idempotency key: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if cached != nil {
+			var resp todov1.EraseUserDataResponse
+			if err := json.Unmarshal(cached, &resp); err == nil {
+				return &resp, nil
+			}
+		}
+	}
+
+	anonymousID, err := userdata.NewAnonymousID()
+	if err != nil {
+		s.logger.Error("Failed to generate anonymous id: %v", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	anonymize := req.Mode == todov1.EraseMode_ERASE_MODE_ANONYMIZE
+	resp := &todov1.EraseUserDataResponse{TombstoneId: anonymousID}
+
+	// There is no transaction spanning the three databases, so erase the most
+	// sensitive data first. A failure part way through is safe to retry.
+	if anonymize {
+		resp.TranscriptsErased, err = s.transcripts.AnonymizeTranscriptsByUser(ctx, req.UserId, anonymousID)
+	} else {
+		resp.TranscriptsErased, err = s.transcripts.EraseTranscriptsByUser(ctx, req.UserId)
+	}
+	if err != nil {
+		s.logger.Error("Failed to erase transcripts: %v", err)
+		return nil, status.Error(codes.Internal, "failed to erase user data")
+	}
+
+	if anonymize {
+		resp.TodosErased, err = s.store.AnonymizeTodos(ctx, req.UserId, anonymousID)
+	} else {
+		resp.TodosErased, err = s.store.EraseTodos(ctx, req.UserId)
+	}
+	if err != nil {
+		s.logger.Error("Failed to erase todos: %v", err)
+		return nil, status.Error(codes.Internal, "failed to erase user data")
+	}
+
+	resp.IdempotencyKeysErased, err = s.store.EraseIdempotencyKeys(ctx, req.UserId)
+	if err != nil {
+		s.logger.Error("Failed to erase idempotency keys: %v", err)
+		return nil, status.Error(codes.Internal, "failed to erase user data")
+	}
+
+	if anonymize {
+		resp.LlmUsageErased, err = s.store.AnonymizeLLMUsage(ctx, req.UserId, anonymousID)
+	} else {
+		resp.LlmUsageErased, err = s.store.EraseLLMUsage(ctx, req.UserId)
+	}
+	if err != nil {
+		s.logger.Error("Failed to erase LLM usage: %v", err)
+		return nil, status.Error(codes.Internal, "failed to erase user data")
+	}
+
+	if anonymize {
+		resp.AuditEventsErased, err = s.audit.AnonymizeEventsByUser(ctx, req.UserId, anonymousID)
+	} else {
+		resp.AuditEventsErased, err = s.audit.EraseEventsByUser(ctx, req.UserId)
+	}
+	if err != nil {
+		s.logger.Error("Failed to erase audit events: %v", err)
+		return nil, status.Error(codes.Internal, "failed to erase user data")
+	}
+
+	// Tombstone records that an erasure happened without recording who it was for
+	_, err = s.audit.RecordEvent(ctx, "user_data_erased", "user", 0, anonymousID, map[string]interface{}{
+		"mode":                    req.Mode.String(),
+		"todos_erased":            resp.TodosErased,
+		"idempotency_keys_erased": resp.IdempotencyKeysErased,
+		"audit_events_erased":     resp.AuditEventsErased,
+		"transcripts_erased":      resp.TranscriptsErased,
+		"llm_usage_erased":        resp.LlmUsageErased,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record erasure tombstone: %v", err)
+		// Don't fail the request, the data is already gone
+	}
+
+	// Cached without the user, who has just been forgotten
+	if req.IdempotencyKey != "" {
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, "", resp)
+	}
+
+	s.logger.Info("Erased user data (%s) as %s: %d todos, %d idempotency keys, %d audit events, %d transcripts, %d LLM usage records",
+		req.Mode, anonymousID, resp.TodosErased, resp.IdempotencyKeysErased, resp.AuditEventsErased, resp.TranscriptsErased, resp.LlmUsageErased)
+	return resp, nil
+}
+
+// ImportTodos creates todos from a CSV, JSON, todo.txt or iCalendar file.
+// Valid rows are imported and invalid ones reported; a dry run only reports.
+func (s *Server) ImportTodos(ctx context.Context, req *todov1.ImportTodosRequest) (*todov1.ImportTodosResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	format, ok := formatFromProto(req.Format)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "format is required")
+	}
+	if len(req.Data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "data is required")
+	}
+
+	// Dry runs change nothing, so there is nothing to deduplicate
+	useIdempotency := req.IdempotencyKey != "" && !req.DryRun
+
+	// Check idempotency key
+	if useIdempotency {
+		cached, err := s.store.CheckIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("Failed to check idempotency key: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if cached != nil {
+			var resp todov1.ImportTodosResponse
+			if err := json.Unmarshal(cached, &resp); err == nil {
+				return &resp, nil
+			}
+		}
+	}
+
+	result, err := transfer.Decode(format, bytes.NewReader(req.Data), time.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "could not read %s file: %v", format, err)
+	}
+	if result.Total > maxImportRows {
+		return nil, status.Errorf(codes.InvalidArgument, "file has %d todos, the limit is %d", result.Total, maxImportRows)
+	}
+
+	if !req.DryRun && len(result.Todos) > 0 {
+		if err := s.store.ImportTodos(ctx, req.UserId, result.Todos); err != nil {
+			s.logger.Error("Failed to import todos: %v", err)
+			return nil, status.Error(codes.Internal, "failed to import todos")
+		}
+	}
+
+	resp := &todov1.ImportTodosResponse{
+		Imported: int32(len(result.Todos)),
+		Rejected: int32(result.Rejected()),
+	}
+	for _, e := range result.Errors {
+		resp.Errors = append(resp.Errors, &todov1.ImportRowError{
+			Row:     int32(e.Row),
+			Field:   e.Field,
+			Message: e.Message,
+		})
+	}
+	for _, todo := range result.Todos {
+		todo.UserID = req.UserId
+		resp.Todos = append(resp.Todos, storeToProto(todo))
+	}
+
+	if useIdempotency {
+		s.store.StoreIdempotencyKey(ctx, req.IdempotencyKey, req.UserId, resp)
+	}
+
+	s.logger.Info("Imported %s for user %s: %d imported, %d rejected (dry run: %v)",
+		format, req.UserId, resp.Imported, resp.Rejected, req.DryRun)
+	return resp, nil
+}
+
+// ExportTodos encodes a user's todos as a CSV, JSON, todo.txt or iCalendar file
+func (s *Server) ExportTodos(ctx context.Context, req *todov1.ExportTodosRequest) (*todov1.ExportTodosResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	format, ok := formatFromProto(req.Format)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "format is required")
+	}
+
+	filter := store.ListTodosFilter{}
+	switch req.Status {
+	case todov1.TodoStatus_TODO_STATUS_ACTIVE:
+		filter.Status = "active"
+	case todov1.TodoStatus_TODO_STATUS_COMPLETED:
+		filter.Status = "completed"
+	case todov1.TodoStatus_TODO_STATUS_DELETED:
+		filter.Status = "deleted"
+	}
+
+	todos, err := s.store.ListTodos(ctx, req.UserId, filter)
+	if err != nil {
+		s.logger.Error("Failed to list todos for export: %v", err)
+		return nil, status.Error(codes.Internal, "failed to export todos")
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := transfer.Encode(format, &buf, todos, now); err != nil {
+		s.logger.Error("Failed to encode todos: %v", err)
+		return nil, status.Error(codes.Internal, "failed to export todos")
+	}
+
+	s.logger.Info("Exported %d todos as %s for user %s", len(todos), format, req.UserId)
+	return &todov1.ExportTodosResponse{
+		Data:        buf.Bytes(),
+		Filename:    transfer.Filename(format, now),
+		ContentType: transfer.ContentType(format),
+		Count:       int32(len(todos)),
+	}, nil
+}
+
+// RecordLLMUsage stores the tokens and cost of the LLM calls made for one message
+func (s *Server) RecordLLMUsage(ctx context.Context, req *todov1.RecordLLMUsageRequest) (*todov1.RecordLLMUsageResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.Model == "" {
+		return nil, status.Error(codes.InvalidArgument, "model is required")
+	}
+	if req.Calls < 0 || req.PromptTokens < 0 || req.CompletionTokens < 0 || req.CostMicros < 0 {
+		return nil, status.Error(codes.InvalidArgument, "usage can't be negative")
+	}
+
+	recorded, err := s.store.RecordLLMUsage(ctx, &store.LLMUsage{
+		UserID:           req.UserId,
+		Model:            req.Model,
+		Calls:            int(req.Calls),
+		PromptTokens:     req.PromptTokens,
+		CompletionTokens: req.CompletionTokens,
+		CostMicros:       req.CostMicros,
+	}, req.IdempotencyKey)
+	if err != nil {
+		s.logger.Error("Failed to record LLM usage: %v", err)
+		return nil, status.Error(codes.Internal, "failed to record LLM usage")
+	}
+
+	return &todov1.RecordLLMUsageResponse{Recorded: recorded}, nil
+}
+
+// GetLLMUsage totals LLM usage per user, day and model over a period
+func (s *Server) GetLLMUsage(ctx context.Context, req *todov1.GetLLMUsageRequest) (*todov1.GetLLMUsageResponse, error) {
+	filter := store.LLMUsageFilter{UserID: req.UserId, Location: time.UTC}
+	if req.Timezone != "" {
+		loc, err := time.LoadLocation(req.Timezone)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown timezone: %s", req.Timezone)
+		}
+		filter.Location = loc
+	}
+	if req.Since != nil {
+		since := req.Since.AsTime()
+		filter.Since = &since
+	}
+	if req.Until != nil {
+		until := req.Until.AsTime()
+		filter.Until = &until
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return nil, status.Error(codes.InvalidArgument, "until must be after since")
+	}
+
+	usage, err := s.store.SummarizeLLMUsage(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to summarize LLM usage: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get LLM usage")
+	}
+
+	resp := &todov1.GetLLMUsageResponse{}
+	for _, u := range usage {
+		resp.Usage = append(resp.Usage, usageToProto(u))
+	}
+	return resp, nil
+}
+
+// usageToProto converts a usage summary to protobuf
+func usageToProto(u *store.LLMUsage) *todov1.LLMUsage {
+	return &todov1.LLMUsage{
+		UserId:           u.UserID,
+		Date:             u.Date,
+		Model:            u.Model,
+		Calls:            int32(u.Calls),
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		CostMicros:       u.CostMicros,
+	}
+}
+
+// formatFromProto maps a proto file format to a transfer format
+func formatFromProto(f todov1.TodoFormat) (transfer.Format, bool) {
+	switch f {
+	case todov1.TodoFormat_TODO_FORMAT_CSV:
+		return transfer.FormatCSV, true
+	case todov1.TodoFormat_TODO_FORMAT_JSON:
+		return transfer.FormatJSON, true
+	case todov1.TodoFormat_TODO_FORMAT_TODO_TXT:
+		return transfer.FormatTodoTxt, true
+	case todov1.TodoFormat_TODO_FORMAT_ICALENDAR:
+		return transfer.FormatICalendar, true
+	default:
+		return "", false
+	}
+}
+
 // storeToProto converts a store.Todo to a protobuf Todo
 func storeToProto(t *store.Todo) *todov1.Todo {
 	proto := &todov1.Todo{
@@ -280,6 +844,8 @@ func storeToProto(t *store.Todo) *todov1.Todo {
 		Title:       t.Title,
 		Description: t.Description,
 		CreatedAt:   timestamppb.New(t.CreatedAt),
+		Priority:    int32(t.Priority),
+		Category:    t.Category,
 	}
 
 	// Map status string to proto enum
@@ -298,5 +864,17 @@ func storeToProto(t *store.Todo) *todov1.Todo {
 		proto.CompletedAt = timestamppb.New(*t.CompletedAt)
 	}
 
+	if t.DeletedAt != nil {
+		proto.DeletedAt = timestamppb.New(*t.DeletedAt)
+	}
+
+	if t.ParentID != nil {
+		proto.ParentId = *t.ParentID
+	}
+
+	if t.DueAt != nil {
+		proto.DueAt = timestamppb.New(*t.DueAt)
+	}
+
 	return proto
 }
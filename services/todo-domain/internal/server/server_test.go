@@ -11,7 +11,9 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	todov1 "hound-todo/api/todo/v1"
+	"hound-todo/services/todo-domain/internal/stats"
 	"hound-todo/services/todo-domain/internal/store"
+	"hound-todo/services/todo-domain/internal/transfer"
 	"hound-todo/shared/logging"
 )
 
@@ -45,7 +47,7 @@ type mockStore struct {
 	lastListFilter   store.ListTodosFilter
 }
 
-func (m *mockStore) CreateTodo(ctx context.Context, userID, title, description string) (*store.Todo, error) {
+func (m *mockStore) CreateTodo(ctx context.Context, userID, title, description, category string, parentID int64) (*store.Todo, error) {
 	m.lastCreateUserID = userID
 	m.lastCreateTitle = title
 	if m.createErr != nil {
@@ -98,7 +100,7 @@ func (m *mockStore) CheckIdempotencyKey(ctx context.Context, key string) ([]byte
 	return m.checkIdempotencyResp, nil
 }
 
-func (m *mockStore) StoreIdempotencyKey(ctx context.Context, key string, response interface{}) error {
+func (m *mockStore) StoreIdempotencyKey(ctx context.Context, key, userID string, response interface{}) error {
 	return m.storeIdempotencyErr
 }
 
@@ -393,6 +395,27 @@ func TestStoreToProto_AllStatuses(t *testing.T) {
 	}
 }
 
+func TestStoreToProto_WithParent(t *testing.T) {
+	parentID := int64(4)
+	todo := &store.Todo{ID: 9, UserID: "user123", Title: "sweep the floor", Status: "active", CreatedAt: time.Now(), ParentID: &parentID}
+
+	if proto := storeToProto(todo); proto.ParentId != 4 {
+		t.Errorf("expected ParentId 4, got %d", proto.ParentId)
+	}
+	todo.ParentID = nil
+	if proto := storeToProto(todo); proto.ParentId != 0 {
+		t.Errorf("expected no parent, got %d", proto.ParentId)
+	}
+}
+
+func TestStoreToProto_WithCategory(t *testing.T) {
+	todo := &store.Todo{ID: 3, UserID: "user123", Title: "buy milk", Status: "active", CreatedAt: time.Now(), Category: "errands"}
+
+	if proto := storeToProto(todo); proto.Category != "errands" {
+		t.Errorf("expected Category errands, got %q", proto.Category)
+	}
+}
+
 func TestStoreToProto_WithCompletedAt(t *testing.T) {
 	completedAt := time.Now()
 	todo := &store.Todo{
@@ -414,6 +437,27 @@ func TestStoreToProto_WithCompletedAt(t *testing.T) {
 	}
 }
 
+func TestStoreToProto_WithDeletedAt(t *testing.T) {
+	deletedAt := time.Now()
+	todo := &store.Todo{
+		ID:        1,
+		UserID:    "user123",
+		Title:     "deleted task",
+		Status:    "deleted",
+		CreatedAt: time.Now().Add(-1 * time.Hour),
+		DeletedAt: &deletedAt,
+	}
+
+	proto := storeToProto(todo)
+
+	if proto.DeletedAt == nil {
+		t.Fatal("expected DeletedAt to be set")
+	}
+	if !proto.DeletedAt.AsTime().Equal(deletedAt) {
+		t.Errorf("expected DeletedAt %v, got %v", deletedAt, proto.DeletedAt.AsTime())
+	}
+}
+
 func TestStoreToProto_WithoutCompletedAt(t *testing.T) {
 	todo := &store.Todo{
 		ID:          1,
@@ -429,6 +473,9 @@ func TestStoreToProto_WithoutCompletedAt(t *testing.T) {
 	if proto.CompletedAt != nil {
 		t.Error("expected CompletedAt to be nil")
 	}
+	if proto.DeletedAt != nil {
+		t.Error("expected DeletedAt to be nil")
+	}
 }
 
 // =============================================================================
@@ -467,3 +514,382 @@ func TestErrorMapping_NotOwner(t *testing.T) {
 		t.Errorf("expected ErrNotOwner, got %v", err)
 	}
 }
+
+// =============================================================================
+// GetStats Tests
+// =============================================================================
+
+func TestGetStats_MissingUserID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.GetStats(context.Background(), &todov1.GetStatsRequest{UserId: ""})
+
+	if err == nil {
+		t.Fatal("expected error for missing user_id")
+	}
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestGetStats_InvalidTimezone(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.GetStats(context.Background(), &todov1.GetStatsRequest{
+		UserId:   "user123",
+		Timezone: "Not/AZone",
+	})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestGetStats_OutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *todov1.GetStatsRequest
+	}{
+		{"days", &todov1.GetStatsRequest{UserId: "user123", Days: stats.MaxDays + 1}},
+		{"weeks", &todov1.GetStatsRequest{UserId: "user123", Weeks: 1 << 30}},
+		{"oldest limit", &todov1.GetStatsRequest{UserId: "user123", OldestLimit: stats.MaxOldestLimit + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer()
+			ts.Server.store = &store.Store{}
+
+			_, err := ts.GetStats(context.Background(), tt.req)
+
+			st, ok := status.FromError(err)
+			if !ok || st.Code() != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStatsToProto(t *testing.T) {
+	st := &stats.Stats{
+		Daily:             []stats.DayCount{{Date: "2026-01-21", Completed: 2}},
+		Weekly:            []stats.WeekCount{{WeekStart: "2026-01-19", Completed: 5}},
+		CurrentStreak:     3,
+		LongestStreak:     7,
+		AverageCompletion: 90 * time.Minute,
+		OldestOpen:        []*store.Todo{{ID: 4, Title: "call mom", Status: "active", CreatedAt: time.Now()}},
+		TotalCompleted:    12,
+		TotalOpen:         4,
+	}
+
+	resp := statsToProto(st)
+
+	if resp.CurrentStreak != 3 || resp.LongestStreak != 7 {
+		t.Errorf("expected streaks 3/7, got %d/%d", resp.CurrentStreak, resp.LongestStreak)
+	}
+	if resp.AverageCompletionSeconds != 5400 {
+		t.Errorf("expected 5400 seconds, got %d", resp.AverageCompletionSeconds)
+	}
+	if len(resp.Daily) != 1 || resp.Daily[0].Date != "2026-01-21" || resp.Daily[0].Completed != 2 {
+		t.Errorf("unexpected daily counts: %v", resp.Daily)
+	}
+	if len(resp.Weekly) != 1 || resp.Weekly[0].Completed != 5 {
+		t.Errorf("unexpected weekly counts: %v", resp.Weekly)
+	}
+	if len(resp.OldestOpen) != 1 || resp.OldestOpen[0].Status != todov1.TodoStatus_TODO_STATUS_ACTIVE {
+		t.Errorf("unexpected oldest open: %v", resp.OldestOpen)
+	}
+	if resp.TotalCompleted != 12 || resp.TotalOpen != 4 {
+		t.Errorf("expected totals 12/4, got %d/%d", resp.TotalCompleted, resp.TotalOpen)
+	}
+}
+
+// =============================================================================
+// ExportUserData / EraseUserData Tests
+// =============================================================================
+
+func TestExportUserData_MissingUserID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	err := ts.ExportUserData(&todov1.ExportUserDataRequest{UserId: ""}, nil)
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestExportUserData_StoresNotConfigured(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	err := ts.ExportUserData(&todov1.ExportUserDataRequest{UserId: "user123"}, nil)
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition error, got %v", err)
+	}
+}
+
+func TestEraseUserData_MissingUserID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.EraseUserData(context.Background(), &todov1.EraseUserDataRequest{UserId: ""})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestEraseUserData_StoresNotConfigured(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+	ts.Server.audit = &store.AuditStore{}
+
+	_, err := ts.EraseUserData(context.Background(), &todov1.EraseUserDataRequest{UserId: "user123"})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition error, got %v", err)
+	}
+}
+
+// =============================================================================
+// RestoreTodo / EmptyTrash Tests
+// =============================================================================
+
+func TestRestoreTodo_MissingTodoID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.RestoreTodo(context.Background(), &todov1.RestoreTodoRequest{TodoId: 0, UserId: "user123"})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestRestoreTodo_MissingUserID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.RestoreTodo(context.Background(), &todov1.RestoreTodoRequest{TodoId: 1, UserId: ""})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestEmptyTrash_MissingUserID(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.EmptyTrash(context.Background(), &todov1.EmptyTrashRequest{UserId: ""})
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument error, got %v", err)
+	}
+}
+
+func TestStoreToProto_WithDueAtAndPriority(t *testing.T) {
+	dueAt := time.Now().Add(24 * time.Hour)
+	todo := &store.Todo{
+		ID:        1,
+		UserID:    "user123",
+		Title:     "renew passport",
+		Status:    "active",
+		CreatedAt: time.Now(),
+		DueAt:     &dueAt,
+		Priority:  2,
+	}
+
+	proto := storeToProto(todo)
+
+	if proto.DueAt == nil || !proto.DueAt.AsTime().Equal(dueAt) {
+		t.Errorf("expected DueAt %v, got %v", dueAt, proto.DueAt)
+	}
+	if proto.Priority != 2 {
+		t.Errorf("expected Priority 2, got %d", proto.Priority)
+	}
+}
+
+// =============================================================================
+// ImportTodos / ExportTodos Tests
+// =============================================================================
+
+func TestImportTodos_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *todov1.ImportTodosRequest
+	}{
+		{"missing user", &todov1.ImportTodosRequest{Format: todov1.TodoFormat_TODO_FORMAT_CSV, Data: []byte("title\nx\n")}},
+		{"missing format", &todov1.ImportTodosRequest{UserId: "user123", Data: []byte("title\nx\n")}},
+		{"missing data", &todov1.ImportTodosRequest{UserId: "user123", Format: todov1.TodoFormat_TODO_FORMAT_CSV}},
+		{"unreadable file", &todov1.ImportTodosRequest{UserId: "user123", Format: todov1.TodoFormat_TODO_FORMAT_CSV, Data: []byte("name only\nx\n"), DryRun: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer()
+			ts.Server.store = &store.Store{}
+
+			_, err := ts.ImportTodos(context.Background(), tt.req)
+
+			st, ok := status.FromError(err)
+			if !ok || st.Code() != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestImportTodos_DryRun(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{} // A dry run must not touch the database
+
+	resp, err := ts.ImportTodos(context.Background(), &todov1.ImportTodosRequest{
+		UserId:         "user123",
+		Format:         todov1.TodoFormat_TODO_FORMAT_TODO_TXT,
+		Data:           []byte("(A) call mom\nx 2026-01-20 pay rent\n\n  \n"),
+		DryRun:         true,
+		IdempotencyKey: "ignored-on-dry-run",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Imported != 2 || resp.Rejected != 0 {
+		t.Errorf("expected 2 imported and 0 rejected, got %d and %d", resp.Imported, resp.Rejected)
+	}
+	if len(resp.Todos) != 2 || resp.Todos[0].Id != 0 || resp.Todos[0].UserId != "user123" {
+		t.Errorf("expected unsaved todos for user123, got %v", resp.Todos)
+	}
+	if resp.Todos[1].Status != todov1.TodoStatus_TODO_STATUS_COMPLETED {
+		t.Errorf("expected second todo completed, got %v", resp.Todos[1].Status)
+	}
+}
+
+func TestImportTodos_DryRunReportsRowErrors(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	resp, err := ts.ImportTodos(context.Background(), &todov1.ImportTodosRequest{
+		UserId: "user123",
+		Format: todov1.TodoFormat_TODO_FORMAT_CSV,
+		Data:   []byte("title,due\nok,2026-02-01\n,2026-02-01\n"),
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Imported != 1 || resp.Rejected != 1 {
+		t.Errorf("expected 1 imported and 1 rejected, got %d and %d", resp.Imported, resp.Rejected)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Row != 3 || resp.Errors[0].Field != "title" {
+		t.Errorf("expected title error on row 3, got %v", resp.Errors)
+	}
+}
+
+func TestExportTodos_Validation(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.ExportTodos(context.Background(), &todov1.ExportTodosRequest{Format: todov1.TodoFormat_TODO_FORMAT_JSON})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for missing user, got %v", err)
+	}
+
+	_, err = ts.ExportTodos(context.Background(), &todov1.ExportTodosRequest{UserId: "user123"})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for missing format, got %v", err)
+	}
+}
+
+func TestFormatFromProto(t *testing.T) {
+	tests := []struct {
+		input    todov1.TodoFormat
+		expected transfer.Format
+		ok       bool
+	}{
+		{todov1.TodoFormat_TODO_FORMAT_CSV, transfer.FormatCSV, true},
+		{todov1.TodoFormat_TODO_FORMAT_JSON, transfer.FormatJSON, true},
+		{todov1.TodoFormat_TODO_FORMAT_TODO_TXT, transfer.FormatTodoTxt, true},
+		{todov1.TodoFormat_TODO_FORMAT_ICALENDAR, transfer.FormatICalendar, true},
+		{todov1.TodoFormat_TODO_FORMAT_UNSPECIFIED, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := formatFromProto(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("formatFromProto(%v) = %q, %v; expected %q, %v", tt.input, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// =============================================================================
+// LLM Usage Tests
+// =============================================================================
+
+func TestRecordLLMUsage_Validation(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	tests := []struct {
+		name string
+		req  *todov1.RecordLLMUsageRequest
+	}{
+		{"missing user", &todov1.RecordLLMUsageRequest{Model: "gpt-5-mini", Calls: 1}},
+		{"missing model", &todov1.RecordLLMUsageRequest{UserId: "user123", Calls: 1}},
+		{"negative tokens", &todov1.RecordLLMUsageRequest{UserId: "user123", Model: "gpt-5-mini", PromptTokens: -5}},
+		{"negative cost", &todov1.RecordLLMUsageRequest{UserId: "user123", Model: "gpt-5-mini", CostMicros: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.RecordLLMUsage(context.Background(), tt.req)
+			if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetLLMUsage_Validation(t *testing.T) {
+	ts := newTestServer()
+	ts.Server.store = &store.Store{}
+
+	_, err := ts.GetLLMUsage(context.Background(), &todov1.GetLLMUsageRequest{Timezone: "Mars/Olympus"})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown timezone, got %v", err)
+	}
+
+	now := time.Now()
+	_, err = ts.GetLLMUsage(context.Background(), &todov1.GetLLMUsageRequest{
+		Since: timestamppb.New(now),
+		Until: timestamppb.New(now.Add(-time.Hour)),
+	})
+	if st, ok := status.FromError(err); !ok || st.Code() != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an empty period, got %v", err)
+	}
+}
+
+func TestUsageToProto(t *testing.T) {
+	got := usageToProto(&store.LLMUsage{
+		UserID: "user123", Date: "2026-01-20", Model: "gpt-5-mini",
+		Calls: 3, PromptTokens: 1200, CompletionTokens: 90, CostMicros: 480,
+	})
+	if got.UserId != "user123" || got.Date != "2026-01-20" || got.Model != "gpt-5-mini" ||
+		got.Calls != 3 || got.PromptTokens != 1200 || got.CompletionTokens != 90 || got.CostMicros != 480 {
+		t.Errorf("unexpected conversion: %v", got)
+	}
+}
@@ -0,0 +1,177 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	DefaultDays        = 7
+	DefaultWeeks       = 4
+	DefaultOldestLimit = 3
+
+	MaxDays        = 366
+	MaxWeeks       = 104
+	MaxOldestLimit = 50
+)
+
+// Options controls the shape of the computed statistics
+type Options struct {
+	Now         time.Time
+	Location    *time.Location
+	Days        int
+	Weeks       int
+	OldestLimit int
+}
+
+// DayCount is the number of completions on a single calendar day
+type DayCount struct {
+	Date      string
+	Completed int
+}
+
+// WeekCount is the number of completions in a week starting Monday
+type WeekCount struct {
+	WeekStart string
+	Completed int
+}
+
+// Stats holds the computed completion statistics for a user
+type Stats struct {
+	Daily             []DayCount
+	Weekly            []WeekCount
+	CurrentStreak     int
+	LongestStreak     int
+	AverageCompletion time.Duration
+	OldestOpen        []*store.Todo
+	TotalCompleted    int
+	TotalOpen         int
+}
+
+// Compute builds statistics from a user's completed and active todos
+func Compute(completed, active []*store.Todo, opts Options) *Stats {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if opts.Days <= 0 {
+		opts.Days = DefaultDays
+	}
+	if opts.Weeks <= 0 {
+		opts.Weeks = DefaultWeeks
+	}
+	if opts.OldestLimit <= 0 {
+		opts.OldestLimit = DefaultOldestLimit
+	}
+	today := startOfDay(opts.Now.In(loc))
+
+	// Bucket completions by local calendar day
+	perDay := make(map[string]int)
+	var totalDuration time.Duration
+	var timed int
+	for _, todo := range completed {
+		if todo.CompletedAt == nil {
+			continue
+		}
+		perDay[todo.CompletedAt.In(loc).Format(dateLayout)]++
+
+		if d := todo.CompletedAt.Sub(todo.CreatedAt); d >= 0 {
+			totalDuration += d
+			timed++
+		}
+	}
+
+	s := &Stats{
+		TotalCompleted: len(completed),
+		TotalOpen:      len(active),
+	}
+	if timed > 0 {
+		s.AverageCompletion = totalDuration / time.Duration(timed)
+	}
+
+	for i := opts.Days - 1; i >= 0; i-- {
+		date := today.AddDate(0, 0, -i).Format(dateLayout)
+		s.Daily = append(s.Daily, DayCount{Date: date, Completed: perDay[date]})
+	}
+
+	thisWeek := startOfWeek(today)
+	for i := opts.Weeks - 1; i >= 0; i-- {
+		weekStart := thisWeek.AddDate(0, 0, -7*i)
+		count := 0
+		for d := 0; d < 7; d++ {
+			count += perDay[weekStart.AddDate(0, 0, d).Format(dateLayout)]
+		}
+		s.Weekly = append(s.Weekly, WeekCount{WeekStart: weekStart.Format(dateLayout), Completed: count})
+	}
+
+	s.CurrentStreak = currentStreak(perDay, today)
+	s.LongestStreak = longestStreak(perDay, loc)
+
+	// Oldest open todos first
+	oldest := make([]*store.Todo, len(active))
+	copy(oldest, active)
+	sort.Slice(oldest, func(i, j int) bool {
+		return oldest[i].CreatedAt.Before(oldest[j].CreatedAt)
+	})
+	if len(oldest) > opts.OldestLimit {
+		oldest = oldest[:opts.OldestLimit]
+	}
+	s.OldestOpen = oldest
+
+	return s
+}
+
+// currentStreak counts consecutive days with a completion ending today.
+// A streak that ended yesterday is still current - the user has until
+// the end of today to keep it going.
+func currentStreak(perDay map[string]int, today time.Time) int {
+	day := today
+	if perDay[day.Format(dateLayout)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for perDay[day.Format(dateLayout)] > 0 {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// longestStreak finds the longest run of consecutive days with a completion
+func longestStreak(perDay map[string]int, loc *time.Location) int {
+	days := make([]time.Time, 0, len(perDay))
+	for date := range perDay {
+		if t, err := time.ParseInLocation(dateLayout, date, loc); err == nil {
+			days = append(days, t)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	longest, run := 0, 0
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1].AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday on or before the given day
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,192 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+// Wednesday 2026-01-21 at noon UTC
+var testNow = time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+
+func completedTodo(id int64, created, completed time.Time) *store.Todo {
+	return &store.Todo{
+		ID:          id,
+		Status:      "completed",
+		CreatedAt:   created,
+		CompletedAt: &completed,
+	}
+}
+
+func daysAgo(n int) time.Time {
+	return testNow.AddDate(0, 0, -n)
+}
+
+// =============================================================================
+// Daily and Weekly Count Tests
+// =============================================================================
+
+func TestCompute_DailyCounts(t *testing.T) {
+	completed := []*store.Todo{
+		completedTodo(1, daysAgo(3), daysAgo(0)),
+		completedTodo(2, daysAgo(3), daysAgo(0)),
+		completedTodo(3, daysAgo(3), daysAgo(2)),
+	}
+
+	s := Compute(completed, nil, Options{Now: testNow, Days: 3})
+
+	if len(s.Daily) != 3 {
+		t.Fatalf("expected 3 daily buckets, got %d", len(s.Daily))
+	}
+	expected := []DayCount{
+		{Date: "2026-01-19", Completed: 1},
+		{Date: "2026-01-20", Completed: 0},
+		{Date: "2026-01-21", Completed: 2},
+	}
+	for i, want := range expected {
+		if s.Daily[i] != want {
+			t.Errorf("bucket %d: expected %+v, got %+v", i, want, s.Daily[i])
+		}
+	}
+}
+
+func TestCompute_WeeklyCountsStartMonday(t *testing.T) {
+	completed := []*store.Todo{
+		completedTodo(1, daysAgo(10), daysAgo(2)), // Monday 2026-01-19
+		completedTodo(2, daysAgo(10), daysAgo(3)), // Sunday 2026-01-18, previous week
+	}
+
+	s := Compute(completed, nil, Options{Now: testNow, Weeks: 2})
+
+	expected := []WeekCount{
+		{WeekStart: "2026-01-12", Completed: 1},
+		{WeekStart: "2026-01-19", Completed: 1},
+	}
+	for i, want := range expected {
+		if s.Weekly[i] != want {
+			t.Errorf("week %d: expected %+v, got %+v", i, want, s.Weekly[i])
+		}
+	}
+}
+
+func TestCompute_Defaults(t *testing.T) {
+	s := Compute(nil, nil, Options{Now: testNow})
+
+	if len(s.Daily) != DefaultDays {
+		t.Errorf("expected %d daily buckets, got %d", DefaultDays, len(s.Daily))
+	}
+	if len(s.Weekly) != DefaultWeeks {
+		t.Errorf("expected %d weekly buckets, got %d", DefaultWeeks, len(s.Weekly))
+	}
+	if s.CurrentStreak != 0 || s.LongestStreak != 0 {
+		t.Errorf("expected no streaks, got current=%d longest=%d", s.CurrentStreak, s.LongestStreak)
+	}
+	if s.AverageCompletion != 0 {
+		t.Errorf("expected zero average, got %v", s.AverageCompletion)
+	}
+}
+
+func TestCompute_Timezone(t *testing.T) {
+	// 03:00 UTC on the 21st is still the 20th in New York
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	completedAt := time.Date(2026, 1, 21, 3, 0, 0, 0, time.UTC)
+	completed := []*store.Todo{completedTodo(1, daysAgo(5), completedAt)}
+
+	s := Compute(completed, nil, Options{Now: testNow, Location: loc, Days: 2})
+
+	if s.Daily[0].Date != "2026-01-20" || s.Daily[0].Completed != 1 {
+		t.Errorf("expected completion on 2026-01-20, got %+v", s.Daily)
+	}
+}
+
+// =============================================================================
+// Streak Tests
+// =============================================================================
+
+func TestCompute_Streaks(t *testing.T) {
+	tests := []struct {
+		name             string
+		completedDaysAgo []int
+		expectedCurrent  int
+		expectedLongest  int
+	}{
+		{"none", nil, 0, 0},
+		{"today only", []int{0}, 1, 1},
+		{"ending today", []int{0, 1, 2}, 3, 3},
+		{"ending yesterday still current", []int{1, 2}, 2, 2},
+		{"broken streak", []int{2, 3}, 0, 2},
+		{"longest in the past", []int{0, 5, 6, 7, 8}, 1, 4},
+		{"multiple per day counted once", []int{0, 0, 1}, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var completed []*store.Todo
+			for i, n := range tt.completedDaysAgo {
+				completed = append(completed, completedTodo(int64(i), daysAgo(n+1), daysAgo(n)))
+			}
+
+			s := Compute(completed, nil, Options{Now: testNow})
+
+			if s.CurrentStreak != tt.expectedCurrent {
+				t.Errorf("expected current streak %d, got %d", tt.expectedCurrent, s.CurrentStreak)
+			}
+			if s.LongestStreak != tt.expectedLongest {
+				t.Errorf("expected longest streak %d, got %d", tt.expectedLongest, s.LongestStreak)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Average and Oldest Open Tests
+// =============================================================================
+
+func TestCompute_AverageCompletion(t *testing.T) {
+	completed := []*store.Todo{
+		completedTodo(1, testNow.Add(-2*time.Hour), testNow),
+		completedTodo(2, testNow.Add(-4*time.Hour), testNow),
+		{ID: 3, Status: "completed", CreatedAt: testNow}, // missing completed_at is ignored
+	}
+
+	s := Compute(completed, nil, Options{Now: testNow})
+
+	if s.AverageCompletion != 3*time.Hour {
+		t.Errorf("expected average 3h, got %v", s.AverageCompletion)
+	}
+	if s.TotalCompleted != 3 {
+		t.Errorf("expected TotalCompleted 3, got %d", s.TotalCompleted)
+	}
+}
+
+func TestCompute_OldestOpen(t *testing.T) {
+	active := []*store.Todo{
+		{ID: 1, Title: "newest", CreatedAt: daysAgo(1)},
+		{ID: 2, Title: "oldest", CreatedAt: daysAgo(30)},
+		{ID: 3, Title: "middle", CreatedAt: daysAgo(10)},
+	}
+
+	s := Compute(nil, active, Options{Now: testNow, OldestLimit: 2})
+
+	if s.TotalOpen != 3 {
+		t.Errorf("expected TotalOpen 3, got %d", s.TotalOpen)
+	}
+	if len(s.OldestOpen) != 2 {
+		t.Fatalf("expected 2 oldest todos, got %d", len(s.OldestOpen))
+	}
+	if s.OldestOpen[0].ID != 2 || s.OldestOpen[1].ID != 3 {
+		t.Errorf("expected ids [2 3], got [%d %d]", s.OldestOpen[0].ID, s.OldestOpen[1].ID)
+	}
+	// Input order must not be modified
+	if active[0].ID != 1 {
+		t.Error("expected input slice to be left untouched")
+	}
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent is an entry in the append-only audit log
+type AuditEvent struct {
+	ID         int64
+	EventType  string
+	EntityType string
+	EntityID   int64
+	UserID     string
+	Payload    json.RawMessage
+	CreatedAt  time.Time
+}
+
+// AuditStore handles database operations for the audit log (audit_db)
+type AuditStore struct {
+	db *sql.DB
+}
+
+// NewAuditStore creates a new AuditStore with the given database connection
+func NewAuditStore(db *sql.DB) *AuditStore {
+	return &AuditStore{db: db}
+}
+
+// RecordEvent appends an event to the audit log
+func (s *AuditStore) RecordEvent(ctx context.Context, eventType, entityType string, entityID int64, userID string, payload interface{}) (*AuditEvent, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &AuditEvent{
+		EventType:  eventType,
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserID:     userID,
+		Payload:    jsonPayload,
+		CreatedAt:  time.Now(),
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO audit_events (event_type, entity_type, entity_id, user_id, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, event.EventType, event.EntityType, event.EntityID, event.UserID, []byte(event.Payload), event.CreatedAt).Scan(&event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListEventsByUser retrieves a user's audit events, oldest first
+func (s *AuditStore) ListEventsByUser(ctx context.Context, userID string) ([]*AuditEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, event_type, entity_type, entity_id, user_id, payload, created_at
+		FROM audit_events
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*AuditEvent
+	for rows.Next() {
+		event := &AuditEvent{}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID, &event.EventType, &event.EntityType, &event.EntityID,
+			&event.UserID, &payload, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// EraseEventsByUser hard-deletes a user's audit events
+func (s *AuditStore) EraseEventsByUser(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM audit_events WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AnonymizeEventsByUser reassigns a user's audit events to an anonymous id and
+// drops their payloads, keeping event types and timestamps
+func (s *AuditStore) AnonymizeEventsByUser(ctx context.Context, userID, anonymousID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE audit_events SET user_id = $1, payload = '{}'::jsonb WHERE user_id = $2
+	`, anonymousID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
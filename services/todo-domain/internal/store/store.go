@@ -28,6 +28,10 @@ type Todo struct {
 	UpdatedAt   time.Time
 	CompletedAt *time.Time
 	DeletedAt   *time.Time
+	DueAt       *time.Time
+	Priority    int    // 1 (highest) to 9 (lowest), 0 means none
+	ParentID    *int64 // The todo this is a step of, nil if none
+	Category    string // Like "errands", empty if unknown
 }
 
 // Store handles all database operations for todos
@@ -60,22 +64,27 @@ func Connect(databaseURL string) (*sql.DB, error) {
 	return db, nil
 }
 
-// CreateTodo inserts a new todo and returns it with the generated ID
-func (s *Store) CreateTodo(ctx context.Context, userID, title, description string) (*Todo, error) {
+// CreateTodo inserts a new todo and returns it with the generated ID. A
+// parentID other than 0 makes it a step of that todo.
+func (s *Store) CreateTodo(ctx context.Context, userID, title, description, category string, parentID int64) (*Todo, error) {
 	todo := &Todo{
 		UserID:      userID,
 		Title:       title,
 		Description: description,
+		Category:    category,
 		Status:      "active",
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if parentID != 0 {
+		todo.ParentID = &parentID
+	}
 
 	err := s.db.QueryRowContext(ctx, `
-		INSERT INTO todos (user_id, title, description, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO todos (user_id, title, description, status, created_at, updated_at, parent_id, category)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
-	`, todo.UserID, todo.Title, todo.Description, todo.Status, todo.CreatedAt, todo.UpdatedAt).Scan(&todo.ID)
+	`, todo.UserID, todo.Title, todo.Description, todo.Status, todo.CreatedAt, todo.UpdatedAt, todo.ParentID, todo.Category).Scan(&todo.ID)
 
 	if err != nil {
 		return nil, err
@@ -84,16 +93,55 @@ func (s *Store) CreateTodo(ctx context.Context, userID, title, description strin
 	return todo, nil
 }
 
+// ImportTodos inserts todos for a user in a single transaction, keeping their
+// status, dates and priority. IDs are set on the passed todos.
+func (s *Store) ImportTodos(ctx context.Context, userID string, todos []*Todo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO todos (user_id, title, description, status, created_at, updated_at, completed_at, due_at, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, todo := range todos {
+		todo.UserID = userID
+		if todo.CreatedAt.IsZero() {
+			todo.CreatedAt = now
+		}
+		todo.UpdatedAt = now
+
+		err := stmt.QueryRowContext(ctx,
+			todo.UserID, todo.Title, todo.Description, todo.Status, todo.CreatedAt, todo.UpdatedAt,
+			todo.CompletedAt, todo.DueAt, todo.Priority,
+		).Scan(&todo.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // GetTodo retrieves a todo by ID
 func (s *Store) GetTodo(ctx context.Context, id int64) (*Todo, error) {
 	todo := &Todo{}
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, user_id, title, description, status, created_at, updated_at, completed_at, deleted_at
+		SELECT id, user_id, title, description, status, created_at, updated_at, completed_at, deleted_at, due_at, priority, parent_id, category
 		FROM todos
 		WHERE id = $1
 	`, id).Scan(
 		&todo.ID, &todo.UserID, &todo.Title, &todo.Description, &todo.Status,
-		&todo.CreatedAt, &todo.UpdatedAt, &todo.CompletedAt, &todo.DeletedAt,
+		&todo.CreatedAt, &todo.UpdatedAt, &todo.CompletedAt, &todo.DeletedAt, &todo.DueAt, &todo.Priority, &todo.ParentID, &todo.Category,
 	)
 
 	if err == sql.ErrNoRows {
@@ -111,13 +159,15 @@ type ListTodosFilter struct {
 	Status          string
 	CompletedAfter  *time.Time
 	CompletedBefore *time.Time
+	Category        string
+	IncludeDeleted  bool // Only used when Status is empty
 }
 
-// ListTodos retrieves all todos for a user, optionally filtered by status and date range
+// ListTodos retrieves all todos for a user, optionally filtered by status, date range and category
 func (s *Store) ListTodos(ctx context.Context, userID string, filter ListTodosFilter) ([]*Todo, error) {
 	// Build dynamic query based on filters
 	query := `
-		SELECT id, user_id, title, description, status, created_at, updated_at, completed_at, deleted_at
+		SELECT id, user_id, title, description, status, created_at, updated_at, completed_at, deleted_at, due_at, priority, parent_id, category
 		FROM todos
 		WHERE user_id = $1`
 	args := []interface{}{userID}
@@ -127,7 +177,7 @@ func (s *Store) ListTodos(ctx context.Context, userID string, filter ListTodosFi
 		query += fmt.Sprintf(" AND status = $%d", argIndex)
 		args = append(args, filter.Status)
 		argIndex++
-	} else {
+	} else if !filter.IncludeDeleted {
 		// Default: show active and completed, not deleted
 		query += " AND status != 'deleted'"
 	}
@@ -144,7 +194,18 @@ func (s *Store) ListTodos(ctx context.Context, userID string, filter ListTodosFi
 		argIndex++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filter.Category != "" {
+		query += fmt.Sprintf(" AND category = $%d", argIndex)
+		args = append(args, filter.Category)
+		argIndex++
+	}
+
+	if filter.Status == "deleted" {
+		// Trash shows the most recently deleted first
+		query += " ORDER BY deleted_at DESC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -157,7 +218,7 @@ func (s *Store) ListTodos(ctx context.Context, userID string, filter ListTodosFi
 		todo := &Todo{}
 		err := rows.Scan(
 			&todo.ID, &todo.UserID, &todo.Title, &todo.Description, &todo.Status,
-			&todo.CreatedAt, &todo.UpdatedAt, &todo.CompletedAt, &todo.DeletedAt,
+			&todo.CreatedAt, &todo.UpdatedAt, &todo.CompletedAt, &todo.DeletedAt, &todo.DueAt, &todo.Priority, &todo.ParentID, &todo.Category,
 		)
 		if err != nil {
 			return nil, err
@@ -273,6 +334,112 @@ func (s *Store) EditTodo(ctx context.Context, id int64, userID, title, descripti
 	return s.GetTodo(ctx, id)
 }
 
+// RestoreTodo moves a soft-deleted todo out of the trash.
+// Todos that were completed before deletion come back as completed.
+func (s *Store) RestoreTodo(ctx context.Context, id int64, userID string) (*Todo, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE todos
+		SET status = CASE WHEN completed_at IS NULL THEN 'active' ELSE 'completed' END,
+		    deleted_at = NULL, updated_at = $1
+		WHERE id = $2 AND user_id = $3 AND status = 'deleted'
+	`, time.Now(), id, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		existing, err := s.GetTodo(ctx, id)
+		if err == ErrNotFound {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if existing.UserID != userID {
+			return nil, ErrNotOwner
+		}
+		// Not in the trash - return current state
+		return existing, nil
+	}
+
+	return s.GetTodo(ctx, id)
+}
+
+// EmptyTrash hard-deletes all of a user's soft-deleted todos
+func (s *Store) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM todos WHERE user_id = $1 AND status = 'deleted'
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// EraseTodos hard-deletes every todo belonging to a user
+func (s *Store) EraseTodos(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM todos WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AnonymizeTodos reassigns a user's todos to an anonymous id and blanks their content,
+// keeping the rows for aggregate statistics
+func (s *Store) AnonymizeTodos(ctx context.Context, userID, anonymousID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE todos
+		SET user_id = $1, title = '[erased]', description = '', category = '', updated_at = $2
+		WHERE user_id = $3
+	`, anonymousID, time.Now(), userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// EraseIdempotencyKeys deletes a user's cached responses. Rows cached before
+// they recorded their user are found through the todo or todos they hold.
+func (s *Store) EraseIdempotencyKeys(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1
+		   OR response->'todo'->>'user_id' = $1
+		   OR response->'todos' @> jsonb_build_array(jsonb_build_object('user_id', $1::text))
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeDeletedTodos hard-deletes up to limit soft-deleted todos deleted before the cutoff
+// Returns the number of rows removed so callers can loop until a batch comes back short
+func (s *Store) PurgeDeletedTodos(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM todos
+		WHERE id IN (
+			SELECT id FROM todos
+			WHERE status = 'deleted' AND deleted_at < $1
+			ORDER BY deleted_at
+			LIMIT $2
+		)
+	`, deletedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // CheckIdempotencyKey checks if an operation was already performed
 // Returns the cached response if found, nil if not found
 func (s *Store) CheckIdempotencyKey(ctx context.Context, key string) ([]byte, error) {
@@ -291,18 +458,19 @@ func (s *Store) CheckIdempotencyKey(ctx context.Context, key string) ([]byte, er
 	return response, nil
 }
 
-// StoreIdempotencyKey stores the result of an operation for idempotency
-func (s *Store) StoreIdempotencyKey(ctx context.Context, key string, response interface{}) error {
+// StoreIdempotencyKey stores the result of an operation for idempotency,
+// recording the user it was for so it can be erased with their data
+func (s *Store) StoreIdempotencyKey(ctx context.Context, key, userID string, response interface{}) error {
 	jsonResponse, err := json.Marshal(response)
 	if err != nil {
 		return err
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO idempotency_keys (key, response, created_at)
-		VALUES ($1, $2, $3)
+		INSERT INTO idempotency_keys (key, user_id, response, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
 		ON CONFLICT (key) DO NOTHING
-	`, key, jsonResponse, time.Now())
+	`, key, userID, jsonResponse, time.Now())
 
 	return err
 }
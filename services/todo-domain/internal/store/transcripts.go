@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Transcript is a raw voice memo transcription
+type Transcript struct {
+	ID               int64
+	UserID           string
+	AudioURL         string
+	RawText          string
+	ParsedAction     string
+	TwilioMessageSID string
+	CreatedAt        time.Time
+}
+
+// TranscriptStore handles database operations for transcripts (transcription_db)
+type TranscriptStore struct {
+	db *sql.DB
+}
+
+// NewTranscriptStore creates a new TranscriptStore with the given database connection
+func NewTranscriptStore(db *sql.DB) *TranscriptStore {
+	return &TranscriptStore{db: db}
+}
+
+// ListTranscriptsByUser retrieves a user's transcripts, oldest first
+func (s *TranscriptStore) ListTranscriptsByUser(ctx context.Context, userID string) ([]*Transcript, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, COALESCE(audio_url, ''), raw_text, COALESCE(parsed_action, ''),
+		       COALESCE(twilio_message_sid, ''), created_at
+		FROM transcriptions
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transcripts []*Transcript
+	for rows.Next() {
+		t := &Transcript{}
+		err := rows.Scan(
+			&t.ID, &t.UserID, &t.AudioURL, &t.RawText, &t.ParsedAction,
+			&t.TwilioMessageSID, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transcripts = append(transcripts, t)
+	}
+
+	return transcripts, rows.Err()
+}
+
+// EraseTranscriptsByUser hard-deletes a user's transcripts
+func (s *TranscriptStore) EraseTranscriptsByUser(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM transcriptions WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AnonymizeTranscriptsByUser reassigns a user's transcripts to an anonymous id and
+// removes the recorded speech and audio links
+func (s *TranscriptStore) AnonymizeTranscriptsByUser(ctx context.Context, userID, anonymousID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE transcriptions
+		SET user_id = $1, raw_text = '[erased]', audio_url = NULL, twilio_message_sid = NULL
+		WHERE user_id = $2
+	`, anonymousID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeTranscripts hard-deletes up to limit transcripts created before the cutoff
+func (s *TranscriptStore) PurgeTranscripts(ctx context.Context, createdBefore time.Time, limit int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM transcriptions
+		WHERE id IN (
+			SELECT id FROM transcriptions
+			WHERE created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+		)
+	`, createdBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LLMUsage is the tokens and cost of LLM calls, either for one message or
+// summed over a user's day with one model
+type LLMUsage struct {
+	UserID           string
+	Date             string // YYYY-MM-DD, set on summaries only
+	Model            string
+	Calls            int
+	PromptTokens     int64
+	CompletionTokens int64
+	CostMicros       int64 // Millionths of a US dollar
+}
+
+// LLMUsageFilter selects the usage to summarize
+type LLMUsageFilter struct {
+	UserID   string         // All users if empty
+	Since    *time.Time     // From the beginning if nil
+	Until    *time.Time     // Up to now if nil
+	Location *time.Location // Day boundaries, UTC if nil
+}
+
+// RecordLLMUsage stores usage for one message. A repeated idempotency key is
+// ignored so a redelivered message isn't counted twice; recorded is false then.
+func (s *Store) RecordLLMUsage(ctx context.Context, usage *LLMUsage, idempotencyKey string) (bool, error) {
+	key := sql.NullString{String: idempotencyKey, Valid: idempotencyKey != ""}
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO llm_usage (user_id, model, calls, prompt_tokens, completion_tokens, cost_micros, idempotency_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`, usage.UserID, usage.Model, usage.Calls, usage.PromptTokens, usage.CompletionTokens, usage.CostMicros, key, time.Now())
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SummarizeLLMUsage totals usage per user, day and model
+func (s *Store) SummarizeLLMUsage(ctx context.Context, filter LLMUsageFilter) ([]*LLMUsage, error) {
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	query := `
+		SELECT user_id, to_char(created_at AT TIME ZONE $1, 'YYYY-MM-DD') AS day, model,
+		       SUM(calls), SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_micros)
+		FROM llm_usage
+		WHERE TRUE`
+	args := []interface{}{loc.String()}
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	query += " GROUP BY user_id, day, model ORDER BY user_id, day, model"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []*LLMUsage
+	for rows.Next() {
+		u := &LLMUsage{}
+		if err := rows.Scan(&u.UserID, &u.Date, &u.Model, &u.Calls, &u.PromptTokens, &u.CompletionTokens, &u.CostMicros); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// EraseLLMUsage hard-deletes a user's usage records
+func (s *Store) EraseLLMUsage(ctx context.Context, userID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM llm_usage WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// AnonymizeLLMUsage reassigns a user's usage records to an anonymous id,
+// keeping them in cost totals
+func (s *Store) AnonymizeLLMUsage(ctx context.Context, userID, anonymousID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE llm_usage SET user_id = $1, idempotency_key = NULL WHERE user_id = $2
+	`, anonymousID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
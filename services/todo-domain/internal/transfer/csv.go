@@ -0,0 +1,97 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// csvHeader is the column order written on export
+var csvHeader = []string{
+	FieldTitle, FieldDescription, FieldStatus, FieldCreatedAt, FieldCompletedAt, FieldDueAt, FieldPriority,
+}
+
+// readCSV reads a CSV file with a header row. Columns are matched by name and
+// unknown columns are ignored. Rows are numbered by line, the header being line 1.
+func readCSV(r io.Reader) ([]*record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		// Spreadsheet apps often start the file with a byte order mark
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	hasTitle := false
+	for _, name := range header {
+		if fieldAliases[normalizeName(name)] == FieldTitle {
+			hasTitle = true
+		}
+	}
+	if !hasTitle {
+		return nil, errors.New("CSV header must include a title column")
+	}
+
+	var records []*record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		rec := newRecord(line)
+		for i, value := range row {
+			if i < len(header) {
+				rec.set(header[i], value)
+			}
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func writeCSV(w io.Writer, todos []*store.Todo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		priority := ""
+		if todo.Priority > 0 {
+			priority = strconv.Itoa(todo.Priority)
+		}
+		err := cw.Write([]string{
+			todo.Title,
+			todo.Description,
+			todo.Status,
+			formatTime(&todo.CreatedAt),
+			formatTime(todo.CompletedAt),
+			formatTime(todo.DueAt),
+			priority,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
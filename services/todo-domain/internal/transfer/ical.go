@@ -0,0 +1,214 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+const (
+	icalDateTime = "20060102T150405Z"
+
+	// icalLineLimit is the longest content line allowed before folding (RFC 5545 3.1)
+	icalLineLimit = 75
+)
+
+// icalProperties maps VTODO properties onto our fields
+var icalProperties = map[string]string{
+	"SUMMARY":     FieldTitle,
+	"DESCRIPTION": FieldDescription,
+	"STATUS":      FieldStatus,
+	"CREATED":     FieldCreatedAt,
+	"COMPLETED":   FieldCompletedAt,
+	"DUE":         FieldDueAt,
+	"PRIORITY":    FieldPriority,
+}
+
+// readICalendar reads the VTODO components of an iCalendar file (RFC 5545).
+// Other components are ignored. Rows are numbered by VTODO position from 1.
+func readICalendar(r io.Reader) ([]*record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*record
+	var current *record
+	depth := 0 // Components nested inside the current VTODO, such as VALARM
+
+	for _, line := range unfoldICalendar(data) {
+		name, params, value := splitICalendarLine(line)
+
+		switch {
+		case name == "BEGIN" && current == nil && strings.EqualFold(value, "VTODO"):
+			current = newRecord(len(records) + 1)
+			records = append(records, current)
+		case name == "BEGIN" && current != nil:
+			depth++
+		case name == "END" && current != nil && depth > 0:
+			depth--
+		case name == "END" && current != nil:
+			current = nil
+		case current != nil && depth == 0:
+			field, ok := icalProperties[name]
+			if !ok {
+				continue
+			}
+			switch field {
+			case FieldTitle, FieldDescription:
+				current.fields[field] = unescapeICalendarText(value)
+			case FieldCreatedAt, FieldCompletedAt, FieldDueAt:
+				current.fields[field] = icalendarDate(current, field, params, value)
+			default:
+				current.fields[field] = value
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func writeICalendar(w io.Writer, todos []*store.Todo, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	write := func(name, value string) {
+		writeICalendarLine(bw, name+":"+value)
+	}
+
+	write("BEGIN", "VCALENDAR")
+	write("VERSION", "2.0")
+	write("PRODID", "-//Hound Todo//Todo Export//EN")
+
+	stamp := now.UTC().Format(icalDateTime)
+	for _, todo := range todos {
+		write("BEGIN", "VTODO")
+		write("UID", fmt.Sprintf("todo-%d@hound-todo", todo.ID))
+		write("DTSTAMP", stamp)
+		if !todo.CreatedAt.IsZero() {
+			write("CREATED", todo.CreatedAt.UTC().Format(icalDateTime))
+		}
+		write("SUMMARY", escapeICalendarText(todo.Title))
+		if todo.Description != "" {
+			write("DESCRIPTION", escapeICalendarText(todo.Description))
+		}
+
+		switch todo.Status {
+		case "completed":
+			write("STATUS", "COMPLETED")
+		case "deleted":
+			write("STATUS", "CANCELLED")
+		default:
+			write("STATUS", "NEEDS-ACTION")
+		}
+		if todo.CompletedAt != nil {
+			write("COMPLETED", todo.CompletedAt.UTC().Format(icalDateTime))
+		}
+		if todo.DueAt != nil {
+			write("DUE", todo.DueAt.UTC().Format(icalDateTime))
+		}
+		if todo.Priority > 0 {
+			write("PRIORITY", strconv.Itoa(todo.Priority))
+		}
+		write("END", "VTODO")
+	}
+
+	write("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// unfoldICalendar splits data into content lines, joining folded continuations
+func unfoldICalendar(data []byte) []string {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitICalendarLine splits "NAME;PARAM=x:value" into its parts.
+// Colons inside quoted parameter values don't end the name.
+func splitICalendarLine(line string) (name string, params map[string]string, value string) {
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+// icalendarDate converts a DATE or DATE-TIME value to RFC 3339, applying its TZID.
+// Floating times without a zone are read as UTC.
+func icalendarDate(rec *record, field string, params map[string]string, value string) string {
+	tzid := params["TZID"]
+	if tzid == "" || strings.HasSuffix(value, "Z") {
+		return value
+	}
+
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		rec.fail(field, fmt.Sprintf("unknown time zone %q", tzid))
+		return ""
+	}
+	for _, layout := range []string{"20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+// escapeICalendarText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeICalendarText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeICalendarText reverses escapeICalendarText
+func unescapeICalendarText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// writeICalendarLine writes a content line, folding it at the length limit
+// without splitting a UTF-8 character
+func writeICalendarLine(w *bufio.Writer, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icalLineLimit - 1 // Continuation lines start with a space
+	}
+	w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,101 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// jsonTodo is the exported form of a todo
+type jsonTodo struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    int        `json:"priority,omitempty"`
+}
+
+// readJSON reads an array of todo objects, or an object holding one under
+// "todos" as in a user data export. Rows are numbered by position from 1.
+func readJSON(r io.Reader) ([]*record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Todos []json.RawMessage `json:"todos"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if wrapper.Todos == nil {
+			return nil, errors.New(`JSON object must have a "todos" array`)
+		}
+		items = wrapper.Todos
+	} else if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	records := make([]*record, 0, len(items))
+	for i, item := range items {
+		rec := newRecord(i + 1)
+		records = append(records, rec)
+
+		var obj map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(item))
+		dec.UseNumber()
+		if err := dec.Decode(&obj); err != nil || obj == nil {
+			rec.fail("", "expected an object")
+			continue
+		}
+
+		for key, value := range obj {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				rec.set(key, v)
+			case json.Number:
+				rec.set(key, v.String())
+			case bool:
+				rec.set(key, fmt.Sprint(v))
+			default:
+				if field, ok := fieldAliases[normalizeName(key)]; ok {
+					rec.fail(field, "expected a string, number or boolean")
+				}
+			}
+		}
+	}
+
+	return records, nil
+}
+
+func writeJSON(w io.Writer, todos []*store.Todo) error {
+	out := make([]jsonTodo, 0, len(todos))
+	for _, todo := range todos {
+		out = append(out, jsonTodo{
+			ID:          todo.ID,
+			Title:       todo.Title,
+			Description: todo.Description,
+			Status:      todo.Status,
+			CreatedAt:   todo.CreatedAt.UTC(),
+			CompletedAt: todo.CompletedAt,
+			DueAt:       todo.DueAt,
+			Priority:    todo.Priority,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
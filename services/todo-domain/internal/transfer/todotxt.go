@@ -0,0 +1,120 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+const todoTxtDate = "2006-01-02"
+
+// readTodoTxt reads the todo.txt format (https://github.com/todotxt/todo.txt):
+//
+//	x (A) 2026-01-20 2026-01-10 call mom +family due:2026-01-25
+//
+// Projects and contexts stay in the title. todo.txt has no descriptions.
+// Rows are numbered by line; blank lines are skipped.
+func readTodoTxt(r io.Reader) ([]*record, error) {
+	scanner := bufio.NewScanner(r)
+	var records []*record
+
+	for line := 1; scanner.Scan(); line++ {
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		rec := newRecord(line)
+		records = append(records, rec)
+
+		completed := false
+		if words[0] == "x" {
+			completed = true
+			rec.fields[FieldStatus] = "completed"
+			words = words[1:]
+		}
+
+		// Some clients keep the priority on completed tasks, before the dates
+		if len(words) > 0 && isTodoTxtPriority(words[0]) {
+			rec.fields[FieldPriority] = words[0]
+			words = words[1:]
+		}
+
+		// Completed tasks have a completion date followed by an optional creation date
+		if completed && len(words) > 1 && isTodoTxtDate(words[0]) {
+			rec.fields[FieldCompletedAt] = words[0]
+			words = words[1:]
+		}
+		if len(words) > 1 && isTodoTxtDate(words[0]) {
+			rec.fields[FieldCreatedAt] = words[0]
+			words = words[1:]
+		}
+
+		var title []string
+		for _, word := range words {
+			key, value, ok := strings.Cut(word, ":")
+			switch {
+			case ok && key == "due":
+				rec.fields[FieldDueAt] = value
+			case ok && key == "pri":
+				rec.fields[FieldPriority] = value
+			default:
+				title = append(title, word)
+			}
+		}
+		rec.fields[FieldTitle] = strings.Join(title, " ")
+	}
+
+	return records, scanner.Err()
+}
+
+func writeTodoTxt(w io.Writer, todos []*store.Todo) error {
+	bw := bufio.NewWriter(w)
+
+	for _, todo := range todos {
+		var parts []string
+		letter := priorityLetter(todo.Priority)
+
+		if todo.Status == "completed" {
+			parts = append(parts, "x")
+			if todo.CompletedAt != nil {
+				parts = append(parts, todo.CompletedAt.UTC().Format(todoTxtDate))
+			}
+		} else if letter != 0 {
+			parts = append(parts, fmt.Sprintf("(%c)", letter))
+		}
+
+		if !todo.CreatedAt.IsZero() {
+			parts = append(parts, todo.CreatedAt.UTC().Format(todoTxtDate))
+		}
+		parts = append(parts, strings.Join(strings.Fields(todo.Title), " "))
+
+		if todo.DueAt != nil {
+			parts = append(parts, "due:"+todo.DueAt.UTC().Format(todoTxtDate))
+		}
+		// The spec drops the priority on completion; keep it as a tag instead
+		if todo.Status == "completed" && letter != 0 {
+			parts = append(parts, fmt.Sprintf("pri:%c", letter))
+		}
+
+		if _, err := bw.WriteString(strings.Join(parts, " ") + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// isTodoTxtPriority reports whether a word is a priority such as (A)
+func isTodoTxtPriority(word string) bool {
+	return len(word) == 3 && word[0] == '(' && word[2] == ')' && word[1] >= 'A' && word[1] <= 'Z'
+}
+
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse(todoTxtDate, word)
+	return err == nil
+}
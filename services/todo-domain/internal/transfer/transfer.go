@@ -0,0 +1,379 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// Format is a todo file format shared with other apps
+type Format string
+
+const (
+	FormatCSV       Format = "csv"
+	FormatJSON      Format = "json"
+	FormatTodoTxt   Format = "todo.txt"
+	FormatICalendar Format = "icalendar"
+)
+
+// ErrUnknownFormat is returned for formats this package can't read or write
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Fields every format maps onto. Rows that fail validation report the field at fault.
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldStatus      = "status"
+	FieldCreatedAt   = "created_at"
+	FieldCompletedAt = "completed_at"
+	FieldDueAt       = "due_at"
+	FieldPriority    = "priority"
+)
+
+// fieldAliases maps column and key names used by other apps onto our fields
+var fieldAliases = map[string]string{
+	"title":           FieldTitle,
+	"name":            FieldTitle,
+	"summary":         FieldTitle,
+	"task":            FieldTitle,
+	"content":         FieldTitle,
+	"subject":         FieldTitle,
+	"description":     FieldDescription,
+	"notes":           FieldDescription,
+	"note":            FieldDescription,
+	"details":         FieldDescription,
+	"body":            FieldDescription,
+	"status":          FieldStatus,
+	"state":           FieldStatus,
+	"done":            FieldStatus,
+	"completed":       FieldStatus,
+	"is_completed":    FieldStatus,
+	"created_at":      FieldCreatedAt,
+	"created":         FieldCreatedAt,
+	"creation_date":   FieldCreatedAt,
+	"created_date":    FieldCreatedAt,
+	"completed_at":    FieldCompletedAt,
+	"completion_date": FieldCompletedAt,
+	"completed_date":  FieldCompletedAt,
+	"completed_on":    FieldCompletedAt,
+	"done_at":         FieldCompletedAt,
+	"due_at":          FieldDueAt,
+	"due":             FieldDueAt,
+	"due_date":        FieldDueAt,
+	"deadline":        FieldDueAt,
+	"priority":        FieldPriority,
+	"pri":             FieldPriority,
+}
+
+// dateLayouts are tried in order when reading dates. Layouts without a zone are read as UTC.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102T150405Z",
+	"20060102T150405",
+	"20060102",
+}
+
+// RowError describes why a single row of an import was rejected
+type RowError struct {
+	Row     int
+	Field   string
+	Message string
+}
+
+func (e RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d: %s: %s", e.Row, e.Field, e.Message)
+}
+
+// Result holds the outcome of decoding an import file
+type Result struct {
+	Todos  []*store.Todo // Valid rows, ready to insert
+	Rows   []int         // Row number of each entry in Todos
+	Errors []RowError    // One entry per problem; a row can have several
+	Total  int           // Rows read, valid or not
+}
+
+// Rejected returns the number of rows that failed validation
+func (r *Result) Rejected() int {
+	return r.Total - len(r.Todos)
+}
+
+// record is one row of an import file before validation, with every field as text
+type record struct {
+	row    int
+	fields map[string]string
+	errors []RowError // Problems found while reading the row
+}
+
+func newRecord(row int) *record {
+	return &record{row: row, fields: make(map[string]string)}
+}
+
+// set stores a value under the field its name maps to, ignoring unknown names
+func (r *record) set(name, value string) {
+	if field, ok := fieldAliases[normalizeName(name)]; ok {
+		r.fields[field] = strings.TrimSpace(value)
+	}
+}
+
+func (r *record) fail(field, message string) {
+	r.errors = append(r.errors, RowError{Row: r.row, Field: field, Message: message})
+}
+
+// unreadable reports whether the row as a whole couldn't be read, so its fields mean nothing
+func (r *record) unreadable() bool {
+	for _, e := range r.errors {
+		if e.Field == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode reads todos from an import file. An error is returned only when the
+// file as a whole can't be read; problems with individual rows are reported
+// in the result and those rows are left out.
+func Decode(format Format, r io.Reader, now time.Time) (*Result, error) {
+	var records []*record
+	var err error
+
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatJSON:
+		records, err = readJSON(r)
+	case FormatTodoTxt:
+		records, err = readTodoTxt(r)
+	case FormatICalendar:
+		records, err = readICalendar(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: len(records)}
+	for _, rec := range records {
+		if rec.unreadable() {
+			result.Errors = append(result.Errors, rec.errors...)
+			continue
+		}
+		todo := validate(rec, now)
+		if len(rec.errors) > 0 {
+			result.Errors = append(result.Errors, rec.errors...)
+			continue
+		}
+		result.Todos = append(result.Todos, todo)
+		result.Rows = append(result.Rows, rec.row)
+	}
+
+	return result, nil
+}
+
+// Encode writes todos in the given format
+func Encode(format Format, w io.Writer, todos []*store.Todo, now time.Time) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, todos)
+	case FormatJSON:
+		return writeJSON(w, todos)
+	case FormatTodoTxt:
+		return writeTodoTxt(w, todos)
+	case FormatICalendar:
+		return writeICalendar(w, todos, now)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// Filename returns the suggested file name for an export
+func Filename(format Format, exportedAt time.Time) string {
+	ext := "json"
+	switch format {
+	case FormatCSV:
+		ext = "csv"
+	case FormatTodoTxt:
+		// todo.txt clients look for this exact name
+		return "todo.txt"
+	case FormatICalendar:
+		ext = "ics"
+	}
+	return fmt.Sprintf("hound-todos-%s.%s", exportedAt.UTC().Format("20060102"), ext)
+}
+
+// ContentType returns the MIME type of an export
+func ContentType(format Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatJSON:
+		return "application/json"
+	case FormatICalendar:
+		return "text/calendar"
+	default:
+		return "text/plain"
+	}
+}
+
+// validate turns a record into a todo, recording any problems on the record
+func validate(rec *record, now time.Time) *store.Todo {
+	todo := &store.Todo{
+		Title:       rec.fields[FieldTitle],
+		Description: rec.fields[FieldDescription],
+	}
+
+	if todo.Title == "" {
+		rec.fail(FieldTitle, "title is required")
+	}
+
+	if created := parseDateField(rec, FieldCreatedAt); created != nil {
+		todo.CreatedAt = *created
+	}
+	todo.CompletedAt = parseDateField(rec, FieldCompletedAt)
+	todo.DueAt = parseDateField(rec, FieldDueAt)
+
+	if value := rec.fields[FieldPriority]; value != "" {
+		p, ok := ParsePriority(value)
+		if !ok {
+			rec.fail(FieldPriority, fmt.Sprintf("unrecognized priority %q", value))
+		}
+		todo.Priority = p
+	}
+
+	status, ok := parseStatus(rec.fields[FieldStatus])
+	if !ok {
+		rec.fail(FieldStatus, fmt.Sprintf("unrecognized status %q", rec.fields[FieldStatus]))
+	}
+	switch {
+	case status == "" && todo.CompletedAt != nil:
+		status = "completed"
+	case status == "":
+		status = "active"
+	case status == "active":
+		todo.CompletedAt = nil
+	case status == "cancelled":
+		rec.fail(FieldStatus, "cancelled todos are not imported")
+	}
+	if status == "completed" && todo.CompletedAt == nil {
+		// The other app didn't say when - count it as done on import
+		todo.CompletedAt = &now
+	}
+	todo.Status = status
+
+	return todo
+}
+
+// parseDateField parses a date field, recording an error if it is present but unreadable
+func parseDateField(rec *record, field string) *time.Time {
+	value := rec.fields[field]
+	if value == "" {
+		return nil
+	}
+	t, ok := ParseDate(value)
+	if !ok {
+		rec.fail(field, fmt.Sprintf("unrecognized date %q", value))
+		return nil
+	}
+	return &t
+}
+
+// ParseDate reads a date or timestamp in any of the layouts other apps commonly use
+func ParseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParsePriority reads a priority as a number from 0 to 9, a todo.txt letter
+// or a word. Returns the iCalendar value: 1 is highest, 9 lowest, 0 none.
+func ParsePriority(value string) (int, bool) {
+	value = strings.Trim(strings.TrimSpace(value), "()")
+	if value == "" {
+		return 0, true
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return n, n >= 0 && n <= 9
+	}
+
+	if len(value) == 1 {
+		c := value[0]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c >= 'A' && c <= 'Z' {
+			return letterPriority(c), true
+		}
+		return 0, false
+	}
+
+	switch strings.ToLower(value) {
+	case "none":
+		return 0, true
+	case "high", "urgent":
+		return 1, true
+	case "medium", "med", "normal":
+		return 5, true
+	case "low":
+		return 9, true
+	}
+	return 0, false
+}
+
+// letterPriority maps a todo.txt priority letter onto 1-9. Letters past I are all lowest.
+func letterPriority(c byte) int {
+	return min(int(c-'A')+1, 9)
+}
+
+// priorityLetter maps a priority onto a todo.txt letter, or 0 if there is none
+func priorityLetter(p int) byte {
+	if p <= 0 || p > 9 {
+		return 0
+	}
+	return byte('A' + p - 1)
+}
+
+// parseStatus normalizes status values used by other apps.
+// Returns "" when the value is empty so the caller can infer it.
+func parseStatus(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return "", true
+	case "active", "open", "todo", "pending", "incomplete", "needs-action", "in-process", "in progress", "false", "no", "0":
+		return "active", true
+	case "completed", "complete", "done", "finished", "closed", "x", "true", "yes", "1":
+		return "completed", true
+	case "cancelled", "canceled":
+		return "cancelled", true
+	}
+	return "", false
+}
+
+// normalizeName folds a column or key name so "Due Date" and "due-date" both match due_date
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+// formatTime writes a timestamp for CSV and JSON exports
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
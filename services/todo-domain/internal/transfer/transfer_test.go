@@ -0,0 +1,377 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+var testNow = time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+
+func decode(t *testing.T, format Format, input string) *Result {
+	t.Helper()
+	result, err := Decode(format, strings.NewReader(input), testNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func sampleTodos() []*store.Todo {
+	return []*store.Todo{
+		{
+			ID:          1,
+			Title:       "buy milk, eggs; bread",
+			Description: "from the corner shop\nnot the big one",
+			Status:      "active",
+			CreatedAt:   time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			DueAt:       timePtr(time.Date(2026, 1, 25, 0, 0, 0, 0, time.UTC)),
+			Priority:    1,
+		},
+		{
+			ID:          2,
+			Title:       "call mom",
+			Status:      "completed",
+			CreatedAt:   time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+			CompletedAt: timePtr(time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+}
+
+// =============================================================================
+// Round Trip Tests
+// =============================================================================
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSON, FormatTodoTxt, FormatICalendar} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(format, &buf, sampleTodos(), testNow); err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+
+			result := decode(t, format, buf.String())
+			if len(result.Errors) > 0 {
+				t.Fatalf("unexpected row errors: %v", result.Errors)
+			}
+			if len(result.Todos) != 2 {
+				t.Fatalf("expected 2 todos, got %d", len(result.Todos))
+			}
+
+			for i, want := range sampleTodos() {
+				got := result.Todos[i]
+				if got.Title != want.Title {
+					t.Errorf("todo %d: expected title %q, got %q", i, want.Title, got.Title)
+				}
+				if got.Status != want.Status {
+					t.Errorf("todo %d: expected status %q, got %q", i, want.Status, got.Status)
+				}
+				if !got.CreatedAt.Equal(want.CreatedAt) {
+					t.Errorf("todo %d: expected created %v, got %v", i, want.CreatedAt, got.CreatedAt)
+				}
+				if (got.CompletedAt == nil) != (want.CompletedAt == nil) ||
+					(got.CompletedAt != nil && !got.CompletedAt.Equal(*want.CompletedAt)) {
+					t.Errorf("todo %d: expected completed %v, got %v", i, want.CompletedAt, got.CompletedAt)
+				}
+				if (got.DueAt == nil) != (want.DueAt == nil) ||
+					(got.DueAt != nil && !got.DueAt.Equal(*want.DueAt)) {
+					t.Errorf("todo %d: expected due %v, got %v", i, want.DueAt, got.DueAt)
+				}
+				if got.Priority != want.Priority {
+					t.Errorf("todo %d: expected priority %d, got %d", i, want.Priority, got.Priority)
+				}
+				// todo.txt has no descriptions
+				if format != FormatTodoTxt && got.Description != want.Description {
+					t.Errorf("todo %d: expected description %q, got %q", i, want.Description, got.Description)
+				}
+			}
+		})
+	}
+}
+
+func TestDecode_UnknownFormat(t *testing.T) {
+	if _, err := Decode("xml", strings.NewReader(""), testNow); err != ErrUnknownFormat {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+	if err := Encode("xml", &bytes.Buffer{}, nil, testNow); err != ErrUnknownFormat {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+// =============================================================================
+// Validation Tests
+// =============================================================================
+
+func TestDecode_RowErrors(t *testing.T) {
+	input := "Title,Status,Due Date,Priority\n" +
+		"good one,open,2026-02-01,high\n" +
+		",done,,\n" +
+		"bad date,,next tuesday,\n" +
+		"bad everything,maybe,,11\n"
+
+	result := decode(t, FormatCSV, input)
+
+	if result.Total != 4 {
+		t.Errorf("expected 4 rows, got %d", result.Total)
+	}
+	if len(result.Todos) != 1 || result.Rows[0] != 2 {
+		t.Fatalf("expected only line 2 to import, got rows %v", result.Rows)
+	}
+	if result.Rejected() != 3 {
+		t.Errorf("expected 3 rejected, got %d", result.Rejected())
+	}
+
+	expected := []RowError{
+		{Row: 3, Field: FieldTitle},
+		{Row: 4, Field: FieldDueAt},
+		{Row: 5, Field: FieldPriority},
+		{Row: 5, Field: FieldStatus},
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), result.Errors)
+	}
+	for i, want := range expected {
+		got := result.Errors[i]
+		if got.Row != want.Row || got.Field != want.Field {
+			t.Errorf("error %d: expected row %d field %s, got %v", i, want.Row, want.Field, got)
+		}
+	}
+}
+
+func TestDecode_StatusInference(t *testing.T) {
+	input := `[
+		{"title": "date only", "completed_at": "2026-01-05"},
+		{"title": "done flag", "done": true},
+		{"title": "not done", "done": false, "completed_at": "2026-01-05"},
+		{"title": "cancelled", "status": "cancelled"}
+	]`
+
+	result := decode(t, FormatJSON, input)
+
+	if len(result.Todos) != 3 {
+		t.Fatalf("expected 3 todos, got %d (%v)", len(result.Todos), result.Errors)
+	}
+	if result.Todos[0].Status != "completed" {
+		t.Errorf("expected a completion date to imply completed, got %s", result.Todos[0].Status)
+	}
+	if result.Todos[1].Status != "completed" || !result.Todos[1].CompletedAt.Equal(testNow) {
+		t.Errorf("expected completed now without a date, got %s %v", result.Todos[1].Status, result.Todos[1].CompletedAt)
+	}
+	if result.Todos[2].Status != "active" || result.Todos[2].CompletedAt != nil {
+		t.Errorf("expected explicit status to win, got %s %v", result.Todos[2].Status, result.Todos[2].CompletedAt)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 4 {
+		t.Errorf("expected cancelled row 4 to be rejected, got %v", result.Errors)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		ok       bool
+	}{
+		{"", 0, true},
+		{"0", 0, true},
+		{"3", 3, true},
+		{"10", 10, false},
+		{"A", 1, true},
+		{"(b)", 2, true},
+		{"Z", 9, true},
+		{"High", 1, true},
+		{"medium", 5, true},
+		{"low", 9, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParsePriority(tt.input)
+			if ok != tt.ok || (ok && got != tt.expected) {
+				t.Errorf("ParsePriority(%q) = %d, %v; expected %d, %v", tt.input, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Format Specific Tests
+// =============================================================================
+
+func TestReadCSV_HeaderAliasesAndBOM(t *testing.T) {
+	input := "\ufeffTask Name,Notes,Completed\n" +
+		"water plants,the ferns too,yes\n"
+
+	// "Task Name" isn't an alias, so there is no title column
+	if _, err := Decode(FormatCSV, strings.NewReader(input), testNow); err == nil {
+		t.Error("expected an error for a CSV without a title column")
+	}
+
+	input = strings.Replace(input, "Task Name", "Task", 1)
+	result := decode(t, FormatCSV, input)
+	if len(result.Todos) != 1 {
+		t.Fatalf("expected 1 todo, got %v", result.Errors)
+	}
+	todo := result.Todos[0]
+	if todo.Title != "water plants" || todo.Description != "the ferns too" || todo.Status != "completed" {
+		t.Errorf("unexpected todo: %+v", todo)
+	}
+}
+
+func TestReadJSON_ExportWrapper(t *testing.T) {
+	result := decode(t, FormatJSON, `{"user_id": "+15551234567", "todos": [{"title": "call mom", "priority": 2}, "oops"]}`)
+
+	if len(result.Todos) != 1 || result.Todos[0].Priority != 2 {
+		t.Fatalf("expected 1 todo with priority 2, got %+v", result.Todos)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 2 {
+		t.Errorf("expected row 2 to be rejected, got %v", result.Errors)
+	}
+
+	if _, err := Decode(FormatJSON, strings.NewReader(`{"items": []}`), testNow); err == nil {
+		t.Error("expected an error for an object without todos")
+	}
+	if _, err := Decode(FormatJSON, strings.NewReader(`[{"title": `), testNow); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
+
+func TestReadTodoTxt(t *testing.T) {
+	input := "(A) 2026-01-10 call mom +family @phone due:2026-01-25\n" +
+		"\n" +
+		"x 2026-01-20 2026-01-11 pay rent pri:B\n" +
+		"x (C) 2026-01-19 book dentist\n" +
+		"check http://example.com\n"
+
+	result := decode(t, FormatTodoTxt, input)
+	if len(result.Todos) != 4 {
+		t.Fatalf("expected 4 todos, got %d (%v)", len(result.Todos), result.Errors)
+	}
+
+	first := result.Todos[0]
+	if first.Title != "call mom +family @phone" || first.Priority != 1 || first.Status != "active" {
+		t.Errorf("unexpected first todo: %+v", first)
+	}
+	if first.DueAt == nil || first.DueAt.Format(todoTxtDate) != "2026-01-25" {
+		t.Errorf("expected due date 2026-01-25, got %v", first.DueAt)
+	}
+
+	second := result.Todos[1]
+	if second.Status != "completed" || second.Priority != 2 ||
+		second.CompletedAt.Format(todoTxtDate) != "2026-01-20" || second.CreatedAt.Format(todoTxtDate) != "2026-01-11" {
+		t.Errorf("unexpected second todo: %+v", second)
+	}
+
+	third := result.Todos[2]
+	if third.Priority != 3 || third.CompletedAt.Format(todoTxtDate) != "2026-01-19" || !third.CreatedAt.IsZero() {
+		t.Errorf("unexpected third todo: %+v", third)
+	}
+
+	if result.Todos[3].Title != "check http://example.com" {
+		t.Errorf("expected URLs to stay in the title, got %q", result.Todos[3].Title)
+	}
+	if result.Rows[1] != 3 {
+		t.Errorf("expected blank lines to count toward row numbers, got %v", result.Rows)
+	}
+}
+
+func TestReadICalendar(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:not a todo\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:renew passport\\, urgently\r\n" +
+		"DESCRIPTION:bring two\r\n" +
+		"  photos\r\n" +
+		"DUE;TZID=America/New_York:20260201T090000\r\n" +
+		"PRIORITY:1\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"DESCRIPTION:alarm text\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"SUMMARY:bad zone\r\n" +
+		"DUE;TZID=Mars/Olympus:20260201T090000\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	result := decode(t, FormatICalendar, input)
+	if result.Total != 2 || len(result.Todos) != 1 {
+		t.Fatalf("expected 1 of 2 todos to import, got %d of %d (%v)", len(result.Todos), result.Total, result.Errors)
+	}
+
+	todo := result.Todos[0]
+	if todo.Title != "renew passport, urgently" {
+		t.Errorf("unexpected title %q", todo.Title)
+	}
+	if todo.Description != "bring two photos" {
+		t.Errorf("expected folded description without the alarm text, got %q", todo.Description)
+	}
+	if todo.Priority != 1 {
+		t.Errorf("expected priority 1, got %d", todo.Priority)
+	}
+	if _, err := time.LoadLocation("America/New_York"); err == nil {
+		want := time.Date(2026, 2, 1, 14, 0, 0, 0, time.UTC)
+		if todo.DueAt == nil || !todo.DueAt.Equal(want) {
+			t.Errorf("expected due %v, got %v", want, todo.DueAt)
+		}
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Row != 2 || result.Errors[0].Field != FieldDueAt {
+		t.Errorf("expected a due_at error on row 2, got %v", result.Errors)
+	}
+}
+
+func TestWriteICalendar_FoldsLongLines(t *testing.T) {
+	todos := []*store.Todo{{ID: 1, Title: strings.Repeat("é", 60), Status: "active", CreatedAt: testNow}}
+
+	var buf bytes.Buffer
+	if err := Encode(FormatICalendar, &buf, todos, testNow); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("line longer than %d octets: %q", icalLineLimit, line)
+		}
+	}
+
+	result := decode(t, FormatICalendar, buf.String())
+	if len(result.Todos) != 1 || result.Todos[0].Title != todos[0].Title {
+		t.Errorf("expected folded title to survive the round trip, got %+v", result.Todos)
+	}
+}
+
+func TestFilenameAndContentType(t *testing.T) {
+	tests := []struct {
+		format      Format
+		filename    string
+		contentType string
+	}{
+		{FormatCSV, "hound-todos-20260121.csv", "text/csv"},
+		{FormatJSON, "hound-todos-20260121.json", "application/json"},
+		{FormatTodoTxt, "todo.txt", "text/plain"},
+		{FormatICalendar, "hound-todos-20260121.ics", "text/calendar"},
+	}
+
+	for _, tt := range tests {
+		if got := Filename(tt.format, testNow); got != tt.filename {
+			t.Errorf("Filename(%s) = %s, expected %s", tt.format, got, tt.filename)
+		}
+		if got := ContentType(tt.format); got != tt.contentType {
+			t.Errorf("ContentType(%s) = %s, expected %s", tt.format, got, tt.contentType)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package userdata
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// Bundle is everything stored about a single user, as handed to them on export
+type Bundle struct {
+	UserID      string       `json:"user_id"`
+	ExportedAt  time.Time    `json:"exported_at"`
+	Todos       []Todo       `json:"todos"`
+	History     []Event      `json:"history"`
+	Transcripts []Transcript `json:"transcripts"`
+}
+
+// Todo is the exported form of a todo
+type Todo struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Priority    int        `json:"priority,omitempty"`
+	ParentID    *int64     `json:"parent_id,omitempty"` // The todo this is a step of
+	Category    string     `json:"category,omitempty"`
+}
+
+// Event is the exported form of an audit log entry
+type Event struct {
+	EventType  string          `json:"event_type"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Transcript is the exported form of a voice memo transcription
+type Transcript struct {
+	ID           int64     `json:"id"`
+	AudioURL     string    `json:"audio_url,omitempty"`
+	RawText      string    `json:"raw_text"`
+	ParsedAction string    `json:"parsed_action,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// manifest summarizes the contents of a ZIP export
+type manifest struct {
+	UserID      string    `json:"user_id"`
+	ExportedAt  time.Time `json:"exported_at"`
+	Todos       int       `json:"todos"`
+	History     int       `json:"history"`
+	Transcripts int       `json:"transcripts"`
+}
+
+// NewBundle assembles an export bundle from store records
+func NewBundle(userID string, todos []*store.Todo, events []*store.AuditEvent, transcripts []*store.Transcript, now time.Time) *Bundle {
+	b := &Bundle{
+		UserID:      userID,
+		ExportedAt:  now.UTC(),
+		Todos:       make([]Todo, 0, len(todos)),
+		History:     make([]Event, 0, len(events)),
+		Transcripts: make([]Transcript, 0, len(transcripts)),
+	}
+
+	for _, t := range todos {
+		b.Todos = append(b.Todos, Todo{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			CreatedAt:   t.CreatedAt,
+			UpdatedAt:   t.UpdatedAt,
+			CompletedAt: t.CompletedAt,
+			DeletedAt:   t.DeletedAt,
+			DueAt:       t.DueAt,
+			Priority:    t.Priority,
+			ParentID:    t.ParentID,
+			Category:    t.Category,
+		})
+	}
+	for _, e := range events {
+		b.History = append(b.History, Event{
+			EventType:  e.EventType,
+			EntityType: e.EntityType,
+			EntityID:   e.EntityID,
+			Payload:    e.Payload,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	for _, t := range transcripts {
+		b.Transcripts = append(b.Transcripts, Transcript{
+			ID:           t.ID,
+			AudioURL:     t.AudioURL,
+			RawText:      t.RawText,
+			ParsedAction: t.ParsedAction,
+			CreatedAt:    t.CreatedAt,
+		})
+	}
+
+	return b
+}
+
+// WriteJSON writes the bundle as a single JSON document
+func WriteJSON(w io.Writer, b *Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// WriteZip writes the bundle as a ZIP archive with one JSON file per section
+func WriteZip(w io.Writer, b *Bundle) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"manifest.json", manifest{
+			UserID:      b.UserID,
+			ExportedAt:  b.ExportedAt,
+			Todos:       len(b.Todos),
+			History:     len(b.History),
+			Transcripts: len(b.Transcripts),
+		}},
+		{"todos.json", b.Todos},
+		{"history.json", b.History},
+		{"transcripts.json", b.Transcripts},
+	}
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: b.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", f.name, err)
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// Filename returns the suggested file name for an export.
+// The user id is left out so the name is safe to log.
+func Filename(exportedAt time.Time, zipped bool) string {
+	ext := "json"
+	if zipped {
+		ext = "zip"
+	}
+	return fmt.Sprintf("hound-export-%s.%s", exportedAt.UTC().Format("20060102T150405Z"), ext)
+}
+
+// NewAnonymousID returns a random id used in place of a user id once their data is erased.
+// It is random rather than derived from the phone number so it can't be reversed.
+func NewAnonymousID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "erased_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,163 @@
+package userdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"hound-todo/services/todo-domain/internal/store"
+)
+
+// =============================================================================
+// Test Helpers
+// =============================================================================
+
+var testNow = time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC)
+
+func testBundle() *Bundle {
+	completedAt := testNow.Add(-time.Hour)
+	parentID := int64(1)
+	todos := []*store.Todo{
+		{ID: 1, UserID: "+15551234567", Title: "buy milk", Status: "active", CreatedAt: testNow, Category: "errands"},
+		{ID: 2, UserID: "+15551234567", Title: "call mom", Status: "completed", CreatedAt: testNow, CompletedAt: &completedAt, ParentID: &parentID},
+	}
+	events := []*store.AuditEvent{
+		{ID: 1, EventType: "todo_created", EntityType: "todo", EntityID: 1, UserID: "+15551234567", Payload: json.RawMessage(`{"title":"buy milk"}`), CreatedAt: testNow},
+	}
+	transcripts := []*store.Transcript{
+		{ID: 7, UserID: "+15551234567", RawText: "remind me to buy milk", CreatedAt: testNow},
+	}
+	return NewBundle("+15551234567", todos, events, transcripts, testNow)
+}
+
+// =============================================================================
+// NewBundle Tests
+// =============================================================================
+
+func TestNewBundle(t *testing.T) {
+	b := testBundle()
+
+	if b.UserID != "+15551234567" {
+		t.Errorf("expected UserID +15551234567, got %s", b.UserID)
+	}
+	if len(b.Todos) != 2 || len(b.History) != 1 || len(b.Transcripts) != 1 {
+		t.Fatalf("unexpected section sizes: %d todos, %d history, %d transcripts",
+			len(b.Todos), len(b.History), len(b.Transcripts))
+	}
+	if b.Todos[1].CompletedAt == nil {
+		t.Error("expected CompletedAt to be carried over")
+	}
+	if b.Todos[0].Category != "errands" || b.Todos[1].ParentID == nil || *b.Todos[1].ParentID != 1 {
+		t.Errorf("expected category and parent to be carried over, got %+v", b.Todos)
+	}
+	if b.Transcripts[0].RawText != "remind me to buy milk" {
+		t.Errorf("unexpected transcript text: %s", b.Transcripts[0].RawText)
+	}
+}
+
+func TestNewBundle_EmptySectionsEncodeAsArrays(t *testing.T) {
+	b := NewBundle("user123", nil, nil, nil, testNow)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, section := range []string{`"todos": []`, `"history": []`, `"transcripts": []`} {
+		if !strings.Contains(buf.String(), section) {
+			t.Errorf("expected %s in output, got %s", section, buf.String())
+		}
+	}
+}
+
+// =============================================================================
+// Encoding Tests
+// =============================================================================
+
+func TestWriteJSON_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testBundle()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded Bundle
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(decoded.Todos) != 2 || decoded.Todos[0].Title != "buy milk" {
+		t.Errorf("unexpected decoded todos: %+v", decoded.Todos)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(decoded.History[0].Payload, &payload); err != nil || payload["title"] != "buy milk" {
+		t.Errorf("unexpected payload: %s", decoded.History[0].Payload)
+	}
+}
+
+func TestWriteZip_Contents(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, testBundle()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a valid zip: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = data
+	}
+
+	for _, name := range []string{"manifest.json", "todos.json", "history.json", "transcripts.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected %s in archive", name)
+		}
+	}
+
+	var m manifest
+	if err := json.Unmarshal(files["manifest.json"], &m); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if m.Todos != 2 || m.History != 1 || m.Transcripts != 1 {
+		t.Errorf("unexpected manifest counts: %+v", m)
+	}
+}
+
+// =============================================================================
+// Filename and Anonymous ID Tests
+// =============================================================================
+
+func TestFilename(t *testing.T) {
+	if got := Filename(testNow, false); got != "hound-export-20260121T120000Z.json" {
+		t.Errorf("unexpected JSON filename: %s", got)
+	}
+	if got := Filename(testNow, true); got != "hound-export-20260121T120000Z.zip" {
+		t.Errorf("unexpected ZIP filename: %s", got)
+	}
+}
+
+func TestNewAnonymousID(t *testing.T) {
+	a, err := NewAnonymousID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := NewAnonymousID()
+
+	if !strings.HasPrefix(a, "erased_") || len(a) != len("erased_")+32 {
+		t.Errorf("unexpected anonymous id format: %s", a)
+	}
+	if a == b {
+		t.Error("expected anonymous ids to be unique")
+	}
+}
@@ -13,5 +13,11 @@ func GenerateKey(source string) string {
 	return fmt.Sprintf("idem_%s", hex.EncodeToString(hash[:16]))
 }
 
+// DeriveKey creates the key for one part of a request that does several things,
+// so each part is deduplicated on its own when the request is redelivered
+func DeriveKey(parent string, index int) string {
+	return GenerateKey(fmt.Sprintf("%s#%d", parent, index))
+}
+
 // Example: Twilio message SID -> idempotency key
 // GenerateKey("SM1234567890abcdef") -> "idem_a1b2c3d4e5f6..."